@@ -131,10 +131,46 @@ func (ie *IfExpression) String() string       {
 }
 
 
+// Catch and Finally may be missing, but not both of them. Param is nil when the catch
+// doesn't name the error.
+type TryExpression struct {
+	Token token.Token  // The 'try' token
+	Body *BlockStatement
+	Param *Identifier
+	Catch *BlockStatement
+	Finally *BlockStatement
+}
+
+func (te *TryExpression) expressionNode()      {}
+func (te *TryExpression) TokenLiteral() string { return te.Token.Literal }
+func (te *TryExpression) String() string       {
+	var out bytes.Buffer
+
+	out.WriteString("try ")
+	out.WriteString(te.Body.String())
+	if te.Catch != nil {
+		out.WriteString("catch ")
+		if te.Param != nil {
+			out.WriteString(te.Param.String() + " ")
+		}
+		out.WriteString(te.Catch.String())
+	}
+	if te.Finally != nil {
+		out.WriteString("finally ")
+		out.WriteString(te.Finally.String())
+	}
+
+	return out.String()
+}
+
+
 type FunctionLiteral struct {
 	Token token.Token  // The 'fn' token
 	Parameters []*Identifier
+	ParameterTypes []*TypeAnnotation  // One per parameter, nil when it has no annotation
+	ReturnType *TypeAnnotation
 	Body *BlockStatement
+	Name string  // The binding it was declared with, if any. Only for the traces of errors.
 }
 
 func (fl *FunctionLiteral) expressionNode()      {}
@@ -143,7 +179,11 @@ func (fl *FunctionLiteral) String() string       {
 	var out bytes.Buffer
 	var params []string
 
-	for _, param := range fl.Parameters {
+	for i, param := range fl.Parameters {
+		if i < len(fl.ParameterTypes) && fl.ParameterTypes[i] != nil {
+			params = append(params, param.String() + ": " + fl.ParameterTypes[i].String())
+			continue
+		}
 		params = append(params, param.String())	
 	}
 
@@ -151,6 +191,9 @@ func (fl *FunctionLiteral) String() string       {
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ","))
 	out.WriteString(")")
+	if fl.ReturnType != nil {
+		out.WriteString(": " + fl.ReturnType.String())
+	}
 	out.WriteString(fl.Body.String())
 
 	return out.String()
@@ -201,6 +244,20 @@ func (ce *CallExpression) String() string       {
 }
 
 
+// module.member
+type MemberExpression struct {
+	Token token.Token  // The '.' token
+	Object Expression
+	Member *Identifier
+}
+
+func (me *MemberExpression) expressionNode()      {}
+func (me *MemberExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MemberExpression) String() string       {
+	return me.Object.String() + "." + me.Member.String()
+}
+
+
 type CommentExpression struct {
 	Token token.Token  
 }
@@ -212,3 +269,87 @@ func (ce *CommentExpression) String() string       {
 	out.WriteString(ce.Token.Literal)
 	return out.String()
 }
+
+
+// x = 5, but also x += 5 and friends. Only identifiers can be assigned for now.
+type AssignExpression struct {
+	Token token.Token  // The operator token, e.g.: = | +=
+	Name *Identifier
+	Operator string
+	Value Expression
+}
+
+func (ae *AssignExpression) expressionNode()      {}
+func (ae *AssignExpression) TokenLiteral() string { return ae.Token.Literal }
+func (ae *AssignExpression) String() string       {
+	var out bytes.Buffer
+
+	out.WriteString(ae.Name.String())
+	out.WriteString(" " + ae.Operator + " ")
+	if ae.Value != nil {
+		out.WriteString(ae.Value.String())
+	}
+
+	return out.String()
+}
+
+
+type ArrayLiteral struct {
+	Token token.Token  // The '[' token
+	Elements []Expression
+}
+
+func (al *ArrayLiteral) expressionNode()      {}
+func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
+func (al *ArrayLiteral) String() string       {
+	var elements []string
+	for _, element := range al.Elements {
+		elements = append(elements, element.String())
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+
+// array[index], and string[index] too
+type IndexExpression struct {
+	Token token.Token  // The '[' token
+	Left Expression
+	Index Expression
+}
+
+func (ie *IndexExpression) expressionNode()      {}
+func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) String() string       {
+	return "(" + ie.Left.String() + "[" + ie.Index.String() + "])"
+}
+
+
+// The keys and the values go in the same order, Values[i] is the value of Keys[i]
+type MapLiteral struct {
+	Token token.Token  // The '{' token
+	Keys []Expression
+	Values []Expression
+}
+
+func (ml *MapLiteral) expressionNode()      {}
+func (ml *MapLiteral) TokenLiteral() string { return ml.Token.Literal }
+func (ml *MapLiteral) String() string       {
+	var pairs []string
+	for i, key := range ml.Keys {
+		pairs = append(pairs, key.String() + ": " + ml.Values[i].String())
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+
+// value? gives back the value, unless it's an error: then the function returns it
+type PropagateExpression struct {
+	Token token.Token  // The '?' token
+	Value Expression
+}
+
+func (pe *PropagateExpression) expressionNode()      {}
+func (pe *PropagateExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PropagateExpression) String() string       {
+	return "(" + pe.Value.String() + "?)"
+}
@@ -32,6 +32,7 @@ func (es *ExpressionStatement) String() string {
 type VarStatement struct {
 	Token token.Token  // This is the token.VAR
 	Name *Identifier
+	Type *TypeAnnotation  // nil when there is no annotation
 	Value Expression 
 }
 
@@ -42,6 +43,9 @@ func (vs *VarStatement) String() string {
 
 	out.WriteString(vs.TokenLiteral() + " ")
 	out.WriteString(vs.Name.String())
+	if vs.Type != nil {
+		out.WriteString(": " + vs.Type.String())
+	}
 
 	if vs.Value != nil {
 		out.WriteString(" = ")
@@ -56,6 +60,7 @@ func (vs *VarStatement) String() string {
 type ConstStatement struct {
 	Token token.Token  // This is the token.CONST
 	Name *Identifier
+	Type *TypeAnnotation  // nil when there is no annotation
 	Value Expression 
 }
 
@@ -66,6 +71,9 @@ func (cs *ConstStatement) String() string {
 
 	out.WriteString(cs.TokenLiteral() + " ")
 	out.WriteString(cs.Name.String())
+	if cs.Type != nil {
+		out.WriteString(": " + cs.Type.String())
+	}
 	out.WriteString(" = ")
 
 	if cs.Value != nil {
@@ -109,6 +117,22 @@ func (rs *ReturnStatement) String() string {
 }
 
 
+// throw stops the program with the error, unless a try catches it
+type ThrowStatement struct {
+	Token token.Token  // The 'throw' token
+	Value Expression
+}
+
+func (ts *ThrowStatement) statementNode() 			{}
+func (ts *ThrowStatement) TokenLiteral() string	{ return ts.Token.Literal }
+func (ts *ThrowStatement) String() string {
+	if ts.Value == nil {
+		return ts.TokenLiteral() + ";"
+	}
+	return ts.TokenLiteral() + " " + ts.Value.String() + ";"
+}
+
+
 type BlockStatement struct {
 	Token token.Token  // The { token
 	Statements []Statement
@@ -128,3 +152,35 @@ func (bs *BlockStatement) String() string {
 
 	return out.String()
 }
+
+
+type BreakStatement struct {
+	Token token.Token  // The 'break' token
+}
+
+func (bs *BreakStatement) statementNode() 			{}
+func (bs *BreakStatement) TokenLiteral() string	{ return bs.Token.Literal }
+func (bs *BreakStatement) String() string 			{ return bs.Token.Literal + ";" }
+
+
+type ContinueStatement struct {
+	Token token.Token  // The 'continue' token
+}
+
+func (cs *ContinueStatement) statementNode() 			{}
+func (cs *ContinueStatement) TokenLiteral() string	{ return cs.Token.Literal }
+func (cs *ContinueStatement) String() string 			{ return cs.Token.Literal + ";" }
+
+
+// The name is the one after `as`, or the last part of the path when there isn't one
+type ImportStatement struct {
+	Token token.Token  // The 'import' token
+	Path string  // Without the quotes
+	Name *Identifier
+}
+
+func (is *ImportStatement) statementNode() 			{}
+func (is *ImportStatement) TokenLiteral() string	{ return is.Token.Literal }
+func (is *ImportStatement) String() string {
+	return is.Token.Literal + " \"" + is.Path + "\" as " + is.Name.String() + ";"
+}
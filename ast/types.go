@@ -0,0 +1,39 @@
+package ast
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/santos-404/myte/token"
+)
+
+// Annotations are optional, so every field holding one of these may be nil
+type TypeAnnotation struct {
+	Token token.Token  // The name of the type, or the 'fn' token for function types
+	Name string
+	Parameters []*TypeAnnotation  // Only for function types
+	ReturnType *TypeAnnotation    // Only for function types, and may be nil too
+}
+
+func (ta *TypeAnnotation) TokenLiteral() string { return ta.Token.Literal }
+func (ta *TypeAnnotation) String() string       {
+	if ta.Name != "fn" {
+		return ta.Name
+	}
+
+	var out bytes.Buffer
+	var params []string
+
+	for _, param := range ta.Parameters {
+		params = append(params, param.String())
+	}
+
+	out.WriteString("fn(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(")")
+	if ta.ReturnType != nil {
+		out.WriteString(": " + ta.ReturnType.String())
+	}
+
+	return out.String()
+}
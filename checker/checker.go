@@ -0,0 +1,272 @@
+package checker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/evaluator"
+	"github.com/santos-404/myte/token"
+)
+
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+)
+
+func (s Severity) String() string {
+	if s == Warning {
+		return "warning"
+	}
+	return "error"
+}
+
+type Finding struct {
+	Severity Severity
+	Message  string
+	Line     int
+	Column   int
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s. Line: %d, column: %d", f.Severity, f.Message, f.Line, f.Column)
+}
+
+
+type DeclarationKind int
+
+const (
+	VarDeclaration DeclarationKind = iota
+	ConstDeclaration
+	ParamDeclaration
+	ImportDeclaration
+)
+
+type Declaration struct {
+	Name  *ast.Identifier
+	Kind  DeclarationKind
+	Value ast.Expression  // nil for parameters and imports
+	Local bool            // Declared inside of a function
+	used  bool
+}
+
+// Result is everything the pass found. Refs maps each identifier (uses and
+// declarations alike) to the declaration it refers to, for tools like the LSP.
+type Result struct {
+	Findings     []Finding
+	Declarations []*Declaration
+	Refs         map[*ast.Identifier]*Declaration
+}
+
+func (r *Result) HasErrors() bool {
+	for _, finding := range r.Findings {
+		if finding.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
+
+
+type scope struct {
+	names  map[string]*Declaration
+	outer  *scope
+	branch *branch  // Where the declarations are being made now, for the duplicates
+}
+
+func newScope(outer *scope) *scope {
+	return &scope{names: make(map[string]*Declaration), outer: outer, branch: newBranch(nil)}
+}
+
+/*
+The two sides of an if share the scope, but only one of them runs, so declaring the
+same name on both is fine. Each side gets a branch of its own, and once the if is
+over what they declared goes to the branch they came from: a later declaration runs
+after either of them.
+*/
+type branch struct {
+	names map[string]*Declaration
+	outer *branch
+}
+
+func newBranch(outer *branch) *branch {
+	return &branch{names: make(map[string]*Declaration), outer: outer}
+}
+
+func (b *branch) lookup(name string) *Declaration {
+	for current := b; current != nil; current = current.outer {
+		if decl, ok := current.names[name]; ok {
+			return decl
+		}
+	}
+	return nil
+}
+
+func (s *scope) lookup(name string) *Declaration {
+	for current := s; current != nil; current = current.outer {
+		if decl, ok := current.names[name]; ok {
+			return decl
+		}
+	}
+	return nil
+}
+
+// How an identifier is used. ++x does both things at once.
+const (
+	read = 1 << iota
+	write
+)
+
+// A use inside of a function body we could not resolve yet; see Check
+type pendingRef struct {
+	ident *ast.Identifier
+	scope *scope
+	mode  int
+	tok   token.Token  // The operator token when it's written
+}
+
+type checker struct {
+	result  *Result
+	scope   *scope
+	pending []pendingRef
+
+	functionDepth int
+	loopDepth     int
+}
+
+/*
+Check resolves every name of the program and reports what's wrong with it without
+running anything. It follows the evaluator rules: only functions open a new scope,
+and a name is visible from its declaration on. Function bodies are the exception;
+they run later, so they can use bindings declared after them (that's how recursion
+works). Those uses are resolved at the end, once every scope is complete.
+*/
+func Check(program *ast.Program) *Result {
+	c := &checker{
+		result: &Result{Refs: make(map[*ast.Identifier]*Declaration)},
+		scope:  newScope(nil),
+	}
+
+	c.checkStatements(program.Statements)
+
+	for _, ref := range c.pending {
+		decl := ref.scope.lookup(ref.ident.Value)
+		if decl == nil {
+			c.addError(ref.ident.Token, "undeclared identifier: %s", ref.ident.Value)
+			continue
+		}
+		c.bind(ref.ident, decl, ref.mode, ref.tok)
+	}
+
+	for _, decl := range c.result.Declarations {
+		if decl.Local && !decl.used && decl.Kind != ParamDeclaration && !strings.HasPrefix(decl.Name.Value, "_") {
+			c.addWarning(decl.Name.Token, "%s is declared but never used", decl.Name.Value)
+		}
+	}
+
+	SortFindings(c.result.Findings)
+	return c.result
+}
+
+// SortFindings puts the findings in the order they appear on the source
+func SortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
+
+// Unreported drops the findings that are at the same place as one already reported.
+// The same mistake can be seen by more than one check, we only want to say it once.
+func Unreported(findings, reported []Finding) []Finding {
+	seen := make(map[[2]int]bool, len(reported))
+	for _, f := range reported {
+		seen[[2]int{f.Line, f.Column}] = true
+	}
+
+	var unreported []Finding
+	for _, f := range findings {
+		if !seen[[2]int{f.Line, f.Column}] {
+			unreported = append(unreported, f)
+		}
+	}
+	return unreported
+}
+
+func (c *checker) addError(tok token.Token, format string, a ...interface{}) {
+	c.addFinding(Error, tok, format, a...)
+}
+
+func (c *checker) addWarning(tok token.Token, format string, a ...interface{}) {
+	c.addFinding(Warning, tok, format, a...)
+}
+
+func (c *checker) addFinding(severity Severity, tok token.Token, format string, a ...interface{}) {
+	c.result.Findings = append(c.result.Findings, Finding{
+		Severity: severity,
+		Message:  fmt.Sprintf(format, a...),
+		Line:     tok.Line,
+		Column:   tok.Column,
+	})
+}
+
+func (c *checker) declare(name *ast.Identifier, kind DeclarationKind, value ast.Expression) {
+	if name == nil {
+		return
+	}
+	if evaluator.IsBuiltin(name.Value) {
+		c.addError(name.Token, "cannot redeclare builtin: %s", name.Value)
+		return
+	}
+
+	if previous := c.scope.branch.lookup(name.Value); previous != nil {
+		c.addError(name.Token, "%s is already declared in this scope (Line: %d, column: %d)",
+			name.Value, previous.Name.Token.Line, previous.Name.Token.Column)
+	}
+
+	decl := &Declaration{Name: name, Kind: kind, Value: value, Local: c.functionDepth > 0}
+	c.scope.names[name.Value] = decl
+	c.scope.branch.names[name.Value] = decl
+	c.result.Declarations = append(c.result.Declarations, decl)
+	c.result.Refs[name] = decl
+}
+
+// use resolves an identifier. The token is the one to blame if it's written on a constant.
+func (c *checker) use(ident *ast.Identifier, mode int, tok token.Token) {
+	if ident == nil {
+		return
+	}
+	// Builtins are found before any declaration
+	if evaluator.IsBuiltin(ident.Value) {
+		if mode&write != 0 {
+			c.addError(tok, "cannot assign to builtin: %s", ident.Value)
+		}
+		return
+	}
+
+	if decl := c.scope.lookup(ident.Value); decl != nil {
+		c.bind(ident, decl, mode, tok)
+		return
+	}
+	if c.functionDepth > 0 {
+		c.pending = append(c.pending, pendingRef{ident, c.scope, mode, tok})
+		return
+	}
+	c.addError(ident.Token, "undeclared identifier: %s", ident.Value)
+}
+
+func (c *checker) bind(ident *ast.Identifier, decl *Declaration, mode int, tok token.Token) {
+	c.result.Refs[ident] = decl
+	if mode&read != 0 {
+		decl.used = true
+	}
+	if mode&write != 0 && (decl.Kind == ConstDeclaration || decl.Kind == ImportDeclaration) {
+		c.addError(tok, "cannot assign to constant: %s", ident.Value)
+	}
+}
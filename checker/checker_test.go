@@ -0,0 +1,99 @@
+package checker
+
+import (
+	"testing"
+
+	"github.com/santos-404/myte/lexer"
+	"github.com/santos-404/myte/parser"
+)
+
+func testCheck(t *testing.T, input string) *Result {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has errors for %q: %v", input, p.Errors())
+	}
+	return Check(program)
+}
+
+func TestFindings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"var x = 1; x + 1;", nil},
+		{"foo;", []string{"error: undeclared identifier: foo. Line: 0, column: 1"}},
+		{"var x = y;", []string{"error: undeclared identifier: y. Line: 0, column: 9"}},
+		{"const c = 1; c = 2;", []string{"error: cannot assign to constant: c. Line: 0, column: 16"}},
+		{"const c = 1; c += 2;", []string{"error: cannot assign to constant: c. Line: 0, column: 16"}},
+		{"const c = 1; ++c;", []string{"error: cannot assign to constant: c. Line: 0, column: 14"}},
+		{"var x = 1; var x = 2;", []string{
+			"error: x is already declared in this scope (Line: 0, column: 5). Line: 0, column: 16"}},
+		{"fn(a, a) { a };", []string{
+			"error: a is already declared in this scope (Line: 0, column: 4). Line: 0, column: 7"}},
+		{"var x = 1; fn() { var x = 2; x };", nil},  // Shadowing on a new scope is fine
+		{"var c = true; if c { var x = 1; x } else { var x = 2; x }", nil},  // Only one side runs
+		{"var c = true; if c { var x = 1; x } else { if c { var x = 2; x } else { var x = 3; x } }", nil},
+		{"var c = true; var x = 0; if c { var x = 1; }", []string{
+			"error: x is already declared in this scope (Line: 0, column: 19). Line: 0, column: 37"}},
+		{"var c = true; if c { var x = 1; var x = 2; }", []string{
+			"error: x is already declared in this scope (Line: 0, column: 26). Line: 0, column: 37"}},
+		{"var c = true; if c { var x = 1; } else { var y = 2; }; var x = 3;", []string{
+			"error: x is already declared in this scope (Line: 0, column: 26). Line: 0, column: 60"}},
+		{"return 1;", []string{"error: return outside of a function. Line: 0, column: 1"}},
+		{"fn() { return 1; };", nil},
+		{"break;", []string{"error: break outside of a loop. Line: 0, column: 1"}},
+		{"for true { if true { continue; } break; }", nil},
+		{"for true { fn() { break; }; }", []string{"error: break outside of a loop. Line: 0, column: 19"}},
+		{"fn() { var unused = 1; var _ignored = 2; };", []string{
+			"warning: unused is declared but never used. Line: 0, column: 12"}},
+		{"fn() { var i = 0; i = 1; };", []string{
+			"warning: i is declared but never used. Line: 0, column: 12"}},
+		{"fn() { var i = 0; i += 1; };", nil},
+		{"var top = 1;", nil},  // Top level bindings can be used from other places
+		{"println(len('a')); fn() { str(1) };", nil},
+		{"var len = 1;", []string{"error: cannot redeclare builtin: len. Line: 0, column: 5"}},
+		{"fn(type) { 1 };", []string{"error: cannot redeclare builtin: type. Line: 0, column: 4"}},
+		{"print = 1;", []string{"error: cannot assign to builtin: print. Line: 0, column: 7"}},
+		{"try { 1 } catch e { e }; try { 2 } catch e { e };", nil},  // Every catch is its own scope
+		{"try { 1 } catch e { 2 };", nil},  // Like a parameter, nobody has to use it
+		{"try { 1 } catch e { throw f };", []string{"error: undeclared identifier: f. Line: 0, column: 27"}},
+	}
+
+	for _, tt := range tests {
+		result := testCheck(t, tt.input)
+
+		if len(result.Findings) != len(tt.expected) {
+			t.Errorf("%q - wrong number of findings. expected=%q, got=%q", tt.input, tt.expected, result.Findings)
+			continue
+		}
+		for i, finding := range result.Findings {
+			if finding.String() != tt.expected[i] {
+				t.Errorf("%q - findings[%d] wrong. expected=%q, got=%q", tt.input, i, tt.expected[i], finding)
+			}
+		}
+	}
+}
+
+// Function bodies run later, so they can see what's declared after them
+func TestLateBindingInFunctions(t *testing.T) {
+	input := `
+var isEven = fn(n) { if n == 0 { return true; } return isOdd(n - 1); };
+var isOdd = fn(n) { if n == 0 { return false; } return isEven(n - 1); };
+var broken = fn() { missing };
+`
+	result := testCheck(t, input)
+
+	if len(result.Findings) != 1 || result.Findings[0].Message != "undeclared identifier: missing" {
+		t.Fatalf("unexpected findings: %q", result.Findings)
+	}
+	if !result.HasErrors() {
+		t.Errorf("HasErrors() should be true")
+	}
+
+	for ident, decl := range result.Refs {
+		if ident.Value != decl.Name.Value {
+			t.Errorf("%s resolved to the declaration of %s", ident.Value, decl.Name.Value)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package checker
+
+import (
+	"github.com/santos-404/myte/ast"
+)
+
+func (c *checker) checkStatements(statements []ast.Statement) {
+	for _, stmt := range statements {
+		c.checkStatement(stmt)
+	}
+}
+
+func (c *checker) checkStatement(stmt ast.Statement) {
+	switch stmt := stmt.(type) {
+	case *ast.ExpressionStatement:
+		c.checkExpression(stmt.Expression)
+	case *ast.VarStatement:
+		if stmt == nil {
+			return
+		}
+		c.checkExpression(stmt.Value)
+		c.declare(stmt.Name, VarDeclaration, stmt.Value)
+	case *ast.ConstStatement:
+		if stmt == nil {
+			return
+		}
+		c.checkExpression(stmt.Value)
+		c.declare(stmt.Name, ConstDeclaration, stmt.Value)
+	case *ast.ReturnStatement:
+		if c.functionDepth == 0 {
+			c.addError(stmt.Token, "return outside of a function")
+		}
+		c.checkExpression(stmt.ReturnValue)
+	case *ast.BreakStatement:
+		if c.loopDepth == 0 {
+			c.addError(stmt.Token, "break outside of a loop")
+		}
+	case *ast.ContinueStatement:
+		if c.loopDepth == 0 {
+			c.addError(stmt.Token, "continue outside of a loop")
+		}
+	case *ast.BlockStatement:
+		if stmt != nil {
+			c.checkStatements(stmt.Statements)
+		}
+	case *ast.ImportStatement:
+		c.declare(stmt.Name, ImportDeclaration, nil)
+	case *ast.ThrowStatement:
+		c.checkExpression(stmt.Value)
+	}
+}
+
+func (c *checker) checkExpression(exp ast.Expression) {
+	switch exp := exp.(type) {
+	case *ast.Identifier:
+		c.use(exp, read, exp.Token)
+	case *ast.PrefixExpression:
+		// ++x and --x write on x too, so they cannot be used on constants
+		if ident, ok := exp.Right.(*ast.Identifier); ok && (exp.Operator == "++" || exp.Operator == "--") {
+			c.use(ident, read|write, exp.Token)
+			return
+		}
+		c.checkExpression(exp.Right)
+	case *ast.InfixExpression:
+		c.checkExpression(exp.Left)
+		c.checkExpression(exp.Right)
+	case *ast.AssignExpression:
+		c.checkExpression(exp.Value)
+		mode := write
+		if exp.Operator != "=" {
+			mode |= read  // x += 1 reads x before writing it
+		}
+		c.use(exp.Name, mode, exp.Token)
+	case *ast.IfExpression:
+		c.checkExpression(exp.Condition)
+		c.checkBranches(exp.Consequence, exp.Alternative)
+	case *ast.ForExpression:
+		c.checkExpression(exp.Condition)
+		c.loopDepth++
+		c.checkStatement(exp.Body)
+		c.loopDepth--
+	case *ast.TryExpression:
+		c.checkStatement(exp.Body)
+		if exp.Catch != nil {
+			c.checkCatch(exp.Param, exp.Catch)
+		}
+		if exp.Finally != nil {
+			c.checkStatement(exp.Finally)
+		}
+	case *ast.FunctionLiteral:
+		c.checkFunction(exp)
+	case *ast.MemberExpression:
+		c.checkExpression(exp.Object)  // What a module has is only known when it runs
+	case *ast.ArrayLiteral:
+		for _, element := range exp.Elements {
+			c.checkExpression(element)
+		}
+	case *ast.MapLiteral:
+		for i, key := range exp.Keys {
+			c.checkExpression(key)
+			c.checkExpression(exp.Values[i])
+		}
+	case *ast.IndexExpression:
+		c.checkExpression(exp.Left)
+		c.checkExpression(exp.Index)
+	case *ast.PropagateExpression:
+		c.checkExpression(exp.Value)
+	case *ast.CallExpression:
+		c.checkExpression(exp.Function)
+		for _, arg := range exp.Arguments {
+			c.checkExpression(arg)
+		}
+	}
+}
+
+// Only one of the statements runs, see branch
+func (c *checker) checkBranches(blocks ...*ast.BlockStatement) {
+	outer := c.scope.branch
+	var branches []*branch
+	for _, block := range blocks {
+		if block == nil {
+			continue
+		}
+		c.scope.branch = newBranch(outer)
+		c.checkStatement(block)
+		branches = append(branches, c.scope.branch)
+	}
+
+	c.scope.branch = outer
+	for _, b := range branches {
+		for name, decl := range b.names {
+			outer.names[name] = decl
+		}
+	}
+}
+
+// A function gets its own scope, and break or continue cannot cross it
+func (c *checker) checkFunction(fn *ast.FunctionLiteral) {
+	outerScope, outerLoopDepth := c.scope, c.loopDepth
+	c.scope = newScope(outerScope)
+	c.functionDepth++
+	c.loopDepth = 0
+
+	for _, param := range fn.Parameters {
+		c.declare(param, ParamDeclaration, nil)
+	}
+	c.checkStatement(fn.Body)
+
+	c.scope, c.loopDepth = outerScope, outerLoopDepth
+	c.functionDepth--
+}
+
+// The error is a parameter of the catch, so every catch can call it the same
+func (c *checker) checkCatch(param *ast.Identifier, body *ast.BlockStatement) {
+	outerScope := c.scope
+	c.scope = newScope(outerScope)
+	c.declare(param, ParamDeclaration, nil)
+	c.checkStatement(body)
+	c.scope = outerScope
+}
@@ -0,0 +1,246 @@
+package code
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+type Instructions []byte
+
+type Opcode byte
+
+const (
+	OpConstant Opcode = iota
+	OpPop
+	OpTrue
+	OpFalse
+	OpNil
+
+	// Every infix operator gets its own opcode
+	OpAdd
+	OpSub
+	OpMul
+	OpDiv
+	OpFloorDiv
+	OpMod
+	OpPow
+	OpEqual
+	OpNotEqual
+	OpLess
+	OpLessEqual
+	OpGreater
+	OpGreaterEqual
+
+	OpMinus
+	OpBang
+	OpIncrement
+	OpDecrement
+
+	OpJump
+	OpJumpNotTruthy
+
+	OpDefineGlobal
+	OpGetGlobal
+	OpSetGlobal
+	OpDefineLocal
+	OpGetLocal
+	OpSetLocal
+	OpGetOuter
+	OpSetOuter
+	OpAssignConst
+
+	OpClosure
+	OpCall
+	OpReturnValue
+	OpReturn
+	OpTailCall
+	OpGetBuiltin
+
+	OpArray
+	OpIndex
+	OpMap
+
+	OpGetMember
+	OpPropagate
+
+	OpTry
+	OpEndTry
+	OpThrow
+	OpJumpNotError
+	OpOutsideLoop
+
+	OpImport
+)
+
+type Definition struct {
+	Name          string
+	OperandWidths []int
+}
+
+var definitions = map[Opcode]*Definition{
+	OpConstant: {"OpConstant", []int{2}},
+	OpPop:      {"OpPop", []int{}},
+	OpTrue:     {"OpTrue", []int{}},
+	OpFalse:    {"OpFalse", []int{}},
+	OpNil:      {"OpNil", []int{}},
+
+	OpAdd:          {"OpAdd", []int{}},
+	OpSub:          {"OpSub", []int{}},
+	OpMul:          {"OpMul", []int{}},
+	OpDiv:          {"OpDiv", []int{}},
+	OpFloorDiv:     {"OpFloorDiv", []int{}},
+	OpMod:          {"OpMod", []int{}},
+	OpPow:          {"OpPow", []int{}},
+	OpEqual:        {"OpEqual", []int{}},
+	OpNotEqual:     {"OpNotEqual", []int{}},
+	OpLess:         {"OpLess", []int{}},
+	OpLessEqual:    {"OpLessEqual", []int{}},
+	OpGreater:      {"OpGreater", []int{}},
+	OpGreaterEqual: {"OpGreaterEqual", []int{}},
+
+	OpMinus:     {"OpMinus", []int{}},
+	OpBang:      {"OpBang", []int{}},
+	OpIncrement: {"OpIncrement", []int{}},
+	OpDecrement: {"OpDecrement", []int{}},
+
+	OpJump:          {"OpJump", []int{2}},
+	OpJumpNotTruthy: {"OpJumpNotTruthy", []int{2}},
+
+	// The second operand of a global definition is 1 for constants
+	OpDefineGlobal: {"OpDefineGlobal", []int{2, 1}},
+	OpGetGlobal:    {"OpGetGlobal", []int{2}},
+	OpSetGlobal:    {"OpSetGlobal", []int{2}},
+	OpDefineLocal:  {"OpDefineLocal", []int{1}},
+	OpGetLocal:     {"OpGetLocal", []int{1}},
+	OpSetLocal:     {"OpSetLocal", []int{1}},
+	// The outer ones take how many functions up the binding is, and its index there
+	OpGetOuter:    {"OpGetOuter", []int{1, 1}},
+	OpSetOuter:    {"OpSetOuter", []int{1, 1}},
+	OpAssignConst: {"OpAssignConst", []int{2}},
+
+	OpClosure:     {"OpClosure", []int{2}},
+	OpCall:        {"OpCall", []int{1}},
+	OpReturnValue: {"OpReturnValue", []int{}},
+	OpReturn:      {"OpReturn", []int{}},
+	// `return f(x)` inside of a function; f takes the frame of the caller instead of a new one
+	OpTailCall: {"OpTailCall", []int{1}},
+
+	OpGetBuiltin: {"OpGetBuiltin", []int{1}},  // By its index on evaluator.Builtins()
+
+	OpArray: {"OpArray", []int{2}},  // Takes that many elements from the stack
+	OpIndex: {"OpIndex", []int{}},
+	OpMap:   {"OpMap", []int{2}},  // Takes that many pairs, each one is the key and then the value
+
+	OpGetMember: {"OpGetMember", []int{2}},  // The name is a string constant
+	OpPropagate: {"OpPropagate", []int{}},   // Returns the value when it's an error
+
+	// Until its OpEndTry, a failure goes to the operand with the error on the stack
+	OpTry:          {"OpTry", []int{2}},
+	OpEndTry:       {"OpEndTry", []int{}},
+	OpThrow:        {"OpThrow", []int{}},
+	OpJumpNotError: {"OpJumpNotError", []int{2}},  // Leaves the value there, whatever it is
+	// A break (0) or a continue (1) with no loop to go to, it fails once it's out of its tries
+	OpOutsideLoop: {"OpOutsideLoop", []int{1}},
+
+	OpImport: {"OpImport", []int{2}},  // The path is a string constant. The evaluator runs the module.
+}
+
+func Lookup(op byte) (*Definition, error) {
+	def, ok := definitions[Opcode(op)]
+	if !ok {
+		return nil, fmt.Errorf("opcode %d undefined", op)
+	}
+	return def, nil
+}
+
+// Make encodes an instruction. Operands are big endian, and they are expected to fit.
+func Make(op Opcode, operands ...int) []byte {
+	def, ok := definitions[op]
+	if !ok {
+		return []byte{}
+	}
+
+	length := 1
+	for _, width := range def.OperandWidths {
+		length += width
+	}
+
+	instruction := make([]byte, length)
+	instruction[0] = byte(op)
+
+	offset := 1
+	for i, operand := range operands {
+		width := def.OperandWidths[i]
+		switch width {
+		case 2:
+			binary.BigEndian.PutUint16(instruction[offset:], uint16(operand))
+		case 1:
+			instruction[offset] = byte(operand)
+		}
+		offset += width
+	}
+
+	return instruction
+}
+
+// ReadOperands is the inverse of Make. It also gives back how many bytes it read.
+func ReadOperands(def *Definition, ins Instructions) ([]int, int) {
+	operands := make([]int, len(def.OperandWidths))
+	offset := 0
+
+	for i, width := range def.OperandWidths {
+		switch width {
+		case 2:
+			operands[i] = int(ReadUint16(ins[offset:]))
+		case 1:
+			operands[i] = int(ReadUint8(ins[offset:]))
+		}
+		offset += width
+	}
+
+	return operands, offset
+}
+
+func ReadUint16(ins Instructions) uint16 {
+	return binary.BigEndian.Uint16(ins)
+}
+
+func ReadUint8(ins Instructions) uint8 {
+	return uint8(ins[0])
+}
+
+// String prints one instruction per line, preceded by its offset
+func (ins Instructions) String() string {
+	var out bytes.Buffer
+
+	i := 0
+	for i < len(ins) {
+		def, err := Lookup(ins[i])
+		if err != nil {
+			fmt.Fprintf(&out, "ERROR: %s\n", err)
+			i++
+			continue
+		}
+
+		operands, read := ReadOperands(def, ins[i+1:])
+		fmt.Fprintf(&out, "%04d %s\n", i, formatInstruction(def, operands))
+
+		i += 1 + read
+	}
+
+	return out.String()
+}
+
+func formatInstruction(def *Definition, operands []int) string {
+	if len(operands) != len(def.OperandWidths) {
+		return fmt.Sprintf("ERROR: operand len %d does not match defined %d\n",
+			len(operands), len(def.OperandWidths))
+	}
+
+	out := def.Name
+	for _, operand := range operands {
+		out += fmt.Sprintf(" %d", operand)
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+package code
+
+import "testing"
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		op       Opcode
+		operands []int
+		expected []byte
+	}{
+		{OpConstant, []int{65534}, []byte{byte(OpConstant), 255, 254}},
+		{OpAdd, []int{}, []byte{byte(OpAdd)}},
+		{OpGetLocal, []int{255}, []byte{byte(OpGetLocal), 255}},
+		{OpDefineGlobal, []int{258, 1}, []byte{byte(OpDefineGlobal), 1, 2, 1}},
+		{OpGetOuter, []int{2, 7}, []byte{byte(OpGetOuter), 2, 7}},
+	}
+
+	for _, tt := range tests {
+		instruction := Make(tt.op, tt.operands...)
+
+		if len(instruction) != len(tt.expected) {
+			t.Errorf("instruction has wrong length. want=%d, got=%d", len(tt.expected), len(instruction))
+			continue
+		}
+		for i, b := range tt.expected {
+			if instruction[i] != b {
+				t.Errorf("wrong byte at pos %d. want=%d, got=%d", i, b, instruction[i])
+			}
+		}
+	}
+}
+
+func TestReadOperands(t *testing.T) {
+	tests := []struct {
+		op        Opcode
+		operands  []int
+		bytesRead int
+	}{
+		{OpConstant, []int{65535}, 2},
+		{OpCall, []int{255}, 1},
+		{OpSetOuter, []int{1, 200}, 2},
+		{OpDefineGlobal, []int{300, 0}, 3},
+	}
+
+	for _, tt := range tests {
+		instruction := Make(tt.op, tt.operands...)
+
+		def, err := Lookup(byte(tt.op))
+		if err != nil {
+			t.Fatalf("definition not found: %q", err)
+		}
+
+		operandsRead, n := ReadOperands(def, instruction[1:])
+		if n != tt.bytesRead {
+			t.Fatalf("n wrong. want=%d, got=%d", tt.bytesRead, n)
+		}
+		for i, want := range tt.operands {
+			if operandsRead[i] != want {
+				t.Errorf("operand wrong. want=%d, got=%d", want, operandsRead[i])
+			}
+		}
+	}
+}
+
+func TestInstructionsString(t *testing.T) {
+	instructions := []Instructions{
+		Make(OpAdd),
+		Make(OpGetLocal, 1),
+		Make(OpConstant, 2),
+		Make(OpConstant, 65535),
+		Make(OpGetOuter, 1, 3),
+	}
+
+	expected := `0000 OpAdd
+0001 OpGetLocal 1
+0003 OpConstant 2
+0006 OpConstant 65535
+0009 OpGetOuter 1 3
+`
+
+	concatted := Instructions{}
+	for _, ins := range instructions {
+		concatted = append(concatted, ins...)
+	}
+
+	if concatted.String() != expected {
+		t.Errorf("instructions wrongly formatted.\nwant=%q\ngot=%q", expected, concatted.String())
+	}
+}
+
+func TestLineTable(t *testing.T) {
+	var table LineTable
+	table.Add(0, 1, 1)
+	table.Add(3, 1, 1)  // Same position, nothing new
+	table.Add(3, 1, 5)
+	table.Add(3, 2, 1)  // Same offset, the last one wins
+	table.Add(7, 4, 2)
+
+	if len(table) != 3 {
+		t.Fatalf("wrong number of entries. got=%+v", table)
+	}
+
+	tests := []struct {
+		offset       int
+		expectedLine int
+		expectedCol  int
+	}{
+		{0, 1, 1},
+		{2, 1, 1},
+		{3, 2, 1},
+		{6, 2, 1},
+		{100, 4, 2},
+	}
+
+	for _, tt := range tests {
+		position, ok := table.Lookup(tt.offset)
+		if !ok || position.Line != tt.expectedLine || position.Column != tt.expectedCol {
+			t.Errorf("Lookup(%d) wrong. expected=%d:%d, got=%+v", tt.offset, tt.expectedLine,
+				tt.expectedCol, position)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package code
+
+import "sort"
+
+// SourcePosition says where the code of the instructions starting at Offset was written
+type SourcePosition struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+/*
+LineTable is the debug info of some instructions. There is one entry every time the
+position changes, sorted by offset, so an instruction belongs to the last entry at
+or before it.
+*/
+type LineTable []SourcePosition
+
+func (t *LineTable) Add(offset, line, column int) {
+	if n := len(*t); n > 0 {
+		last := (*t)[n-1]
+		if last.Line == line && last.Column == column {
+			return
+		}
+		if last.Offset == offset {
+			(*t)[n-1] = SourcePosition{offset, line, column}
+			return
+		}
+	}
+	*t = append(*t, SourcePosition{offset, line, column})
+}
+
+func (t LineTable) Lookup(offset int) (SourcePosition, bool) {
+	i := sort.Search(len(t), func(i int) bool { return t[i].Offset > offset })
+	if i == 0 {
+		return SourcePosition{}, false
+	}
+	return t[i-1], true
+}
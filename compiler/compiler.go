@@ -0,0 +1,239 @@
+package compiler
+
+import (
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/code"
+	"github.com/santos-404/myte/object"
+	"github.com/santos-404/myte/token"
+)
+
+// The operands are 1 byte for these
+const (
+	MAX_LOCALS    = 256
+	MAX_ARGUMENTS = 255
+)
+
+// And 2 bytes for the rest: the constants, the globals, where the jumps land...
+const MAX_OPERAND = 65535
+
+type Bytecode struct {
+	Instructions code.Instructions
+	Lines        code.LineTable
+	Constants    []object.Object
+	Globals      []string  // The names of the global slots, by index
+}
+
+type loop struct {
+	start    int
+	breaks   []int  // These jumps must land after the loop, but we only know where that is at the end
+	tryDepth int    // How many tries there were around it, a break only leaves the ones inside
+}
+
+// The finally (if any) of a try we are in. Whatever leaves the try early must run it.
+type tryBlock struct {
+	finally *ast.BlockStatement
+}
+
+// Every function is compiled on its own scope, the top level code included
+type CompilationScope struct {
+	instructions code.Instructions
+	lines        code.LineTable
+	loops        []*loop
+	tries        []*tryBlock
+}
+
+type Compiler struct {
+	constants   []object.Object
+	symbolTable *SymbolTable
+	scopes      []*CompilationScope
+
+	err error  // The first operand that didn't fit, see checkOperands
+}
+
+func New() *Compiler {
+	return &Compiler{
+		symbolTable: NewSymbolTable(),
+		scopes:      []*CompilationScope{{}},
+	}
+}
+
+/*
+Compile turns the program into the top level code. It ends with the value of the
+last statement, the same one the evaluator would give back, or without any value
+when that statement is not an expression (a declaration, for instance).
+*/
+func (c *Compiler) Compile(program *ast.Program) error {
+	for i, stmt := range program.Statements {
+		if i == len(program.Statements)-1 {
+			if exp, ok := statementValue(stmt); ok {
+				if err := c.compileExpression(exp); err != nil {
+					return err
+				}
+				c.emit(token.Token{}, code.OpReturnValue)
+				return c.err
+			}
+		}
+
+		if err := c.compileStatement(stmt); err != nil {
+			return err
+		}
+	}
+
+	c.emit(token.Token{}, code.OpReturn)
+	return c.err
+}
+
+func (c *Compiler) Bytecode() *Bytecode {
+	return &Bytecode{
+		Instructions: c.currentScope().instructions,
+		Lines:        c.currentScope().lines,
+		Constants:    c.constants,
+		Globals:      c.symbolTable.global().Names(),
+	}
+}
+
+// statementValue gives back the expression of the statements that produce a value
+func statementValue(stmt ast.Statement) (ast.Expression, bool) {
+	exp, ok := stmt.(*ast.ExpressionStatement)
+	if !ok || exp.Expression == nil {
+		return nil, false
+	}
+	if _, ok := exp.Expression.(*ast.CommentExpression); ok {
+		return nil, false
+	}
+	return exp.Expression, true
+}
+
+func (c *Compiler) currentScope() *CompilationScope {
+	return c.scopes[len(c.scopes)-1]
+}
+
+func (c *Compiler) enterScope() {
+	c.scopes = append(c.scopes, &CompilationScope{})
+	c.symbolTable = NewEnclosedSymbolTable(c.symbolTable)
+}
+
+func (c *Compiler) leaveScope() *CompilationScope {
+	scope := c.currentScope()
+	c.scopes = c.scopes[:len(c.scopes)-1]
+	c.symbolTable = c.symbolTable.Outer
+	return scope
+}
+
+func (c *Compiler) addConstant(obj object.Object) int {
+	c.constants = append(c.constants, obj)
+	return len(c.constants) - 1
+}
+
+// emit adds the instruction and gives back where it starts. The token is where the
+// instruction comes from; a zero token (columns start at 1) keeps the previous one.
+func (c *Compiler) emit(tok token.Token, op code.Opcode, operands ...int) int {
+	scope := c.currentScope()
+	position := len(scope.instructions)
+
+	if tok.Column != 0 {
+		scope.lines.Add(position, tok.Line, tok.Column)
+	}
+	c.checkOperands(position, op, operands)
+	scope.instructions = append(scope.instructions, code.Make(op, operands...)...)
+	return position
+}
+
+// The jumps are emitted before we know where they go, this fixes them afterwards
+func (c *Compiler) changeOperand(position int, operand int) {
+	ins := c.currentScope().instructions
+	op := code.Opcode(ins[position])
+	c.checkOperands(position, op, []int{operand})
+	copy(ins[position:], code.Make(op, operand))
+}
+
+/*
+checkOperands makes sure the operands fit in their bytes, otherwise code.Make would
+cut them short and the program would quietly do something else. There are too many
+places that emit to check it on each one, so the first error is kept and Compile
+gives it back at the end.
+*/
+func (c *Compiler) checkOperands(position int, op code.Opcode, operands []int) {
+	def, err := code.Lookup(byte(op))
+	if err != nil || c.err != nil {
+		return
+	}
+
+	for i, operand := range operands {
+		limit := 1<<(8*def.OperandWidths[i]) - 1
+		if operand <= limit {
+			continue
+		}
+
+		var tok token.Token
+		if pos, ok := c.currentScope().lines.Lookup(position); ok {
+			tok.Line, tok.Column = pos.Line, pos.Column
+		}
+		switch op {
+		case code.OpConstant, code.OpClosure, code.OpGetMember, code.OpAssignConst, code.OpImport:
+			c.err = newError(tok, "too many constants: %d, the limit is %d", operand+1, limit+1)
+		case code.OpJump, code.OpJumpNotTruthy, code.OpTry, code.OpJumpNotError:
+			c.err = newError(tok, "too much code to jump over: %d bytes, the limit is %d", operand, limit)
+		case code.OpDefineGlobal, code.OpGetGlobal, code.OpSetGlobal:
+			c.err = newError(tok, "too many global bindings: %d, the limit is %d", operand+1, limit+1)
+		case code.OpArray, code.OpMap:
+			c.err = newError(tok, "too many elements: %d, the limit is %d", operand, limit)
+		default:
+			c.err = newError(tok, "operand too big for %s: %d", def.Name, operand)
+		}
+		return
+	}
+}
+
+func (c *Compiler) compileStatements(statements []ast.Statement) error {
+	for _, stmt := range statements {
+		if err := c.compileStatement(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+leaveTries is what a return, a break or a ? does before jumping out of the tries
+above depth: every one of them stops catching, and its finally runs. A finally is
+compiled without its own try, so a return in there doesn't run it again.
+*/
+func (c *Compiler) leaveTries(depth int) error {
+	scope := c.currentScope()
+	tries := scope.tries
+	defer func() { scope.tries = tries }()
+
+	for i := len(tries) - 1; i >= depth; i-- {
+		scope.tries = tries[:i]
+		c.emit(token.Token{}, code.OpEndTry)
+		if tries[i].finally != nil {
+			if err := c.compileStatements(tries[i].finally.Statements); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// compileBlockValue leaves on the stack the value the block ends with (nil if none)
+func (c *Compiler) compileBlockValue(block *ast.BlockStatement) error {
+	if block == nil || len(block.Statements) == 0 {
+		c.emit(token.Token{}, code.OpNil)
+		return nil
+	}
+
+	last := len(block.Statements) - 1
+	if err := c.compileStatements(block.Statements[:last]); err != nil {
+		return err
+	}
+
+	if exp, ok := statementValue(block.Statements[last]); ok {
+		return c.compileExpression(exp)
+	}
+	if err := c.compileStatement(block.Statements[last]); err != nil {
+		return err
+	}
+	c.emit(token.Token{}, code.OpNil)
+	return nil
+}
@@ -0,0 +1,695 @@
+package compiler
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/code"
+	"github.com/santos-404/myte/lexer"
+	"github.com/santos-404/myte/object"
+	"github.com/santos-404/myte/parser"
+)
+
+type compilerTestCase struct {
+	input                string
+	expectedConstants    []interface{}
+	expectedInstructions []code.Instructions
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has errors for %q: %v", input, p.Errors())
+	}
+	return program
+}
+
+func runCompilerTests(t *testing.T, tests []compilerTestCase) {
+	t.Helper()
+
+	for _, tt := range tests {
+		compiler := New()
+		if err := compiler.Compile(parse(t, tt.input)); err != nil {
+			t.Fatalf("%q - compiler error: %s", tt.input, err)
+		}
+
+		bytecode := compiler.Bytecode()
+		testInstructions(t, tt.input, tt.expectedInstructions, bytecode.Instructions)
+		testConstants(t, tt.input, tt.expectedConstants, bytecode.Constants)
+	}
+}
+
+func concatInstructions(s []code.Instructions) code.Instructions {
+	out := code.Instructions{}
+	for _, ins := range s {
+		out = append(out, ins...)
+	}
+	return out
+}
+
+func testInstructions(t *testing.T, input string, expected []code.Instructions, actual code.Instructions) {
+	t.Helper()
+
+	concatted := concatInstructions(expected)
+	if concatted.String() != actual.String() {
+		t.Errorf("%q - wrong instructions.\nwant=\n%s\ngot=\n%s", input, concatted, actual)
+	}
+}
+
+func testConstants(t *testing.T, input string, expected []interface{}, actual []object.Object) {
+	t.Helper()
+
+	if len(expected) != len(actual) {
+		t.Errorf("%q - wrong number of constants. want=%d, got=%d", input, len(expected), len(actual))
+		return
+	}
+
+	for i, constant := range expected {
+		switch constant := constant.(type) {
+		case int:
+			if integer, ok := actual[i].(*object.Integer); !ok || integer.Value != int64(constant) {
+				t.Errorf("%q - constant %d wrong. want=%d, got=%s", input, i, constant, actual[i].Inspect())
+			}
+		case string:
+			if str, ok := actual[i].(*object.String); !ok || str.Value != constant {
+				t.Errorf("%q - constant %d wrong. want=%q, got=%s", input, i, constant, actual[i].Inspect())
+			}
+		case []code.Instructions:
+			fn, ok := actual[i].(*object.CompiledFunction)
+			if !ok {
+				t.Errorf("%q - constant %d is not a function. got=%T", input, i, actual[i])
+				continue
+			}
+			testInstructions(t, input, constant, fn.Instructions)
+		}
+	}
+}
+
+func TestExpressions(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "1 + 2; 'a'",
+			expectedConstants: []interface{}{1, 2, "a"},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpAdd),
+				code.Make(code.OpPop),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			input:             "-1 // 2 == !true",
+			expectedConstants: []interface{}{1, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpMinus),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpFloorDiv),
+				code.Make(code.OpTrue),
+				code.Make(code.OpBang),
+				code.Make(code.OpEqual),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			input:             "if true { 10 }; 3333",
+			expectedConstants: []interface{}{10, 3333},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpTrue),
+				code.Make(code.OpJumpNotTruthy, 10),
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpJump, 11),
+				code.Make(code.OpNil),
+				code.Make(code.OpPop),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			input:             "for true { break; continue; }",
+			expectedConstants: []interface{}{},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpTrue),
+				code.Make(code.OpJumpNotTruthy, 13),
+				code.Make(code.OpJump, 13),
+				code.Make(code.OpJump, 0),
+				code.Make(code.OpJump, 0),
+				code.Make(code.OpNil),
+				code.Make(code.OpReturnValue),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestBindings(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "var one = 1; const two = 2; one += two;",
+			expectedConstants: []interface{}{1, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpDefineGlobal, 0, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpDefineGlobal, 1, 1),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpGetGlobal, 1),
+				code.Make(code.OpAdd),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			// The name is used before it's declared, so the slot is given on the first use
+			input:             "later; var later = 1;",
+			expectedConstants: []interface{}{1},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpPop),
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpDefineGlobal, 0, 0),
+				code.Make(code.OpReturn),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestFunctions(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "fn(a) { var b = a; b }",
+			expectedConstants: []interface{}{
+				[]code.Instructions{
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpDefineLocal, 1),
+					code.Make(code.OpGetLocal, 1),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 0),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			// inner sees a binding of its parent that is declared after it
+			input: "fn() { const inner = fn() { x = 1 }; var x = 0; inner() }",
+			expectedConstants: []interface{}{
+				1,
+				[]code.Instructions{
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpSetOuter, 1, 1),
+					code.Make(code.OpReturnValue),
+				},
+				0,
+				[]code.Instructions{
+					code.Make(code.OpClosure, 1),
+					code.Make(code.OpDefineLocal, 0),
+					code.Make(code.OpConstant, 2),
+					code.Make(code.OpDefineLocal, 1),
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpCall, 0),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 3),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			input:             "fn(n) { return f(n); }",
+			expectedConstants: []interface{}{
+				[]code.Instructions{
+					code.Make(code.OpGetGlobal, 0),
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpTailCall, 1),
+					code.Make(code.OpNil),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 0),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			input:             "fn() { const k = 1; k = 2; }",
+			expectedConstants: []interface{}{
+				1,
+				2,
+				"k",
+				[]code.Instructions{
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpDefineLocal, 0),
+					code.Make(code.OpConstant, 1),
+					code.Make(code.OpAssignConst, 2),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 3),
+				code.Make(code.OpReturnValue),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestBuiltins(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "len('a'); fn() { print }",
+			expectedConstants: []interface{}{
+				"a",
+				[]code.Instructions{
+					code.Make(code.OpGetBuiltin, 0),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpGetBuiltin, 2),
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpCall, 1),
+				code.Make(code.OpPop),
+				code.Make(code.OpClosure, 1),
+				code.Make(code.OpReturnValue),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestArrays(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "[]",
+			expectedConstants: []interface{}{},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpArray, 0),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			input:             "[1, 2 + 3][0]",
+			expectedConstants: []interface{}{1, 2, 3, 0},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpAdd),
+				code.Make(code.OpArray, 2),
+				code.Make(code.OpConstant, 3),
+				code.Make(code.OpIndex),
+				code.Make(code.OpReturnValue),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestMaps(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "{'a': 1, 'b': 2}['a']",
+			expectedConstants: []interface{}{"a", 1, "b", 2, "a"},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpConstant, 3),
+				code.Make(code.OpMap, 2),
+				code.Make(code.OpConstant, 4),
+				code.Make(code.OpIndex),
+				code.Make(code.OpReturnValue),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestErrorValues(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "int('x')?.message",
+			expectedConstants: []interface{}{"x", "message"},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpGetBuiltin, 5),
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpCall, 1),
+				code.Make(code.OpPropagate),
+				code.Make(code.OpGetMember, 1),
+				code.Make(code.OpReturnValue),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestImports(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             `import "math"; math.pi`,
+			expectedConstants: []interface{}{"math", "pi"},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpImport, 0),
+				code.Make(code.OpDefineGlobal, 0, 1),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpGetMember, 1),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			input:             `fn() { import "./lib" as l; l }`,
+			expectedConstants: []interface{}{
+				"./lib",
+				[]code.Instructions{
+					code.Make(code.OpImport, 0),
+					code.Make(code.OpDefineLocal, 0),
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 1),
+				code.Make(code.OpReturnValue),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestTryExpressions(t *testing.T) {
+	tests := []compilerTestCase{
+		{
+			input:             "try { 1 } catch e { 2 }",
+			expectedConstants: []interface{}{1, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpTry, 10),
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpEndTry),
+				code.Make(code.OpJump, 17),
+				code.Make(code.OpDefineGlobal, 0, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			// Without a catch, the error is thrown again after the finally. It's there twice,
+			// once for each way out of the body.
+			input:             "try { 1 } finally { 2 }",
+			expectedConstants: []interface{}{1, 2, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpTry, 14),
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpEndTry),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpPop),
+				code.Make(code.OpJump, 19),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpPop),
+				code.Make(code.OpThrow),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			input:             "throw 'x'",
+			expectedConstants: []interface{}{"x"},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpThrow),
+				code.Make(code.OpReturn),
+			},
+		},
+		{
+			// It only fails when it runs, after the finallys it's in
+			input:             "try { break } finally { 1 }",
+			expectedConstants: []interface{}{1, 1, 1},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpTry, 19),
+				code.Make(code.OpEndTry),
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpPop),
+				code.Make(code.OpOutsideLoop, 0),
+				code.Make(code.OpNil),
+				code.Make(code.OpEndTry),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpPop),
+				code.Make(code.OpJump, 24),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpPop),
+				code.Make(code.OpThrow),
+				code.Make(code.OpReturnValue),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestCompilerErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var len = 1;", "cannot redeclare builtin: len. Line: 0, column: 5"},
+		{"fn(a, str) { a }", "cannot redeclare builtin: str. Line: 0, column: 7"},
+		{"fn() { print = 1 }", "cannot assign to builtin: print. Line: 0, column: 8"},
+		{"++type", "cannot assign to builtin: type. Line: 0, column: 3"},
+		{`import "math" as len;`, "cannot redeclare builtin: len. Line: 0, column: 18"},
+	}
+
+	for _, tt := range tests {
+		err := New().Compile(parse(t, tt.input))
+		if err == nil {
+			t.Errorf("%q - expected an error", tt.input)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("%q - error wrong. expected=%q, got=%q", tt.input, tt.expected, err)
+		}
+	}
+}
+
+// The operands that don't fit in their 2 bytes must fail to compile, not wrap around
+func TestOperandLimits(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var x = 0;\n" + strings.Repeat("x = x + 1;\n", 70000) + "x",
+			"too many constants: 65537, the limit is 65536. Line: 65536, column: 9"},
+		{"var x = 0;\nif x == 0 {\n" + strings.Repeat("x;\n", 20000) + "}",
+			"too much code to jump over: 80019 bytes, the limit is 65535. Line: 1, column: 1"},
+	}
+
+	for i, tt := range tests {
+		err := New().Compile(parse(t, tt.input))
+		if err == nil {
+			t.Errorf("tests[%d] - expected an error", i)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("tests[%d] - error wrong. expected=%q, got=%q", i, tt.expected, err)
+		}
+	}
+
+	// Right at the limit is still fine
+	input := "var x = 0;\n" + strings.Repeat("x = x + 1;\n", MAX_OPERAND-1) + "x"
+	if err := New().Compile(parse(t, input)); err != nil {
+		t.Errorf("the program at the limit failed: %s", err)
+	}
+}
+
+func TestLines(t *testing.T) {
+	compiler := New()
+	if err := compiler.Compile(parse(t, "var x = 1;\nx + y")); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+	bytecode := compiler.Bytecode()
+
+	// The OpAdd comes from the '+' on the second line
+	position, ok := bytecode.Lines.Lookup(13)
+	if !ok || position.Line != 1 || position.Column != 3 {
+		t.Errorf("position of OpAdd wrong. got=%+v", position)
+	}
+	if len(bytecode.Globals) != 2 || bytecode.Globals[1] != "y" {
+		t.Errorf("globals wrong. got=%q", bytecode.Globals)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	compiler := New()
+	input := "const f = fn(a, b) { var c = a * 2.5; c + b }; f(1, 2) + 'x'"
+	if err := compiler.Compile(parse(t, input)); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+	original := compiler.Bytecode()
+
+	data, err := original.Encode()
+	if err != nil {
+		t.Fatalf("encode error: %s", err)
+	}
+	if !strings.HasPrefix(string(data), MAGIC) {
+		t.Fatalf("the magic is missing. got=%q", data[:8])
+	}
+
+	decoded, err := Decode(data)
+	if err != nil {
+		t.Fatalf("decode error: %s", err)
+	}
+
+	testInstructions(t, input, []code.Instructions{original.Instructions}, decoded.Instructions)
+	if len(decoded.Lines) != len(original.Lines) || decoded.Lines[2] != original.Lines[2] {
+		t.Errorf("lines wrong. want=%+v, got=%+v", original.Lines, decoded.Lines)
+	}
+	if strings.Join(decoded.Globals, ",") != "f" {
+		t.Errorf("globals wrong. got=%q", decoded.Globals)
+	}
+	if len(decoded.Constants) != len(original.Constants) {
+		t.Fatalf("wrong number of constants. want=%d, got=%d", len(original.Constants), len(decoded.Constants))
+	}
+	for i, constant := range original.Constants {
+		if fn, ok := constant.(*object.CompiledFunction); ok {
+			got, ok := decoded.Constants[i].(*object.CompiledFunction)
+			if !ok || got.Instructions.String() != fn.Instructions.String() ||
+				got.NumLocals != fn.NumLocals || got.NumParameters != fn.NumParameters ||
+				strings.Join(got.Names, ",") != strings.Join(fn.Names, ",") || len(got.Lines) != len(fn.Lines) {
+				t.Errorf("constant %d wrong. want=%+v, got=%+v", i, fn, decoded.Constants[i])
+			}
+			continue
+		}
+		if decoded.Constants[i].Inspect() != constant.Inspect() {
+			t.Errorf("constant %d wrong. want=%s, got=%s", i, constant.Inspect(), decoded.Constants[i].Inspect())
+		}
+	}
+}
+
+func TestDecodeRejectsBadFiles(t *testing.T) {
+	compiler := New()
+	if err := compiler.Compile(parse(t, "const f = fn(x) { x + 1 }; f(41)")); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+	valid, err := compiler.Bytecode().Encode()
+	if err != nil {
+		t.Fatalf("encode error: %s", err)
+	}
+
+	modified := func(change func(data []byte) []byte) []byte {
+		data := append([]byte{}, valid...)
+		return change(data)
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{"empty", []byte{}, "not a myte bytecode file"},
+		{"source code", []byte("const x = 1;\nx + 1\n"), "not a myte bytecode file"},
+		{"version", modified(func(d []byte) []byte { d[6] = 99; return d }),
+			"unsupported bytecode version 99 (this myte reads version 4), build the file again"},
+		{"instruction set", modified(func(d []byte) []byte { d[8] ^= 0x01; return d }),
+			"the bytecode was built by another myte, its instructions mean something else here; build the file again"},
+		{"truncated", valid[:len(valid)-3], "corrupt bytecode file: unexpected end of data"},
+		{"flipped bit", modified(func(d []byte) []byte { d[len(d)-2] ^= 0x10; return d }),
+			"corrupt bytecode file: checksum mismatch"},
+	}
+
+	for _, tt := range tests {
+		_, err := Decode(tt.data)
+		if err == nil {
+			t.Errorf("%s - expected an error", tt.name)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("%s - error wrong. expected=%q, got=%q", tt.name, tt.expected, err)
+		}
+	}
+}
+
+// A file with a valid checksum can still be wrong, if it was written by hand
+func TestDecodeValidatesOperands(t *testing.T) {
+	bytecode := &Bytecode{
+		Instructions: concatInstructions([]code.Instructions{
+			code.Make(code.OpConstant, 5),
+			code.Make(code.OpReturnValue),
+		}),
+	}
+	data, err := bytecode.Encode()
+	if err != nil {
+		t.Fatalf("encode error: %s", err)
+	}
+
+	_, err = Decode(data)
+	expected := "corrupt bytecode file: bad operand on OpConstant at offset 0"
+	if err == nil || err.Error() != expected {
+		t.Errorf("error wrong. expected=%q, got=%v", expected, err)
+	}
+
+	bytecode.Instructions = concatInstructions([]code.Instructions{
+		code.Make(code.OpGetBuiltin, 200),
+		code.Make(code.OpReturnValue),
+	})
+	if data, err = bytecode.Encode(); err != nil {
+		t.Fatalf("encode error: %s", err)
+	}
+	_, err = Decode(data)
+	expected = "corrupt bytecode file: bad operand on OpGetBuiltin at offset 0"
+	if err == nil || err.Error() != expected {
+		t.Errorf("error wrong. expected=%q, got=%v", expected, err)
+	}
+}
+
+func TestDisassemble(t *testing.T) {
+	input := "const greet = fn(name) {\n\t'hi ' + name\n};\ngreet('myte')"
+	compiler := New()
+	if err := compiler.Compile(parse(t, input)); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	var out bytes.Buffer
+	Disassemble(&out, compiler.Bytecode(), input)
+
+	expected := `== main ==
+     ; 0 | const greet = fn(name) {
+0000 OpClosure 1            <fn 1>                   0:15
+0003 OpDefineGlobal 0 1     greet                    0:7
+     ; 3 | greet('myte')
+0007 OpGetGlobal 0          greet                    3:1
+0010 OpConstant 2           "myte"                   3:7
+0013 OpCall 1               1 arguments              3:6
+0015 OpReturnValue                                   3:6
+
+== fn 1 (parameters: 1, locals: 1) ==
+     ; 1 | 'hi ' + name
+0000 OpConstant 0           "hi "                    1:5
+0003 OpGetLocal 0           name                     1:13
+0005 OpAdd                                           1:11
+0006 OpReturnValue                                   1:11
+`
+	if out.String() != expected {
+		t.Errorf("disassembly wrong.\nwant=\n%s\ngot=\n%s", expected, out.String())
+	}
+
+	// Without the source there are no source lines, but the positions stay
+	out.Reset()
+	Disassemble(&out, compiler.Bytecode(), "")
+	if strings.Contains(out.String(), ";") || !strings.Contains(out.String(), "1:13") {
+		t.Errorf("disassembly without source wrong. got=\n%s", out.String())
+	}
+}
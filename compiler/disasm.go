@@ -0,0 +1,126 @@
+package compiler
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/santos-404/myte/code"
+	"github.com/santos-404/myte/evaluator"
+	"github.com/santos-404/myte/object"
+)
+
+/*
+Disassemble prints the top level code and then every function on the constant pool,
+one instruction per line: its offset, the opcode with its operands, what those
+operands mean and where the instruction comes from. The source is optional (a
+.mytec file doesn't have it); when we have it, every source line is printed above
+the first instruction it produced.
+*/
+func Disassemble(out io.Writer, bytecode *Bytecode, source string) {
+	var lines []string
+	if source != "" {
+		lines = strings.Split(source, "\n")
+	}
+
+	d := &disassembler{out: out, bytecode: bytecode, source: lines}
+
+	fmt.Fprintln(out, "== main ==")
+	d.instructions(bytecode.Instructions, bytecode.Lines, nil)
+
+	for i, constant := range bytecode.Constants {
+		if fn, ok := constant.(*object.CompiledFunction); ok {
+			fmt.Fprintf(out, "\n== fn %d (parameters: %d, locals: %d) ==\n", i, fn.NumParameters, fn.NumLocals)
+			d.instructions(fn.Instructions, fn.Lines, fn)
+		}
+	}
+}
+
+type disassembler struct {
+	out      io.Writer
+	bytecode *Bytecode
+	source   []string
+}
+
+// fn is nil for the top level code
+func (d *disassembler) instructions(ins code.Instructions, lines code.LineTable, fn *object.CompiledFunction) {
+	lastLine := -1
+
+	for ip := 0; ip < len(ins); {
+		def, err := code.Lookup(ins[ip])
+		if err != nil {
+			fmt.Fprintf(d.out, "%04d ERROR: %s\n", ip, err)
+			ip++
+			continue
+		}
+		operands, read := code.ReadOperands(def, ins[ip+1:])
+
+		position, ok := lines.Lookup(ip)
+		where := ""
+		if ok {
+			where = fmt.Sprintf("%d:%d", position.Line, position.Column)
+			if position.Line != lastLine && position.Line < len(d.source) {
+				fmt.Fprintf(d.out, "     ; %d | %s\n", position.Line, strings.TrimSpace(d.source[position.Line]))
+			}
+			lastLine = position.Line
+		}
+
+		instruction := def.Name
+		for _, operand := range operands {
+			instruction += " " + strconv.Itoa(operand)
+		}
+		fmt.Fprintf(d.out, "%04d %-22s %-24s %s\n", ip, instruction,
+			d.describe(code.Opcode(ins[ip]), operands, fn), where)
+
+		ip += 1 + read
+	}
+}
+
+// describe tells what the operands point to: the constant, the binding, the target...
+func (d *disassembler) describe(op code.Opcode, operands []int, fn *object.CompiledFunction) string {
+	switch op {
+	case code.OpConstant, code.OpAssignConst, code.OpGetMember, code.OpImport:
+		return d.constant(operands[0])
+	case code.OpClosure:
+		return fmt.Sprintf("<fn %d>", operands[0])
+	case code.OpJump, code.OpJumpNotTruthy, code.OpTry, code.OpJumpNotError:
+		return fmt.Sprintf("-> %04d", operands[0])
+	case code.OpDefineGlobal, code.OpGetGlobal, code.OpSetGlobal:
+		if operands[0] < len(d.bytecode.Globals) {
+			return d.bytecode.Globals[operands[0]]
+		}
+	case code.OpDefineLocal, code.OpGetLocal, code.OpSetLocal:
+		if fn != nil && operands[0] < len(fn.Names) {
+			return fn.Names[operands[0]]
+		}
+	case code.OpGetOuter, code.OpSetOuter:
+		return fmt.Sprintf("%d up, slot %d", operands[0], operands[1])
+	case code.OpCall:
+		return fmt.Sprintf("%d arguments", operands[0])
+	case code.OpArray:
+		return fmt.Sprintf("%d elements", operands[0])
+	case code.OpMap:
+		return fmt.Sprintf("%d pairs", operands[0])
+	case code.OpGetBuiltin:
+		if operands[0] < len(evaluator.Builtins()) {
+			return evaluator.Builtins()[operands[0]].Name
+		}
+	}
+	return ""
+}
+
+func (d *disassembler) constant(index int) string {
+	if index >= len(d.bytecode.Constants) {
+		return "<missing>"
+	}
+
+	switch constant := d.bytecode.Constants[index].(type) {
+	case *object.String:
+		return strconv.Quote(constant.Value)
+	case *object.CompiledFunction:
+		return fmt.Sprintf("<fn %d>", index)
+	default:
+		return constant.Inspect()
+	}
+}
@@ -0,0 +1,25 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/santos-404/myte/token"
+)
+
+type CompileError struct {
+	Message string
+	Line    int
+	Column  int
+}
+
+func (e *CompileError) Error() string {
+	return fmt.Sprintf("%s. Line: %d, column: %d", e.Message, e.Line, e.Column)
+}
+
+func newError(tok token.Token, format string, a ...interface{}) *CompileError {
+	return &CompileError{
+		Message: fmt.Sprintf(format, a...),
+		Line:    tok.Line,
+		Column:  tok.Column,
+	}
+}
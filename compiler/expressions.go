@@ -0,0 +1,432 @@
+package compiler
+
+import (
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/code"
+	"github.com/santos-404/myte/evaluator"
+	"github.com/santos-404/myte/object"
+	"github.com/santos-404/myte/token"
+)
+
+var infixOpcodes = map[string]code.Opcode{
+	"+":  code.OpAdd,
+	"-":  code.OpSub,
+	"*":  code.OpMul,
+	"/":  code.OpDiv,
+	"//": code.OpFloorDiv,
+	"%":  code.OpMod,
+	"**": code.OpPow,
+	"==": code.OpEqual,
+	"!=": code.OpNotEqual,
+	"<":  code.OpLess,
+	"<=": code.OpLessEqual,
+	">":  code.OpGreater,
+	">=": code.OpGreaterEqual,
+}
+
+var compoundOperators = map[string]string{"+=": "+", "-=": "-", "*=": "*", "/=": "/"}
+
+// Every expression leaves exactly one value on the stack
+func (c *Compiler) compileExpression(exp ast.Expression) error {
+	switch exp := exp.(type) {
+	case nil:
+		c.emit(token.Token{}, code.OpNil)
+	case *ast.IntegerLiteral:
+		c.emit(exp.Token, code.OpConstant, c.addConstant(&object.Integer{Value: exp.Value}))
+	case *ast.FloatLiteral:
+		c.emit(exp.Token, code.OpConstant, c.addConstant(&object.Float{Value: exp.Value}))
+	case *ast.StringLiteral:
+		str := &object.String{Value: evaluator.DecodeString(exp.Value)}
+		c.emit(exp.Token, code.OpConstant, c.addConstant(str))
+	case *ast.BooleanLiteral:
+		if exp.Value {
+			c.emit(exp.Token, code.OpTrue)
+		} else {
+			c.emit(exp.Token, code.OpFalse)
+		}
+	case *ast.NilLiteral:
+		c.emit(exp.Token, code.OpNil)
+	case *ast.CommentExpression:
+		c.emit(exp.Token, code.OpNil)
+
+	case *ast.Identifier:
+		c.loadSymbol(exp)
+	case *ast.PrefixExpression:
+		return c.compilePrefixExpression(exp)
+	case *ast.InfixExpression:
+		if err := c.compileExpression(exp.Left); err != nil {
+			return err
+		}
+		if err := c.compileExpression(exp.Right); err != nil {
+			return err
+		}
+		op, ok := infixOpcodes[exp.Operator]
+		if !ok {
+			return newError(exp.Token, "unknown operator: %s", exp.Operator)
+		}
+		c.emit(exp.Token, op)
+	case *ast.AssignExpression:
+		return c.compileAssignExpression(exp)
+
+	case *ast.IfExpression:
+		return c.compileIfExpression(exp)
+	case *ast.ForExpression:
+		return c.compileForExpression(exp)
+	case *ast.FunctionLiteral:
+		return c.compileFunctionLiteral(exp)
+	case *ast.CallExpression:
+		return c.compileCallExpression(exp, code.OpCall)
+	case *ast.MemberExpression:
+		// A module or a value with members, like an error. Only the vm knows which.
+		if err := c.compileExpression(exp.Object); err != nil {
+			return err
+		}
+		name := c.addConstant(&object.String{Value: exp.Member.Value})
+		c.emit(exp.Member.Token, code.OpGetMember, name)
+	case *ast.PropagateExpression:
+		return c.compilePropagateExpression(exp)
+	case *ast.TryExpression:
+		return c.compileTryExpression(exp)
+	case *ast.ArrayLiteral:
+		for _, element := range exp.Elements {
+			if err := c.compileExpression(element); err != nil {
+				return err
+			}
+		}
+		c.emit(exp.Token, code.OpArray, len(exp.Elements))
+	case *ast.MapLiteral:
+		for i, key := range exp.Keys {
+			if err := c.compileExpression(key); err != nil {
+				return err
+			}
+			if err := c.compileExpression(exp.Values[i]); err != nil {
+				return err
+			}
+		}
+		c.emit(exp.Token, code.OpMap, len(exp.Keys))
+	case *ast.IndexExpression:
+		if err := c.compileExpression(exp.Left); err != nil {
+			return err
+		}
+		if err := c.compileExpression(exp.Index); err != nil {
+			return err
+		}
+		c.emit(exp.Token, code.OpIndex)
+
+	default:
+		return newError(token.Token{}, "cannot compile %T", exp)
+	}
+	return nil
+}
+
+func (c *Compiler) compilePrefixExpression(exp *ast.PrefixExpression) error {
+	if err := c.compileExpression(exp.Right); err != nil {
+		return err
+	}
+
+	switch exp.Operator {
+	case "!":
+		c.emit(exp.Token, code.OpBang)
+	case "-":
+		c.emit(exp.Token, code.OpMinus)
+	case "++", "--":
+		op := code.OpIncrement
+		if exp.Operator == "--" {
+			op = code.OpDecrement
+		}
+		c.emit(exp.Token, op)
+
+		// Like the evaluator, ++5 just gives back 6
+		if ident, ok := exp.Right.(*ast.Identifier); ok {
+			return c.storeSymbol(ident)
+		}
+	default:
+		return newError(exp.Token, "unknown operator: %s", exp.Operator)
+	}
+	return nil
+}
+
+func (c *Compiler) compileAssignExpression(exp *ast.AssignExpression) error {
+	if evaluator.IsBuiltin(exp.Name.Value) {
+		return newError(exp.Name.Token, "cannot assign to builtin: %s", exp.Name.Value)
+	}
+
+	operator, compound := compoundOperators[exp.Operator]
+	if compound {
+		c.loadSymbol(exp.Name)
+	}
+
+	if err := c.compileExpression(exp.Value); err != nil {
+		return err
+	}
+
+	if compound {
+		c.emit(exp.Token, infixOpcodes[operator])
+	}
+	return c.storeSymbol(exp.Name)
+}
+
+func (c *Compiler) compileIfExpression(exp *ast.IfExpression) error {
+	if err := c.compileExpression(exp.Condition); err != nil {
+		return err
+	}
+
+	jumpNotTruthy := c.emit(exp.Token, code.OpJumpNotTruthy, 9999)
+	if err := c.compileBlockValue(exp.Consequence); err != nil {
+		return err
+	}
+	jump := c.emit(token.Token{}, code.OpJump, 9999)
+
+	c.changeOperand(jumpNotTruthy, len(c.currentScope().instructions))
+	if exp.Alternative == nil {
+		c.emit(token.Token{}, code.OpNil)
+	} else if err := c.compileBlockValue(exp.Alternative); err != nil {
+		return err
+	}
+	c.changeOperand(jump, len(c.currentScope().instructions))
+
+	return nil
+}
+
+// A for leaves nil on the stack when it ends, whether it was a break or the condition
+func (c *Compiler) compileForExpression(exp *ast.ForExpression) error {
+	scope := c.currentScope()
+	current := &loop{start: len(scope.instructions), tryDepth: len(scope.tries)}
+
+	if err := c.compileExpression(exp.Condition); err != nil {
+		return err
+	}
+	exit := c.emit(exp.Token, code.OpJumpNotTruthy, 9999)
+
+	scope.loops = append(scope.loops, current)
+	if exp.Body != nil {
+		if err := c.compileStatements(exp.Body.Statements); err != nil {
+			return err
+		}
+	}
+	scope.loops = scope.loops[:len(scope.loops)-1]
+	c.emit(token.Token{}, code.OpJump, current.start)
+
+	end := len(scope.instructions)
+	c.changeOperand(exit, end)
+	for _, jump := range current.breaks {
+		c.changeOperand(jump, end)
+	}
+	c.emit(token.Token{}, code.OpNil)
+
+	return nil
+}
+
+// Inside a try, returning the error must leave the try first, and that's only if it is one
+func (c *Compiler) compilePropagateExpression(exp *ast.PropagateExpression) error {
+	if err := c.compileExpression(exp.Value); err != nil {
+		return err
+	}
+	if len(c.currentScope().tries) == 0 {
+		c.emit(exp.Token, code.OpPropagate)
+		return nil
+	}
+
+	notError := c.emit(exp.Token, code.OpJumpNotError, 9999)
+	if err := c.leaveTries(0); err != nil {
+		return err
+	}
+	c.emit(exp.Token, code.OpPropagate)
+	c.changeOperand(notError, len(c.currentScope().instructions))
+	return nil
+}
+
+/*
+A try is laid out like this, where the catch starts with the error on the stack:
+
+	OpTry catch
+	body
+	OpEndTry
+	finally
+	OpJump end
+	catch:  define the name, OpTry rethrow, catch body, OpEndTry, finally, OpJump end
+	rethrow:  finally, OpThrow
+	end:
+
+The try around the catch (and the rethrow part) is only there when there is a finally
+that has to run if the catch fails too. Without a catch, a failing body goes right to
+the rethrow.
+*/
+func (c *Compiler) compileTryExpression(exp *ast.TryExpression) error {
+	scope := c.currentScope()
+
+	handler := c.emit(exp.Token, code.OpTry, 9999)
+	if err := c.compileTryBlock(exp.Body, exp.Finally); err != nil {
+		return err
+	}
+	ends := []int{c.emit(token.Token{}, code.OpJump, 9999)}
+
+	c.changeOperand(handler, len(scope.instructions))
+	if exp.Catch != nil {
+		if exp.Param != nil {
+			if evaluator.IsBuiltin(exp.Param.Value) {
+				return newError(exp.Param.Token, "cannot redeclare builtin: %s", exp.Param.Value)
+			}
+			c.define(exp.Param, false)
+		} else {
+			c.emit(token.Token{}, code.OpPop)
+		}
+
+		// With nothing to rethrow, the catch just goes on to the end
+		if exp.Finally == nil {
+			if err := c.compileBlockValue(exp.Catch); err != nil {
+				return err
+			}
+		} else {
+			handler = c.emit(token.Token{}, code.OpTry, 9999)
+			if err := c.compileTryBlock(exp.Catch, exp.Finally); err != nil {
+				return err
+			}
+			ends = append(ends, c.emit(token.Token{}, code.OpJump, 9999))
+			c.changeOperand(handler, len(scope.instructions))
+		}
+	}
+
+	if exp.Finally != nil {
+		if err := c.compileStatements(exp.Finally.Statements); err != nil {
+			return err
+		}
+		c.emit(exp.Token, code.OpThrow)
+	}
+
+	for _, jump := range ends {
+		c.changeOperand(jump, len(scope.instructions))
+	}
+	return nil
+}
+
+// compileTryBlock is the part of a try that is caught: it stops catching at the end,
+// and then runs the finally
+func (c *Compiler) compileTryBlock(block *ast.BlockStatement, finally *ast.BlockStatement) error {
+	scope := c.currentScope()
+
+	scope.tries = append(scope.tries, &tryBlock{finally: finally})
+	err := c.compileBlockValue(block)
+	scope.tries = scope.tries[:len(scope.tries)-1]
+	if err != nil {
+		return err
+	}
+
+	c.emit(token.Token{}, code.OpEndTry)
+	if finally != nil {
+		return c.compileStatements(finally.Statements)
+	}
+	return nil
+}
+
+func (c *Compiler) compileFunctionLiteral(fn *ast.FunctionLiteral) error {
+	c.enterScope()
+
+	for _, param := range fn.Parameters {
+		if evaluator.IsBuiltin(param.Value) {
+			c.leaveScope()
+			return newError(param.Token, "cannot redeclare builtin: %s", param.Value)
+		}
+		c.symbolTable.Define(param.Value, false)
+	}
+	if fn.Body != nil {
+		for _, decl := range declarations(fn.Body.Statements) {
+			c.symbolTable.Define(decl.name, decl.isConst)
+		}
+	}
+
+	if err := c.compileBlockValue(fn.Body); err != nil {
+		c.leaveScope()
+		return err
+	}
+	c.emit(token.Token{}, code.OpReturnValue)
+
+	names := c.symbolTable.Names()
+	scope := c.leaveScope()
+	if len(names) > MAX_LOCALS {
+		return newError(fn.Token, "too many local bindings: %d", len(names))
+	}
+
+	compiled := &object.CompiledFunction{
+		Instructions:  scope.instructions,
+		Lines:         scope.lines,
+		NumLocals:     len(names),
+		NumParameters: len(fn.Parameters),
+		Names:         names,
+		Name:          fn.Name,
+		Line:          fn.Token.Line,
+		Column:        fn.Token.Column,
+	}
+	c.emit(fn.Token, code.OpClosure, c.addConstant(compiled))
+	return nil
+}
+
+// op is OpCall, or OpTailCall when nothing is left to do after the call but returning
+func (c *Compiler) compileCallExpression(call *ast.CallExpression, op code.Opcode) error {
+	if err := c.compileExpression(call.Function); err != nil {
+		return err
+	}
+
+	if len(call.Arguments) > MAX_ARGUMENTS {
+		return newError(call.Token, "too many arguments: %d", len(call.Arguments))
+	}
+	for _, arg := range call.Arguments {
+		if err := c.compileExpression(arg); err != nil {
+			return err
+		}
+	}
+
+	c.emit(call.Token, op, len(call.Arguments))
+	return nil
+}
+
+/*
+A name nobody declared is taken as a global. It may be declared later (a function
+can use a binding defined after it), and if it never is, the vm complains when it
+gets there, the same way the evaluator does.
+*/
+func (c *Compiler) resolve(name string) (*Symbol, int) {
+	if symbol, depth, ok := c.symbolTable.Resolve(name); ok {
+		return symbol, depth
+	}
+	return c.symbolTable.global().Define(name, false), 0
+}
+
+// Builtins come before any binding, the same way the evaluator finds them
+func (c *Compiler) loadSymbol(ident *ast.Identifier) {
+	if index := evaluator.BuiltinIndex(ident.Value); index >= 0 {
+		c.emit(ident.Token, code.OpGetBuiltin, index)
+		return
+	}
+	symbol, depth := c.resolve(ident.Value)
+
+	switch {
+	case symbol.Scope == GlobalScope:
+		c.emit(ident.Token, code.OpGetGlobal, symbol.Index)
+	case depth == 0:
+		c.emit(ident.Token, code.OpGetLocal, symbol.Index)
+	default:
+		c.emit(ident.Token, code.OpGetOuter, depth, symbol.Index)
+	}
+}
+
+// storeSymbol assigns the value on top of the stack, and leaves it there
+func (c *Compiler) storeSymbol(ident *ast.Identifier) error {
+	if evaluator.IsBuiltin(ident.Value) {
+		return newError(ident.Token, "cannot assign to builtin: %s", ident.Value)
+	}
+	symbol, depth := c.resolve(ident.Value)
+
+	switch {
+	case symbol.Scope == GlobalScope:
+		c.emit(ident.Token, code.OpSetGlobal, symbol.Index)
+	case symbol.Const:
+		name := c.addConstant(&object.String{Value: ident.Value})
+		c.emit(ident.Token, code.OpAssignConst, name)
+	case depth == 0:
+		c.emit(ident.Token, code.OpSetLocal, symbol.Index)
+	default:
+		c.emit(ident.Token, code.OpSetOuter, depth, symbol.Index)
+	}
+	return nil
+}
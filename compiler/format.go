@@ -0,0 +1,436 @@
+package compiler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+
+	"github.com/santos-404/myte/code"
+	"github.com/santos-404/myte/evaluator"
+	"github.com/santos-404/myte/object"
+)
+
+/*
+This is the layout of a .mytec file:
+
+	"MYTEC"           magic
+	version           uint16, big endian
+	instruction set   uint32, big endian; see instructionSet
+	checksum          uint32, big endian; the CRC-32 (IEEE) of the payload
+	payload length    uint32, big endian
+	payload
+
+The payload holds the global names, the top level code with its line table and the
+constant pool. Numbers inside of it are varints, and every string and instruction
+block goes after its length. Bump the version whenever any of that changes; old
+files must be rebuilt, we don't try to read them. The opcodes don't need it, the
+instruction set takes care of them.
+*/
+const (
+	MAGIC          = "MYTEC"
+	FORMAT_VERSION = 4
+)
+
+const headerSize = len(MAGIC) + 2 + 4 + 4 + 4
+
+/*
+instructionSet sums up what the instructions mean: every opcode with its operands,
+and the builtins, since OpGetBuiltin refers to them by their index. A file built by
+a myte where any of that was different would run, but doing something else.
+*/
+var instructionSet = func() uint32 {
+	var set bytes.Buffer
+	for op := 0; op < 256; op++ {
+		def, err := code.Lookup(byte(op))
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&set, "%d %s %v\n", op, def.Name, def.OperandWidths)
+	}
+	for _, builtin := range evaluator.Builtins() {
+		fmt.Fprintf(&set, "%s\n", builtin.QualifiedName())
+	}
+	return crc32.ChecksumIEEE(set.Bytes())
+}()
+
+// The tags of the constants on the pool
+const (
+	tagInteger byte = iota + 1
+	tagFloat
+	tagString
+	tagFunction
+)
+
+var errTruncated = errors.New("corrupt bytecode file: unexpected end of data")
+
+func (b *Bytecode) Encode() ([]byte, error) {
+	var payload bytes.Buffer
+
+	writeUvarint(&payload, uint64(len(b.Globals)))
+	for _, name := range b.Globals {
+		writeString(&payload, name)
+	}
+
+	writeBytes(&payload, b.Instructions)
+	writeLines(&payload, b.Lines)
+
+	writeUvarint(&payload, uint64(len(b.Constants)))
+	for _, constant := range b.Constants {
+		if err := writeConstant(&payload, constant); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString(MAGIC)
+	binary.Write(&out, binary.BigEndian, uint16(FORMAT_VERSION))
+	binary.Write(&out, binary.BigEndian, instructionSet)
+	binary.Write(&out, binary.BigEndian, crc32.ChecksumIEEE(payload.Bytes()))
+	binary.Write(&out, binary.BigEndian, uint32(payload.Len()))
+	out.Write(payload.Bytes())
+
+	return out.Bytes(), nil
+}
+
+// Decode reads what Encode wrote. Anything that doesn't look exactly like that (a
+// file from another version, a truncated one...) is an error, never a panic.
+func Decode(data []byte) (*Bytecode, error) {
+	if len(data) < headerSize || string(data[:len(MAGIC)]) != MAGIC {
+		return nil, errors.New("not a myte bytecode file")
+	}
+
+	header := data[len(MAGIC):]
+	version := binary.BigEndian.Uint16(header)
+	if version != FORMAT_VERSION {
+		return nil, fmt.Errorf("unsupported bytecode version %d (this myte reads version %d), "+
+			"build the file again", version, FORMAT_VERSION)
+	}
+	if binary.BigEndian.Uint32(header[2:]) != instructionSet {
+		return nil, errors.New("the bytecode was built by another myte, its instructions mean something " +
+			"else here; build the file again")
+	}
+	checksum := binary.BigEndian.Uint32(header[6:])
+	length := binary.BigEndian.Uint32(header[10:])
+
+	payload := data[headerSize:]
+	if uint32(len(payload)) != length {
+		return nil, errTruncated
+	}
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return nil, errors.New("corrupt bytecode file: checksum mismatch")
+	}
+
+	r := bytes.NewReader(payload)
+	bytecode := &Bytecode{}
+
+	count, err := readCount(r)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < count; i++ {
+		name, err := readString(r)
+		if err != nil {
+			return nil, err
+		}
+		bytecode.Globals = append(bytecode.Globals, name)
+	}
+
+	if bytecode.Instructions, err = readBytes(r); err != nil {
+		return nil, err
+	}
+	if bytecode.Lines, err = readLines(r); err != nil {
+		return nil, err
+	}
+
+	if count, err = readCount(r); err != nil {
+		return nil, err
+	}
+	for i := 0; i < count; i++ {
+		constant, err := readConstant(r)
+		if err != nil {
+			return nil, err
+		}
+		bytecode.Constants = append(bytecode.Constants, constant)
+	}
+
+	if r.Len() != 0 {
+		return nil, errors.New("corrupt bytecode file: unexpected data at the end")
+	}
+	if err := bytecode.validate(); err != nil {
+		return nil, err
+	}
+	return bytecode, nil
+}
+
+func writeUvarint(w *bytes.Buffer, n uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	w.Write(buf[:binary.PutUvarint(buf[:], n)])
+}
+
+func writeBytes(w *bytes.Buffer, b []byte) {
+	writeUvarint(w, uint64(len(b)))
+	w.Write(b)
+}
+
+func writeString(w *bytes.Buffer, s string) {
+	writeBytes(w, []byte(s))
+}
+
+func writeLines(w *bytes.Buffer, lines code.LineTable) {
+	writeUvarint(w, uint64(len(lines)))
+	for _, position := range lines {
+		writeUvarint(w, uint64(position.Offset))
+		writeUvarint(w, uint64(position.Line))
+		writeUvarint(w, uint64(position.Column))
+	}
+}
+
+func writeConstant(w *bytes.Buffer, constant object.Object) error {
+	switch constant := constant.(type) {
+	case *object.Integer:
+		w.WriteByte(tagInteger)
+		var buf [binary.MaxVarintLen64]byte
+		w.Write(buf[:binary.PutVarint(buf[:], constant.Value)])
+	case *object.Float:
+		w.WriteByte(tagFloat)
+		binary.Write(w, binary.BigEndian, math.Float64bits(constant.Value))
+	case *object.String:
+		w.WriteByte(tagString)
+		writeString(w, constant.Value)
+	case *object.CompiledFunction:
+		w.WriteByte(tagFunction)
+		writeBytes(w, constant.Instructions)
+		writeLines(w, constant.Lines)
+		writeUvarint(w, uint64(constant.NumLocals))
+		writeUvarint(w, uint64(constant.NumParameters))
+		writeUvarint(w, uint64(len(constant.Names)))
+		for _, name := range constant.Names {
+			writeString(w, name)
+		}
+		writeString(w, constant.Name)
+		writeUvarint(w, uint64(constant.Line))
+		writeUvarint(w, uint64(constant.Column))
+	default:
+		return fmt.Errorf("cannot serialize a constant of type %s", constant.Type())
+	}
+	return nil
+}
+
+func readUvarint(r *bytes.Reader) (uint64, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, errTruncated
+	}
+	return n, nil
+}
+
+// A count can never be larger than what's left to read, that keeps a corrupt length
+// from making us allocate gigabytes
+func readCount(r *bytes.Reader) (int, error) {
+	n, err := readUvarint(r)
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(r.Len()) {
+		return 0, errTruncated
+	}
+	return int(n), nil
+}
+
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	n, err := readCount(r)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, errTruncated
+	}
+	return b, nil
+}
+
+func readString(r *bytes.Reader) (string, error) {
+	b, err := readBytes(r)
+	return string(b), err
+}
+
+func readLines(r *bytes.Reader) (code.LineTable, error) {
+	count, err := readCount(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines code.LineTable
+	for i := 0; i < count; i++ {
+		var fields [3]uint64
+		for j := range fields {
+			if fields[j], err = readUvarint(r); err != nil {
+				return nil, err
+			}
+		}
+		lines = append(lines, code.SourcePosition{
+			Offset: int(fields[0]),
+			Line:   int(fields[1]),
+			Column: int(fields[2]),
+		})
+	}
+	return lines, nil
+}
+
+func readConstant(r *bytes.Reader) (object.Object, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return nil, errTruncated
+	}
+
+	switch tag {
+	case tagInteger:
+		n, err := binary.ReadVarint(r)
+		if err != nil {
+			return nil, errTruncated
+		}
+		return &object.Integer{Value: n}, nil
+	case tagFloat:
+		var bits uint64
+		if err := binary.Read(r, binary.BigEndian, &bits); err != nil {
+			return nil, errTruncated
+		}
+		return &object.Float{Value: math.Float64frombits(bits)}, nil
+	case tagString:
+		s, err := readString(r)
+		if err != nil {
+			return nil, err
+		}
+		return &object.String{Value: s}, nil
+	case tagFunction:
+		return readFunction(r)
+	}
+	return nil, fmt.Errorf("corrupt bytecode file: unknown constant tag %d", tag)
+}
+
+func readFunction(r *bytes.Reader) (*object.CompiledFunction, error) {
+	fn := &object.CompiledFunction{}
+
+	var err error
+	if fn.Instructions, err = readBytes(r); err != nil {
+		return nil, err
+	}
+	if fn.Lines, err = readLines(r); err != nil {
+		return nil, err
+	}
+	if fn.NumLocals, err = readCount(r); err != nil {
+		return nil, err
+	}
+	if fn.NumParameters, err = readCount(r); err != nil {
+		return nil, err
+	}
+
+	count, err := readCount(r)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < count; i++ {
+		name, err := readString(r)
+		if err != nil {
+			return nil, err
+		}
+		fn.Names = append(fn.Names, name)
+	}
+
+	if fn.Name, err = readString(r); err != nil {
+		return nil, err
+	}
+	var position [2]uint64
+	for i := range position {
+		if position[i], err = readUvarint(r); err != nil {
+			return nil, err
+		}
+	}
+	fn.Line, fn.Column = int(position[0]), int(position[1])
+
+	if fn.NumLocals > MAX_LOCALS || len(fn.Names) != fn.NumLocals {
+		return nil, errors.New("corrupt bytecode file: bad locals on a function")
+	}
+	return fn, nil
+}
+
+/*
+The checksum catches a damaged file, but not one that was made wrong on purpose. The
+vm trusts its instructions completely, so before running them we make sure every
+operand points somewhere that exists.
+*/
+func (b *Bytecode) validate() error {
+	if err := b.validateInstructions(b.Instructions, nil); err != nil {
+		return err
+	}
+	for _, constant := range b.Constants {
+		if fn, ok := constant.(*object.CompiledFunction); ok {
+			if err := b.validateInstructions(fn.Instructions, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fn is nil for the top level code
+func (b *Bytecode) validateInstructions(ins code.Instructions, fn *object.CompiledFunction) error {
+	for ip := 0; ip < len(ins); {
+		def, err := code.Lookup(ins[ip])
+		if err != nil {
+			return fmt.Errorf("corrupt bytecode file: %s", err)
+		}
+
+		width := 0
+		for _, w := range def.OperandWidths {
+			width += w
+		}
+		if ip+1+width > len(ins) {
+			return errTruncated
+		}
+		operands, _ := code.ReadOperands(def, ins[ip+1:])
+
+		if !b.validOperands(code.Opcode(ins[ip]), operands, len(ins), fn) {
+			return fmt.Errorf("corrupt bytecode file: bad operand on %s at offset %d", def.Name, ip)
+		}
+		ip += 1 + width
+	}
+	return nil
+}
+
+func (b *Bytecode) validOperands(op code.Opcode, operands []int, size int, fn *object.CompiledFunction) bool {
+	switch op {
+	case code.OpConstant:
+		return operands[0] < len(b.Constants)
+	case code.OpClosure:
+		if operands[0] >= len(b.Constants) {
+			return false
+		}
+		_, ok := b.Constants[operands[0]].(*object.CompiledFunction)
+		return ok
+	case code.OpAssignConst, code.OpGetMember, code.OpImport:
+		if operands[0] >= len(b.Constants) {
+			return false
+		}
+		_, ok := b.Constants[operands[0]].(*object.String)
+		return ok
+	case code.OpJump, code.OpJumpNotTruthy, code.OpTry, code.OpJumpNotError:
+		return operands[0] <= size
+	case code.OpDefineGlobal, code.OpGetGlobal, code.OpSetGlobal:
+		return operands[0] < len(b.Globals)
+	case code.OpDefineLocal, code.OpGetLocal, code.OpSetLocal:
+		return fn != nil && operands[0] < fn.NumLocals
+	case code.OpGetOuter, code.OpSetOuter:
+		// How many locals the outer function has is only known when it runs
+		return fn != nil && operands[0] > 0
+	case code.OpTailCall:
+		return fn != nil
+	case code.OpGetBuiltin:
+		return operands[0] < len(evaluator.Builtins())
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package compiler
+
+import "github.com/santos-404/myte/ast"
+
+/*
+Only functions open a scope, and a function body can use a binding that is declared
+further down (a closure calling another one, for instance). So, before compiling a
+body, we find every declaration that belongs to it; blocks included, nested
+functions not.
+*/
+type declaration struct {
+	name    string
+	isConst bool
+}
+
+func declarations(statements []ast.Statement) []declaration {
+	var found []declaration
+	for _, stmt := range statements {
+		found = append(found, statementDeclarations(stmt)...)
+	}
+	return found
+}
+
+func statementDeclarations(stmt ast.Statement) []declaration {
+	switch stmt := stmt.(type) {
+	case *ast.VarStatement:
+		if stmt == nil {
+			return nil
+		}
+		return append([]declaration{{stmt.Name.Value, false}}, expressionDeclarations(stmt.Value)...)
+	case *ast.ConstStatement:
+		if stmt == nil {
+			return nil
+		}
+		return append([]declaration{{stmt.Name.Value, true}}, expressionDeclarations(stmt.Value)...)
+	case *ast.ExpressionStatement:
+		return expressionDeclarations(stmt.Expression)
+	case *ast.ReturnStatement:
+		return expressionDeclarations(stmt.ReturnValue)
+	case *ast.ThrowStatement:
+		return expressionDeclarations(stmt.Value)
+	case *ast.ImportStatement:
+		return []declaration{{stmt.Name.Value, true}}
+	case *ast.BlockStatement:
+		if stmt != nil {
+			return declarations(stmt.Statements)
+		}
+	}
+	return nil
+}
+
+func expressionDeclarations(exp ast.Expression) []declaration {
+	switch exp := exp.(type) {
+	case *ast.PrefixExpression:
+		return expressionDeclarations(exp.Right)
+	case *ast.InfixExpression:
+		return append(expressionDeclarations(exp.Left), expressionDeclarations(exp.Right)...)
+	case *ast.AssignExpression:
+		return expressionDeclarations(exp.Value)
+	case *ast.IfExpression:
+		found := expressionDeclarations(exp.Condition)
+		found = append(found, statementDeclarations(exp.Consequence)...)
+		if exp.Alternative != nil {
+			found = append(found, statementDeclarations(exp.Alternative)...)
+		}
+		return found
+	case *ast.ForExpression:
+		return append(expressionDeclarations(exp.Condition), statementDeclarations(exp.Body)...)
+	case *ast.CallExpression:
+		found := expressionDeclarations(exp.Function)
+		for _, arg := range exp.Arguments {
+			found = append(found, expressionDeclarations(arg)...)
+		}
+		return found
+	case *ast.ArrayLiteral:
+		var found []declaration
+		for _, element := range exp.Elements {
+			found = append(found, expressionDeclarations(element)...)
+		}
+		return found
+	case *ast.MapLiteral:
+		var found []declaration
+		for i, key := range exp.Keys {
+			found = append(found, expressionDeclarations(key)...)
+			found = append(found, expressionDeclarations(exp.Values[i])...)
+		}
+		return found
+	case *ast.IndexExpression:
+		return append(expressionDeclarations(exp.Left), expressionDeclarations(exp.Index)...)
+	case *ast.MemberExpression:
+		return expressionDeclarations(exp.Object)
+	case *ast.PropagateExpression:
+		return expressionDeclarations(exp.Value)
+	case *ast.TryExpression:
+		found := statementDeclarations(exp.Body)
+		if exp.Param != nil {
+			found = append(found, declaration{exp.Param.Value, false})
+		}
+		if exp.Catch != nil {
+			found = append(found, statementDeclarations(exp.Catch)...)
+		}
+		if exp.Finally != nil {
+			found = append(found, statementDeclarations(exp.Finally)...)
+		}
+		return found
+	}
+	return nil
+}
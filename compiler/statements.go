@@ -0,0 +1,125 @@
+package compiler
+
+import (
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/code"
+	"github.com/santos-404/myte/evaluator"
+	"github.com/santos-404/myte/object"
+	"github.com/santos-404/myte/token"
+)
+
+func (c *Compiler) compileStatement(stmt ast.Statement) error {
+	switch stmt := stmt.(type) {
+	case *ast.ExpressionStatement:
+		exp, ok := statementValue(stmt)
+		if !ok {
+			return nil  // Comments don't leave anything behind
+		}
+		if err := c.compileExpression(exp); err != nil {
+			return err
+		}
+		c.emit(stmt.Token, code.OpPop)
+
+	case *ast.VarStatement:
+		if stmt != nil {
+			return c.compileDeclaration(stmt.Name, stmt.Value, false)
+		}
+	case *ast.ConstStatement:
+		if stmt != nil {
+			return c.compileDeclaration(stmt.Name, stmt.Value, true)
+		}
+
+	case *ast.ReturnStatement:
+		// The top level has no frame to give away, so it calls and returns like always.
+		// Neither does a try, it must still be there if the call fails.
+		call, ok := stmt.ReturnValue.(*ast.CallExpression)
+		if ok && len(c.scopes) > 1 && len(c.currentScope().tries) == 0 {
+			return c.compileCallExpression(call, code.OpTailCall)
+		}
+		if err := c.compileExpression(stmt.ReturnValue); err != nil {
+			return err
+		}
+		if err := c.leaveTries(0); err != nil {
+			return err
+		}
+		c.emit(stmt.Token, code.OpReturnValue)
+
+	case *ast.ThrowStatement:
+		if err := c.compileExpression(stmt.Value); err != nil {
+			return err
+		}
+		c.emit(stmt.Token, code.OpThrow)
+
+	case *ast.BreakStatement:
+		loops := c.currentScope().loops
+		if len(loops) == 0 {
+			return c.compileOutsideLoop(stmt.Token, 0)
+		}
+		current := loops[len(loops)-1]
+		if err := c.leaveTries(current.tryDepth); err != nil {
+			return err
+		}
+		current.breaks = append(current.breaks, c.emit(stmt.Token, code.OpJump, 9999))
+	case *ast.ContinueStatement:
+		loops := c.currentScope().loops
+		if len(loops) == 0 {
+			return c.compileOutsideLoop(stmt.Token, 1)
+		}
+		current := loops[len(loops)-1]
+		if err := c.leaveTries(current.tryDepth); err != nil {
+			return err
+		}
+		c.emit(stmt.Token, code.OpJump, current.start)
+
+	case *ast.BlockStatement:
+		if stmt != nil {
+			return c.compileStatements(stmt.Statements)
+		}
+	case *ast.ImportStatement:
+		if evaluator.IsBuiltin(stmt.Name.Value) {
+			return newError(stmt.Name.Token, "cannot redeclare builtin: %s", stmt.Name.Value)
+		}
+		c.emit(stmt.Token, code.OpImport, c.addConstant(&object.String{Value: stmt.Path}))
+		c.define(stmt.Name, true)
+	}
+	return nil
+}
+
+/*
+A break or a continue with no loop around is only wrong once it runs, like on the
+evaluator, where it goes up to the function (running the finallys on its way) and
+fails there. So no try of the function can catch it, but the ones of its callers can.
+*/
+func (c *Compiler) compileOutsideLoop(tok token.Token, statement int) error {
+	if err := c.leaveTries(0); err != nil {
+		return err
+	}
+	c.emit(tok, code.OpOutsideLoop, statement)
+	return nil
+}
+
+func (c *Compiler) compileDeclaration(name *ast.Identifier, value ast.Expression, isConst bool) error {
+	if evaluator.IsBuiltin(name.Value) {
+		return newError(name.Token, "cannot redeclare builtin: %s", name.Value)
+	}
+	if err := c.compileExpression(value); err != nil {
+		return err
+	}
+	c.define(name, isConst)
+	return nil
+}
+
+// define takes the value on top of the stack as the value of the name
+func (c *Compiler) define(name *ast.Identifier, isConst bool) {
+	// Inside a function the name was already defined before compiling its body
+	symbol := c.symbolTable.Define(name.Value, isConst)
+	if symbol.Scope == GlobalScope {
+		flag := 0
+		if isConst {
+			flag = 1
+		}
+		c.emit(name.Token, code.OpDefineGlobal, symbol.Index, flag)
+		return
+	}
+	c.emit(name.Token, code.OpDefineLocal, symbol.Index)
+}
@@ -0,0 +1,80 @@
+package compiler
+
+type SymbolScope string
+
+const (
+	GlobalScope SymbolScope = "GLOBAL"
+	LocalScope  SymbolScope = "LOCAL"
+)
+
+type Symbol struct {
+	Name  string
+	Scope SymbolScope
+	Index int
+	Const bool  // Only known for locals; globals are checked by the vm when it runs
+}
+
+// There is one table per function, and the outermost one holds the globals
+type SymbolTable struct {
+	Outer *SymbolTable
+
+	store map[string]*Symbol
+	names []string  // By index
+}
+
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{store: make(map[string]*Symbol)}
+}
+
+func NewEnclosedSymbolTable(outer *SymbolTable) *SymbolTable {
+	s := NewSymbolTable()
+	s.Outer = outer
+	return s
+}
+
+/*
+Define gives the name a slot on this table. Declaring it again keeps the same slot,
+since the language lets you do that, but a name is only constant while every one of
+its declarations is.
+*/
+func (s *SymbolTable) Define(name string, isConst bool) *Symbol {
+	if symbol, ok := s.store[name]; ok {
+		symbol.Const = symbol.Const && isConst
+		return symbol
+	}
+
+	scope := LocalScope
+	if s.Outer == nil {
+		scope = GlobalScope
+	}
+
+	symbol := &Symbol{Name: name, Scope: scope, Index: len(s.names), Const: isConst}
+	s.store[name] = symbol
+	s.names = append(s.names, name)
+	return symbol
+}
+
+// Resolve also gives back how many functions up the name was found
+func (s *SymbolTable) Resolve(name string) (*Symbol, int, bool) {
+	depth := 0
+	for table := s; table != nil; table = table.Outer {
+		if symbol, ok := table.store[name]; ok {
+			return symbol, depth, true
+		}
+		depth++
+	}
+	return nil, 0, false
+}
+
+// Names are the names of the slots, by index. The vm needs them for its errors.
+func (s *SymbolTable) Names() []string {
+	return s.names
+}
+
+func (s *SymbolTable) global() *SymbolTable {
+	table := s
+	for table.Outer != nil {
+		table = table.Outer
+	}
+	return table
+}
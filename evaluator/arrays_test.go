@@ -0,0 +1,61 @@
+package evaluator
+
+import (
+	"testing"
+)
+
+func TestArrays(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[]", "[]"},
+		{"[1, 2.5, 'a', nil, true]", "[1, 2.5, 'a', nil, true]"},
+		{"[\"it's\", [1, 2]]", "[\"it's\", [1, 2]]"},
+		{"[1 + 1, 'a' + 'b'][1]", "ab"},
+		{"var a = [1, 2, 3]; a[0] + a[2]", "4"},
+		{"var a = [1, 2, 3]; a[-1]", "3"},
+		{"var a = [1, 2, 3]; var i = 1; a[i + 1]", "3"},
+		{"[[1, 2], [3, 4]][1][0]", "3"},
+		{"'héllo'[1]", "é"},
+		{"'abc'[-1]", "c"},
+		{"[1] + [2, 3]", "[1, 2, 3]"},
+		{"var a = [1]; var b = a + [2]; a", "[1]"},
+		{"[1, 'a'] == [1.0, 'a']", "true"},
+		{"[1, [2]] != [1, [3]]", "true"},
+		{"[] == nil", "false"},
+		{"len([1, 2, 3]) + len([])", "3"},
+		{"type([])", "array"},
+		{"const first = fn(a) { a[0] }; first(['x', 'y'])", "x"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong. expected=%q, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+func TestArrayErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2][2]", "ERROR: index out of range: 2 (length 2). Line: 0, column: 7"},
+		{"[1, 2][-3]", "ERROR: index out of range: -3 (length 2). Line: 0, column: 7"},
+		{"''[0]", "ERROR: index out of range: 0 (length 0). Line: 0, column: 3"},
+		{"[1]['0']", "ERROR: index must be int, got string. Line: 0, column: 4"},
+		{"5[0]", "ERROR: cannot index int. Line: 0, column: 2"},
+		{"[1, x]", "ERROR: identifier not found: x. Line: 0, column: 5"},
+		{"[1] + 1", "ERROR: unsupported operand types: array + int. Line: 0, column: 5"},
+		{"[1] - [1]", "ERROR: unsupported operand types: array - array. Line: 0, column: 5"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong.\nexpected=%q\ngot=     %v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
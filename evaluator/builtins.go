@@ -0,0 +1,445 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/santos-404/myte/object"
+)
+
+// ProcessIO is where the programs that were not given an IO of their own (see
+// Environment.SetIO) write, read and exit. They all share it.
+var ProcessIO = object.NewIO(os.Stdout, os.Stdin, os.Exit)
+
+func programIO(usage *object.Usage) *object.IO {
+	if usage == nil || usage.IO == nil {
+		return ProcessIO
+	}
+	return usage.IO
+}
+
+type BuiltinParameter struct {
+	Name     string
+	Types    []object.ObjectType  // Any type is fine when it's empty
+	Optional bool
+}
+
+/*
+Builtin is one of the functions every program can use without declaring it. They
+are found before any binding of the program, so their names cannot be declared or
+assigned. The signature is what the arguments are checked against before calling
+it, and it's also what the REPL, the LSP and the docs show.
+*/
+type Builtin struct {
+	Name       string
+	Parameters []BuiltinParameter
+	Variadic   bool    // The last parameter takes any number of arguments, none too
+	Returns    string
+	Doc        string
+
+	fn     func(args []object.Object) (object.Object, error)
+	ioFn   func(streams *object.IO, args []object.Object) (object.Object, error)  // Instead of fn, for the ones that use the IO
+	size   func(args []object.Object) int  // See object.Builtin
+	object *object.Builtin
+	module string  // Empty for the ones every program sees, see StdModule
+}
+
+// The vm refers to them by their index, and so do the .mytec files. New ones go at
+// the end.
+var builtins []*Builtin
+
+var builtinIndex = make(map[string]int)
+
+func init() {
+	builtins = []*Builtin{
+		{
+			Name:       "print",
+			Parameters: []BuiltinParameter{{Name: "values"}},
+			Variadic:   true,
+			Returns:    "nil",
+			Doc:        "Writes the values separated by spaces",
+			ioFn:       builtinPrint(""),
+		},
+		{
+			Name:       "println",
+			Parameters: []BuiltinParameter{{Name: "values"}},
+			Variadic:   true,
+			Returns:    "nil",
+			Doc:        "Writes the values separated by spaces, and then a new line",
+			ioFn:       builtinPrint("\n"),
+		},
+		{
+			Name:       "len",
+			Parameters: []BuiltinParameter{{
+				Name:  "value",
+				Types: []object.ObjectType{object.STRING_OBJ, object.ARRAY_OBJ, object.MAP_OBJ},
+			}},
+			Returns: "int",
+			Doc:     "Gives back the number of characters of a string, elements of an array or keys of a map",
+			fn:         builtinLen,
+		},
+		{
+			Name:       "type",
+			Parameters: []BuiltinParameter{{Name: "value"}},
+			Returns:    "string",
+			Doc:        "Gives back the name of the type of the value",
+			fn:         builtinType,
+		},
+		{
+			Name:       "str",
+			Parameters: []BuiltinParameter{{Name: "value"}},
+			Returns:    "string",
+			Doc:        "Gives back the value as it would be printed",
+			fn:         builtinStr,
+		},
+		{
+			Name: "int",
+			Parameters: []BuiltinParameter{{
+				Name:  "value",
+				Types: []object.ObjectType{object.INTEGER_OBJ, object.FLOAT_OBJ, object.STRING_OBJ, object.BOOLEAN_OBJ},
+			}},
+			Returns: "int | error",
+			Doc:     "Converts the value to an int. Floats are truncated and strings parsed, giving back an error when they are not a number",
+			fn:      builtinInt,
+		},
+		{
+			Name: "float",
+			Parameters: []BuiltinParameter{{
+				Name:  "value",
+				Types: []object.ObjectType{object.INTEGER_OBJ, object.FLOAT_OBJ, object.STRING_OBJ},
+			}},
+			Returns: "float | error",
+			Doc:     "Converts the value to a float. Strings are parsed, giving back an error when they are not a number",
+			fn:      builtinFloat,
+		},
+		{
+			Name:       "bool",
+			Parameters: []BuiltinParameter{{Name: "value"}},
+			Returns:    "bool",
+			Doc:        "Tells whether the value is truthy; only false and nil are not",
+			fn:         builtinBool,
+		},
+		{
+			Name: "input",
+			Parameters: []BuiltinParameter{
+				{Name: "prompt", Types: []object.ObjectType{object.STRING_OBJ}, Optional: true},
+			},
+			Returns: "string | nil | error",
+			Doc:     "Writes the prompt and reads a line, without its end. Gives back nil when there is nothing left to read, and an error when it cannot be read",
+			ioFn:    builtinInput,
+		},
+		{
+			Name: "exit",
+			Parameters: []BuiltinParameter{
+				{Name: "code", Types: []object.ObjectType{object.INTEGER_OBJ}, Optional: true},
+			},
+			Returns: "nil",
+			Doc:     "Ends the program right away with the code, 0 when there is none",
+			ioFn:    builtinExit,
+		},
+		{
+			Name: "assert",
+			Parameters: []BuiltinParameter{
+				{Name: "condition"},
+				{Name: "message", Types: []object.ObjectType{object.STRING_OBJ}, Optional: true},
+			},
+			Returns: "nil",
+			Doc:     "Fails with the message when the condition is not truthy",
+			fn:      builtinAssert,
+		},
+		{
+			Name:       "keys",
+			Parameters: []BuiltinParameter{{Name: "m", Types: []object.ObjectType{object.MAP_OBJ}}},
+			Returns:    "array",
+			Doc:        "Gives back the keys of a map, in the order they were added",
+			fn:         builtinKeys,
+		},
+		{
+			Name:       "error",
+			Parameters: []BuiltinParameter{{Name: "message", Types: []object.ObjectType{object.STRING_OBJ}}},
+			Returns:    "error",
+			Doc:        "Makes an error with the message, at the place of the call. It can be returned and checked like any other value",
+			fn:         builtinError,
+		},
+	}
+
+	for i, builtin := range builtins {
+		builtin.register("")
+		builtinIndex[builtin.Name] = i
+	}
+}
+
+func (b *Builtin) register(module string) {
+	b.module = module
+	b.object = &object.Builtin{Name: b.QualifiedName(), Size: b.size, IO: b.call}
+	b.object.Fn = func(args []object.Object) (object.Object, error) {
+		return b.call(ProcessIO, args)
+	}
+}
+
+// Builtins gives back every builtin, in the order the vm knows them
+func Builtins() []*Builtin {
+	return builtins
+}
+
+func LookupBuiltin(name string) (*Builtin, bool) {
+	index, ok := builtinIndex[name]
+	if !ok {
+		return nil, false
+	}
+	return builtins[index], true
+}
+
+// BuiltinIndex is -1 when there is no builtin with that name
+func BuiltinIndex(name string) int {
+	if index, ok := builtinIndex[name]; ok {
+		return index
+	}
+	return -1
+}
+
+func IsBuiltin(name string) bool {
+	_, ok := builtinIndex[name]
+	return ok
+}
+
+// Object is the value programs get when they use the name
+func (b *Builtin) Object() *object.Builtin {
+	return b.object
+}
+
+// QualifiedName is the name with the module in front, when it's on one
+func (b *Builtin) QualifiedName() string {
+	if b.module == "" {
+		return b.Name
+	}
+	return b.module + "." + b.Name
+}
+
+// Signature is written the way the functions of the language are, like len(value: string): int
+func (b *Builtin) Signature() string {
+	var params []string
+	for i, param := range b.Parameters {
+		name := param.Name
+		if b.Variadic && i == len(b.Parameters)-1 {
+			name += "..."
+		}
+		if param.Optional {
+			name += "?"
+		}
+		if len(param.Types) > 0 {
+			var types []string
+			for _, t := range param.Types {
+				types = append(types, string(t))
+			}
+			name += ": " + strings.Join(types, " | ")
+		}
+		params = append(params, name)
+	}
+	return b.QualifiedName() + "(" + strings.Join(params, ", ") + "): " + b.Returns
+}
+
+func (b *Builtin) call(streams *object.IO, args []object.Object) (object.Object, error) {
+	required := 0
+	for i, param := range b.Parameters {
+		if !param.Optional && !(b.Variadic && i == len(b.Parameters)-1) {
+			required++
+		}
+	}
+	if len(args) < required || (!b.Variadic && len(args) > len(b.Parameters)) {
+		return nil, kindErrorf(ARGUMENTS_KIND, "wrong number of arguments to %s: want=%s, got=%d",
+			b.QualifiedName(), b.arity(required), len(args))
+	}
+
+	for i, arg := range args {
+		param := b.Parameters[min(i, len(b.Parameters)-1)]
+		if !param.accepts(arg) {
+			return nil, kindErrorf(TYPE_KIND, "argument %d of %s must be %s, got %s",
+				i+1, b.QualifiedName(), param.typeList(), typeOf(arg))
+		}
+	}
+
+	var result object.Object
+	var err error
+	if b.ioFn != nil {
+		result, err = b.ioFn(streams, args)
+	} else {
+		result, err = b.fn(args)
+	}
+	if result == nil && err == nil {
+		result = NIL
+	}
+	return result, err
+}
+
+func (b *Builtin) arity(required int) string {
+	switch {
+	case b.Variadic:
+		return fmt.Sprintf("at least %d", required)
+	case required == len(b.Parameters):
+		return strconv.Itoa(required)
+	case required+1 == len(b.Parameters):
+		return fmt.Sprintf("%d or %d", required, len(b.Parameters))
+	}
+	return fmt.Sprintf("%d to %d", required, len(b.Parameters))
+}
+
+func (p BuiltinParameter) accepts(arg object.Object) bool {
+	if len(p.Types) == 0 {
+		return true
+	}
+	for _, t := range p.Types {
+		if typeOf(arg) == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Like "int, float or string"
+func (p BuiltinParameter) typeList() string {
+	var types []string
+	for _, t := range p.Types {
+		types = append(types, string(t))
+	}
+	if len(types) == 1 {
+		return types[0]
+	}
+	return strings.Join(types[:len(types)-1], ", ") + " or " + types[len(types)-1]
+}
+
+
+func builtinPrint(end string) func(streams *object.IO, args []object.Object) (object.Object, error) {
+	return func(streams *object.IO, args []object.Object) (object.Object, error) {
+		var values []string
+		for _, arg := range args {
+			values = append(values, arg.Inspect())
+		}
+		io.WriteString(streams.Stdout, strings.Join(values, " ")+end)
+		return NIL, nil
+	}
+}
+
+func builtinLen(args []object.Object) (object.Object, error) {
+	switch arg := args[0].(type) {
+	case *object.Array:
+		return &object.Integer{Value: int64(len(arg.Elements))}, nil
+	case *object.Map:
+		return &object.Integer{Value: int64(len(arg.Keys))}, nil
+	}
+	s := args[0].(*object.String)
+	return &object.Integer{Value: int64(utf8.RuneCountInString(s.Value))}, nil
+}
+
+func builtinType(args []object.Object) (object.Object, error) {
+	return &object.String{Value: string(typeOf(args[0]))}, nil
+}
+
+func builtinStr(args []object.Object) (object.Object, error) {
+	if s, ok := args[0].(*object.String); ok {
+		return s, nil
+	}
+	return &object.String{Value: args[0].Inspect()}, nil
+}
+
+func builtinInt(args []object.Object) (object.Object, error) {
+	switch arg := args[0].(type) {
+	case *object.Integer:
+		return arg, nil
+	case *object.Float:
+		// Like a string that is not a number, it's a value the program can deal with
+		if math.IsNaN(arg.Value) || math.IsInf(arg.Value, 0) ||
+			arg.Value >= math.MaxInt64 || arg.Value < math.MinInt64 {
+			return nil, recoverableError("cannot convert %s to int", arg.Inspect())
+		}
+		return &object.Integer{Value: int64(arg.Value)}, nil
+	case *object.String:
+		value, err := strconv.ParseInt(strings.TrimSpace(arg.Value), 10, 64)
+		if err != nil {
+			return nil, recoverableError("cannot convert %q to int", arg.Value)
+		}
+		return &object.Integer{Value: value}, nil
+	case *object.Boolean:
+		if arg.Value {
+			return &object.Integer{Value: 1}, nil
+		}
+		return &object.Integer{Value: 0}, nil
+	}
+	return nil, nil
+}
+
+func builtinFloat(args []object.Object) (object.Object, error) {
+	switch arg := args[0].(type) {
+	case *object.Integer:
+		return &object.Float{Value: float64(arg.Value)}, nil
+	case *object.Float:
+		return arg, nil
+	case *object.String:
+		value, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64)
+		if err != nil {
+			return nil, recoverableError("cannot convert %q to float", arg.Value)
+		}
+		return &object.Float{Value: value}, nil
+	}
+	return nil, nil
+}
+
+func builtinBool(args []object.Object) (object.Object, error) {
+	return nativeBoolToBooleanObject(isTruthy(args[0])), nil
+}
+
+func builtinInput(streams *object.IO, args []object.Object) (object.Object, error) {
+	if len(args) == 1 {
+		io.WriteString(streams.Stdout, args[0].(*object.String).Value)
+	}
+
+	line, err := streams.Stdin.ReadString('\n')
+	if err != nil && line == "" {
+		if err == io.EOF {
+			return NIL, nil
+		}
+		return nil, recoverableError("cannot read the input: %s", err)
+	}
+	line = strings.TrimSuffix(line, "\n")
+	return &object.String{Value: strings.TrimSuffix(line, "\r")}, nil
+}
+
+// Whoever runs the program may not want it to end the process (or may not end it
+// right away), so the program stops either way
+func builtinExit(streams *object.IO, args []object.Object) (object.Object, error) {
+	code := 0
+	if len(args) == 1 {
+		code = int(args[0].(*object.Integer).Value)
+	}
+	if streams.Exit != nil {
+		streams.Exit(code)
+	}
+	return nil, kindErrorf(EXIT_KIND, "exit: %d", code)
+}
+
+func builtinAssert(args []object.Object) (object.Object, error) {
+	if isTruthy(args[0]) {
+		return NIL, nil
+	}
+	if len(args) == 2 {
+		return nil, kindErrorf(ASSERTION_KIND, "assertion failed: %s", args[1].(*object.String).Value)
+	}
+	return nil, kindErrorf(ASSERTION_KIND, "assertion failed")
+}
+
+func builtinKeys(args []object.Object) (object.Object, error) {
+	keys := args[0].(*object.Map).Keys
+	return &object.Array{Elements: append([]object.Object{}, keys...)}, nil
+}
+
+// The error goes back the same way the ones of a failing builtin do, so it gets the
+// position of the call
+func builtinError(args []object.Object) (object.Object, error) {
+	return nil, recoverable{errors.New(stringArg(args, 0))}
+}
@@ -0,0 +1,142 @@
+package evaluator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/santos-404/myte/lexer"
+	"github.com/santos-404/myte/object"
+	"github.com/santos-404/myte/parser"
+)
+
+func TestBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"len('hello')", "5"},
+		{"len('')", "0"},
+		{"len('héllo')", "5"},
+		{"type(1)", "int"},
+		{"type(1.5)", "float"},
+		{"type('a')", "string"},
+		{"type(nil)", "nil"},
+		{"type(fn() {})", "fn"},
+		{"type(len)", "fn"},
+		{"str(12) + str(1.0) + str(true) + str(nil)", "121.0truenil"},
+		{"int(3.9)", "3"},
+		{"int(-3.9)", "-3"},
+		{"int(' 42 ')", "42"},
+		{"int(true) + int(false)", "1"},
+		{"float(2)", "2.0"},
+		{"float('2.5')", "2.5"},
+		{"bool(0)", "true"},
+		{"bool(nil)", "false"},
+		{"assert(1 == 1)", "nil"},
+		{"len", "builtin len"},
+		// They are values like any other function
+		{"const apply = fn(f, x) { f(x) }; apply(len, 'abc')", "3"},
+		{"const f = fn(x) { return str(x); }; f(7) + '!'", "7!"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong. expected=%q, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+func TestBuiltinErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"len()", "ERROR: wrong number of arguments to len: want=1, got=0. Line: 0, column: 4"},
+		{"len('a', 'b')", "ERROR: wrong number of arguments to len: want=1, got=2. Line: 0, column: 4"},
+		{"input('a', 'b')", "ERROR: wrong number of arguments to input: want=0 or 1, got=2. Line: 0, column: 6"},
+		{"assert()", "ERROR: wrong number of arguments to assert: want=1 or 2, got=0. Line: 0, column: 7"},
+		{"len(5)", "ERROR: argument 1 of len must be string, array or map, got int. Line: 0, column: 4"},
+		{"int(nil)", "ERROR: argument 1 of int must be int, float, string or bool, got nil. Line: 0, column: 4"},
+		{"assert(true, 1)", "ERROR: argument 2 of assert must be string, got int. Line: 0, column: 7"},
+		{"int(1.0 / 0)", "ERROR: division by zero. Line: 0, column: 9"},
+		{"assert(1 > 2)", "ERROR: assertion failed. Line: 0, column: 7"},
+		{"\n  assert(false, 'the sky fell')", "ERROR: assertion failed: the sky fell. Line: 1, column: 9"},
+		{"const f = fn() { return len(1); }; f()", "ERROR: argument 1 of len must be string, array or map, got int. Line: 0, column: 28"},
+		// Builtins are found before any binding, so their names are taken
+		{"var len = 1;", "ERROR: cannot redeclare builtin: len. Line: 0, column: 5"},
+		{"const print = 1;", "ERROR: cannot redeclare builtin: print. Line: 0, column: 7"},
+		{"fn(str) { str }", "ERROR: cannot redeclare builtin: str. Line: 0, column: 4"},
+		{"type = 1", "ERROR: cannot assign to builtin: type. Line: 0, column: 1"},
+		{"int += 1", "ERROR: cannot assign to builtin: int. Line: 0, column: 1"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong.\nexpected=%q\ngot=     %v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+func TestBuiltinInputAndOutput(t *testing.T) {
+	var out bytes.Buffer
+	code := -1
+	env := object.NewEnvironment()
+	env.SetIO(object.NewIO(&out, strings.NewReader("Ada\r\n41\nlast"), func(c int) { code = c }))
+
+	input := `
+		print('name?', '');
+		var name = input();
+		println('hi', name, 1, 2.0);
+		var n = int(input('n? ')) + 1;
+		println(n);
+		println(input(), input());
+		try { exit(3) } finally { println('never') }
+		println('never either');
+	`
+	program := parser.New(lexer.New(input)).ParseProgram()
+	result := Eval(program, env)
+
+	// Once the exit hook is done, the program stops and no try gets in the way
+	err, ok := result.(*object.Error)
+	if !ok || err.Kind != EXIT_KIND || err.Message != "exit: 3" {
+		t.Fatalf("expected the program to stop with exit. got=%v", result)
+	}
+
+	expected := "name? hi Ada 1 2.0\nn? 42\nlast nil\n"
+	if out.String() != expected {
+		t.Errorf("output wrong. expected=%q, got=%q", expected, out.String())
+	}
+	if code != 3 {
+		t.Errorf("exit code wrong. expected=3, got=%d", code)
+	}
+}
+
+func TestBuiltinSignatures(t *testing.T) {
+	tests := map[string]string{
+		"print":  "print(values...): nil",
+		"len":    "len(value: string | array | map): int",
+		"int":    "int(value: int | float | string | bool): int | error",
+		"input":  "input(prompt?: string): string | nil | error",
+		"assert": "assert(condition, message?: string): nil",
+		"error":  "error(message: string): error",
+	}
+
+	for name, expected := range tests {
+		builtin, ok := LookupBuiltin(name)
+		if !ok {
+			t.Fatalf("there is no builtin called %s", name)
+		}
+		if builtin.Signature() != expected {
+			t.Errorf("signature of %s wrong. expected=%q, got=%q", name, expected, builtin.Signature())
+		}
+	}
+
+	for i, builtin := range Builtins() {
+		if BuiltinIndex(builtin.Name) != i || builtin.Doc == "" {
+			t.Errorf("%s is not registered right", builtin.Name)
+		}
+	}
+}
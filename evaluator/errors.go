@@ -0,0 +1,171 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/object"
+	"github.com/santos-404/myte/token"
+)
+
+/*
+The kinds of errors, which is what a catch gets to tell them apart. ERROR_KIND is
+for the ones the program makes itself, with error() or throw, and whatever doesn't
+fit any other kind is a RUNTIME_KIND one. The limits have kinds of their own (see
+limits.go).
+*/
+const (
+	ERROR_KIND          = "error"
+	RUNTIME_KIND        = "runtime"
+	ZERO_DIVISION_KIND  = "zero_division"
+	INDEX_KIND          = "index"
+	NAME_KIND           = "name"
+	TYPE_KIND           = "type"
+	MEMBER_KIND         = "member"
+	ARGUMENTS_KIND      = "arguments"
+	ASSIGNMENT_KIND     = "assignment"
+	ASSERTION_KIND      = "assertion"
+	STACK_OVERFLOW_KIND = "stack_overflow"
+)
+
+// The token is the one that points to the place where the error happened
+func newError(tok token.Token, format string, a ...interface{}) *object.Error {
+	return newKindError(tok, RUNTIME_KIND, format, a...)
+}
+
+func newKindError(tok token.Token, kind string, format string, a ...interface{}) *object.Error {
+	return &object.Error{
+		Message: fmt.Sprintf(format, a...),
+		Kind:    kind,
+		Line:    tok.Line,
+		Column:  tok.Column,
+	}
+}
+
+// Builtins are found before any binding, so one with the same name could never be used
+func checkDeclarable(name *ast.Identifier) *object.Error {
+	if IsBuiltin(name.Value) {
+		return newKindError(name.Token, ASSIGNMENT_KIND, "cannot redeclare builtin: %s", name.Value)
+	}
+	return nil
+}
+
+// A break or a continue that got out of every loop (and function) without being caught
+func loopControlError(obj object.Object) *object.Error {
+	switch obj := obj.(type) {
+	case *object.Break:
+		return outsideLoopError(token.Token{Line: obj.Line, Column: obj.Column}, "break")
+	case *object.Continue:
+		return outsideLoopError(token.Token{Line: obj.Line, Column: obj.Column}, "continue")
+	}
+	return nil
+}
+
+func outsideLoopError(tok token.Token, statement string) *object.Error {
+	return newKindError(tok, RUNTIME_KIND, "%s outside of a loop", statement)
+}
+
+/*
+recoverable is how a builtin tells that it failed in a way the program may want to
+deal with, like a file that is not there. Instead of stopping the program, the call
+gives back an error value with the message. Anything else a builtin fails with (the
+wrong arguments, mostly) is a bug of the program and still stops it.
+*/
+type recoverable struct {
+	err error
+}
+
+func (r recoverable) Error() string { return r.err.Error() }
+
+func recoverableError(format string, a ...interface{}) error {
+	return recoverable{fmt.Errorf(format, a...)}
+}
+
+// kindError is a builtin failing with a kind other than the runtime one, like the
+// wrong arguments do
+type kindError struct {
+	kind string
+	err  error
+}
+
+func (k kindError) Error() string { return k.err.Error() }
+
+func kindErrorf(kind string, format string, a ...interface{}) error {
+	return kindError{kind, fmt.Errorf(format, a...)}
+}
+
+// The error value takes the position of the call, that's where it was made
+func callBuiltin(usage *object.Usage, tok token.Token, builtin *object.Builtin, args []object.Object) object.Object {
+	var result object.Object
+	var err error
+	if builtin.IO != nil {
+		result, err = builtin.IO(programIO(usage), args)
+	} else {
+		result, err = builtin.Fn(args)
+	}
+	if r, ok := err.(recoverable); ok {
+		return &object.ErrorValue{Message: r.Error(), Kind: ERROR_KIND, Line: tok.Line, Column: tok.Column}
+	}
+	if k, ok := err.(kindError); ok {
+		return newKindError(tok, k.kind, "%s", k.err)
+	}
+	if err != nil {
+		return newError(tok, "%s", err)
+	}
+	return result
+}
+
+// What ends the program when ? finds an error outside of any function
+func uncaughtError(err *object.ErrorValue) *object.Error {
+	return &object.Error{Message: err.Message, Kind: err.Kind, Line: err.Line, Column: err.Column}
+}
+
+// caughtError is what the catch gets, the same error as a value
+func caughtError(err *object.Error) *object.ErrorValue {
+	return &object.ErrorValue{
+		Message: err.Message,
+		Kind:    err.Kind,
+		Line:    err.Line,
+		Column:  err.Column,
+		Trace:   err.Trace,
+	}
+}
+
+// traced takes note of the call the error is going out of
+func traced(err *object.Error, fn *object.Function, call token.Token) *object.Error {
+	err.Trace = append(err.Trace, object.TraceFrame{
+		Function: fn.Name,
+		FnLine:   fn.Line,
+		FnColumn: fn.Column,
+		Line:     call.Line,
+		Column:   call.Column,
+	})
+	return err
+}
+
+// The trace as the program sees it, one map per call
+func traceArray(trace []object.TraceFrame) *object.Array {
+	frames := &object.Array{Elements: []object.Object{}}
+	for _, frame := range trace {
+		m := object.NewMap()
+		m.Set(&object.String{Value: "function"}, &object.String{Value: frame.Name()})
+		m.Set(&object.String{Value: "line"}, &object.Integer{Value: int64(frame.Line)})
+		m.Set(&object.String{Value: "column"}, &object.Integer{Value: int64(frame.Column)})
+		frames.Elements = append(frames.Elements, m)
+	}
+	return frames
+}
+
+// An error keeps where it was made, so throwing a caught one again doesn't move it.
+// A string is a shortcut for an error made right there.
+func throwValue(tok token.Token, value object.Object) *object.Error {
+	switch value := value.(type) {
+	case *object.ErrorValue:
+		// Whatever is added to the trace from here on is not part of the one the value has
+		trace := value.Trace[:len(value.Trace):len(value.Trace)]
+		return &object.Error{Message: value.Message, Kind: value.Kind, Line: value.Line, Column: value.Column, Trace: trace}
+	case *object.String:
+		return &object.Error{Message: value.Value, Kind: ERROR_KIND, Line: tok.Line, Column: tok.Column}
+	}
+	return newKindError(tok, TYPE_KIND, "throw takes an error or a string, got %s", typeOf(value))
+}
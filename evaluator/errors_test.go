@@ -0,0 +1,90 @@
+package evaluator
+
+import (
+	"testing"
+)
+
+func TestErrorValues(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"error('bad')", "error('bad')"},
+		{"error(\"it's bad\")", "error(\"it's bad\")"},
+		{"type(error('bad'))", "error"},
+		{"error('bad').message", "bad"},
+		{"var e = error('bad'); [e.line, e.column]", "[0, 14]"},
+		{"\n  error('bad').line", "1"},
+		{"[error('a')]", "[error('a')]"},
+		{"bool(error('bad'))", "true"},
+		{"var e = error('bad'); e == e", "true"},
+		{"str(error('bad'))", "error('bad')"},
+		{"int('12')", "12"},
+		{"int('twelve')", "error('cannot convert \"twelve\" to int')"},
+		{"float('x').message", "cannot convert \"x\" to float"},
+		{"type(int('x')) == 'error'", "true"},
+		{"int(2.0 ** 64)", "error('cannot convert 1.8446744073709552e+19 to int')"},
+		{"[int(2.0 ** 64).kind, int('x').kind]", "['error', 'error']"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong. expected=%q, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+func TestPropagation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"const f = fn() { var n = int('7')?; n * 2 }; f()", "14"},
+		{"const f = fn() { var n = int('x')?; n * 2 }; f()", "error('cannot convert \"x\" to int')"},
+		{"const f = fn() { error('early')?; 'late' }; f()", "error('early')"},
+		{"const f = fn() { int('x')? + 1 }; f().message", "cannot convert \"x\" to int"},
+		{"const f = fn(s) { [int(s)?] }; [f('1'), f('b')]", "[[1], error('cannot convert \"b\" to int')]"},
+		{`const f = fn() { for true { error('in a loop')?; } }; f()`, "error('in a loop')"},
+		{`const f = fn() { if true { error('in an if')? } 1 }; f()`, "error('in an if')"},
+		// Only the closest function returns, whoever called it goes on
+		{`
+			const parse = fn(s) { int(s)? };
+			const total = fn(a, b) { parse(a)? + parse(b)? };
+			[total('1', '2'), total('1', 'x').message]
+		`, "[3, 'cannot convert \"x\" to int']"},
+		{"const f = fn() { nil? }; f()", "nil"},
+		// Returning an error is fine, it's a value like any other
+		{"return error('given back')", "error('given back')"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong. expected=%q, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+func TestErrorValueFailures(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// There is no function to return the error from, so the program stops with it
+		{"int('x')?", "ERROR: cannot convert \"x\" to int. Line: 0, column: 4"},
+		{"\nvar e = error('stop');\ne?; 'not here'", "ERROR: stop. Line: 1, column: 14"},
+		{"if true { error('inside')? }", "ERROR: inside. Line: 0, column: 16"},
+		{"error('bad').code", "ERROR: error has no member: code. Line: 0, column: 14"},
+		{"error(1)", "ERROR: argument 1 of error must be string, got int. Line: 0, column: 6"},
+		{"error()", "ERROR: wrong number of arguments to error: want=1, got=0. Line: 0, column: 6"},
+		{"var error = 1;", "ERROR: cannot redeclare builtin: error. Line: 0, column: 5"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong.\nexpected=%q\ngot=     %v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package evaluator
+
+import (
+	"strings"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/object"
+)
+
+// There is no need to create new objects for these, every true is the same true
+var (
+	NIL   = &object.Nil{}
+	TRUE  = &object.Boolean{Value: true}
+	FALSE = &object.Boolean{Value: false}
+)
+
+/*
+Eval walks the tree. It may return a Go nil for the nodes that don't produce any
+value at all (declarations or comments), so callers need to check that before
+using the result.
+*/
+func Eval(node ast.Node, env *object.Environment) object.Object {
+	// Every Eval inside of another one takes more of the Go stack, see MAX_NESTING
+	usage := env.Usage()
+	usage.Nesting++
+	result := eval(node, env)
+	usage.Nesting--
+	return result
+}
+
+func eval(node ast.Node, env *object.Environment) object.Object {
+	switch node := node.(type) {
+
+	// Statements
+	case *ast.Program:
+		return evalProgram(node, env)
+	case *ast.ExpressionStatement:
+		return Eval(node.Expression, env)
+	case *ast.VarStatement:
+		return evalVarStatement(node, env)
+	case *ast.ConstStatement:
+		return evalConstStatement(node, env)
+	case *ast.ReturnStatement:
+		return evalReturnStatement(node, env)
+	case *ast.BlockStatement:
+		return evalBlockStatement(node, env)
+	case *ast.BreakStatement:
+		return &object.Break{Line: node.Token.Line, Column: node.Token.Column}
+	case *ast.ContinueStatement:
+		return &object.Continue{Line: node.Token.Line, Column: node.Token.Column}
+	case *ast.ImportStatement:
+		return evalImportStatement(node, env)
+	case *ast.ThrowStatement:
+		return evalThrowStatement(node, env)
+
+	// Expressions
+	case *ast.IntegerLiteral:
+		return &object.Integer{Value: node.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
+	case *ast.StringLiteral:
+		return &object.String{Value: decodeString(node.Value)}
+	case *ast.BooleanLiteral:
+		return nativeBoolToBooleanObject(node.Value)
+	case *ast.NilLiteral:
+		return NIL
+	case *ast.Identifier:
+		return evalIdentifier(node, env)
+	case *ast.PrefixExpression:
+		return evalPrefixExpression(node, env)
+	case *ast.InfixExpression:
+		return evalInfixExpression(node, env)
+	case *ast.AssignExpression:
+		return evalAssignExpression(node, env)
+	case *ast.IfExpression:
+		return evalIfExpression(node, env)
+	case *ast.ForExpression:
+		return evalForExpression(node, env)
+	case *ast.TryExpression:
+		return evalTryExpression(node, env)
+	case *ast.FunctionLiteral:
+		return evalFunctionLiteral(node, env)
+	case *ast.CallExpression:
+		return evalCallExpression(node, env)
+	case *ast.MemberExpression:
+		return evalMemberExpression(node, env)
+	case *ast.ArrayLiteral:
+		return evalArrayLiteral(node, env)
+	case *ast.MapLiteral:
+		return evalMapLiteral(node, env)
+	case *ast.IndexExpression:
+		return evalIndexExpression(node, env)
+	case *ast.PropagateExpression:
+		return evalPropagateExpression(node, env)
+	case *ast.CommentExpression:
+		return nil
+	}
+
+	return nil
+}
+
+// A return in the middle of an expression (the one ? makes, for instance) must go up
+// the same way an error does, nobody can use it as a value. So must a break or a
+// continue, like the one in `[1, if done { break }]`.
+func isError(obj object.Object) bool {
+	return isInterruption(obj)
+}
+
+// Only false and nil are falsy. Anything else (even 0 or "") is truthy.
+func isTruthy(obj object.Object) bool {
+	switch obj {
+	case NIL, FALSE, nil:
+		return false
+	default:
+		return true
+	}
+}
+
+// These are the results that must stop a block from running the rest of its statements
+func isInterruption(obj object.Object) bool {
+	if obj == nil {
+		return false
+	}
+	switch obj.Type() {
+	case object.RETURN_VALUE_OBJ, object.RUNTIME_ERROR_OBJ, object.BREAK_OBJ, object.CONTINUE_OBJ:
+		return true
+	}
+	return false
+}
+
+func nativeBoolToBooleanObject(value bool) *object.Boolean {
+	if value {
+		return TRUE
+	}
+	return FALSE
+}
+
+// The escapes a string can have. Any other backslash is kept as it is.
+var escapes = map[byte]byte{'n': '\n', 't': '\t', '\\': '\\', '"': '"', '\'': '\''}
+
+// The lexer keeps the quotes and the escapes on string literals, so we get rid of them here
+func decodeString(raw string) string {
+	if len(raw) >= 2 && (raw[0] == '"' || raw[0] == '\'') && raw[len(raw)-1] == raw[0] {
+		raw = raw[1 : len(raw)-1]
+	}
+	if !strings.Contains(raw, "\\") {
+		return raw
+	}
+
+	var decoded strings.Builder
+	for i := 0; i < len(raw); i++ {
+		if raw[i] == '\\' && i+1 < len(raw) {
+			if char, ok := escapes[raw[i+1]]; ok {
+				decoded.WriteByte(char)
+				i++
+				continue
+			}
+		}
+		decoded.WriteByte(raw[i])
+	}
+	return decoded.String()
+}
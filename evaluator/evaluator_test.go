@@ -0,0 +1,187 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/santos-404/myte/lexer"
+	"github.com/santos-404/myte/object"
+	"github.com/santos-404/myte/parser"
+)
+
+func testEval(t *testing.T, input string) object.Object {
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has %d errors for %q: %v", len(p.Errors()), input, p.Errors())
+	}
+
+	return Eval(program, object.NewEnvironment())
+}
+
+func TestEvalIntegerExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"5", 5},
+		{"-5", -5},
+		{"5 + 5 * 2", 15},
+		{"(5 + 5) * 2", 20},
+		{"2 ** 10", 1024},
+		{"7 // 2", 3},
+		{"-7 // 2", -4},
+		{"-7 % 2", 1},
+		{"7 % -2", -1},
+		{"++5", 6},
+		{"--5", 4},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"5.5", 5.5},
+		{"7 / 2", 3.5},
+		{"1 + 0.5", 1.5},
+		{"-7.0 // 2", -4},
+		{"2 ** -1", 0.5},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		result, ok := evaluated.(*object.Float)
+		if !ok {
+			t.Errorf("%q - object is not Float. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if result.Value != tt.expected {
+			t.Errorf("%q - object has wrong value. got=%g, want=%g", tt.input, result.Value, tt.expected)
+		}
+	}
+}
+
+func TestEvalBooleanExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"true", true},
+		{"!true", false},
+		{"!nil", true},
+		{"!0", false},
+		{"1 < 2", true},
+		{"1 >= 2", false},
+		{"1 == 1.0", true},
+		{"'a' == \"a\"", true},
+		{"'a' < 'b'", true},
+		{"nil == nil", true},
+		{"1 == true", false},
+		{"1 != 'a'", true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		result, ok := evaluated.(*object.Boolean)
+		if !ok {
+			t.Errorf("%q - object is not Boolean. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if result.Value != tt.expected {
+			t.Errorf("%q - object has wrong value. got=%t, want=%t", tt.input, result.Value, tt.expected)
+		}
+	}
+}
+
+func TestEvalStringExpression(t *testing.T) {
+	evaluated := testEval(t, "'foo' + \"bar\"")
+	str, ok := evaluated.(*object.String)
+	if !ok {
+		t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+	}
+	if str.Value != "foobar" {
+		t.Errorf("String has wrong value. got=%q", str.Value)
+	}
+}
+
+func TestBindingsAndFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"var a = 5; a;", 5},
+		{"const a = 5; var b = a * 2; b;", 10},
+		{"var x = 1; ++x; x;", 2},
+		{"var add = fn(a, b) { return a + b; }; add(2, 3);", 5},
+		{"var add = fn(a, b) { a + b }; add(2, add(1, 1));", 4},
+		{"var adder = fn(x) { fn(y) { x + y } }; var addTwo = adder(2); addTwo(3);", 5},
+		{"var i = 0; for i < 10 { ++i; }; i;", 10},
+		{"var f = fn() { var i = 0; for true { ++i; if i == 3 { return i; } } }; f();", 3},
+		{"if 1 > 2 { 10 } else { 20 }", 20},
+		{"var x = 1; x = x + 4; x;", 5},
+		{"var x: int = 2; const sq = fn(n: int): int { n * n }; sq(x);", 4},
+		{"var x = 10; x += 2; x -= 1; x *= 2; x;", 22},
+		{"var a = 0; var b = 0; a = b = 7; a + b;", 14},
+		{"var f = fn() { x = 3 }; var x = 1; f(); x;", 3},
+		{"var i = 0; for true { ++i; if i == 5 { break; } }; i;", 5},
+		{"var i = 0; var odd = 0; for i < 10 { ++i; if i % 2 == 0 { continue; } ++odd; }; odd;", 5},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestErrorHandling(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"foobar", "identifier not found: foobar"},
+		{"5 + true", "unsupported operand types: int + bool"},
+		{"'a' - 'b'", "unknown operator: string - string"},
+		{"-true", "unknown operator: -bool"},
+		{"1 / 0", "division by zero"},
+		{"1 % 0", "division by zero"},
+		{"const x = 1; ++x;", "cannot assign to constant: x"},
+		{"var f = fn(a) { a }; f(1, 2);", "wrong number of arguments: want=1, got=2"},
+		{"var x = 1; x();", "not a function: int"},
+		{"y = 1;", "identifier not found: y"},
+		{"const c = 1; c = 2;", "cannot assign to constant: c"},
+		{"const c = 1; c += 2;", "cannot assign to constant: c"},
+		{"break;", "break outside of a loop"},
+		{"var f = fn() { continue; }; for true { f(); }", "continue outside of a loop"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%q - no error object returned. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("%q - wrong error message. expected=%q, got=%q",
+				tt.input, tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
+	result, ok := obj.(*object.Integer)
+	if !ok {
+		t.Errorf("object is not Integer. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%d, want=%d", result.Value, expected)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,731 @@
+package evaluator
+
+import (
+	"math"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/object"
+	"github.com/santos-404/myte/token"
+)
+
+func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
+	if builtin, ok := LookupBuiltin(node.Value); ok {
+		return builtin.Object()
+	}
+	if val, ok := env.Get(node.Value); ok {
+		return val
+	}
+	return newKindError(node.Token, NAME_KIND, "identifier not found: %s", node.Value)
+}
+
+func evalPrefixExpression(node *ast.PrefixExpression, env *object.Environment) object.Object {
+	right := Eval(node.Right, env)
+	if isError(right) {
+		return right
+	}
+
+	switch node.Operator {
+	case "++", "--":
+		return evalIncrementExpression(node, right, env)
+	}
+	return evalPrefixOperation(node.Token, node.Operator, right)
+}
+
+func evalPrefixOperation(tok token.Token, operator string, right object.Object) object.Object {
+	switch operator {
+	case "!":
+		return nativeBoolToBooleanObject(!isTruthy(right))
+	case "-":
+		switch right := right.(type) {
+		case *object.Integer:
+			return &object.Integer{Value: -right.Value}
+		case *object.Float:
+			return &object.Float{Value: -right.Value}
+		}
+	}
+
+	return newKindError(tok, TYPE_KIND, "unknown operator: %s%s", operator, typeOf(right))
+}
+
+// ++x and --x update the binding when there is one. On anything else (like ++5)
+// they just give back the new value.
+func evalIncrementExpression(
+	node *ast.PrefixExpression, 
+	right object.Object, 
+	env *object.Environment,
+) object.Object {
+	result := evalIncrementOperation(node.Token, node.Operator, right)
+	if isError(result) {
+		return result
+	}
+
+	if ident, ok := node.Right.(*ast.Identifier); ok {
+		if IsBuiltin(ident.Value) {
+			return newKindError(ident.Token, ASSIGNMENT_KIND, "cannot assign to builtin: %s", ident.Value)
+		}
+		if _, exists, assignable := env.Assign(ident.Value, result); exists && !assignable {
+			return newKindError(ident.Token, ASSIGNMENT_KIND, "cannot assign to constant: %s", ident.Value)
+		}
+	}
+	return result
+}
+
+func evalIncrementOperation(tok token.Token, operator string, right object.Object) object.Object {
+	delta := int64(1)
+	if operator == "--" {
+		delta = -1
+	}
+
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: right.Value + delta}
+	case *object.Float:
+		return &object.Float{Value: right.Value + float64(delta)}
+	}
+	return newKindError(tok, TYPE_KIND, "unknown operator: %s%s", operator, typeOf(right))
+}
+
+var compoundOperators = map[string]string{"+=": "+", "-=": "-", "*=": "*", "/=": "/"}
+
+func evalAssignExpression(node *ast.AssignExpression, env *object.Environment) object.Object {
+	if IsBuiltin(node.Name.Value) {
+		return newKindError(node.Name.Token, ASSIGNMENT_KIND, "cannot assign to builtin: %s", node.Name.Value)
+	}
+
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	if operator, ok := compoundOperators[node.Operator]; ok {
+		current, ok := env.Get(node.Name.Value)
+		if !ok {
+			return newKindError(node.Name.Token, NAME_KIND, "identifier not found: %s", node.Name.Value)
+		}
+		val = infixOperationWithin(env.Usage(), node.Token, operator, current, val)
+		if isError(val) {
+			return val
+		}
+	}
+
+	_, exists, assignable := env.Assign(node.Name.Value, val)
+	if !exists {
+		return newKindError(node.Name.Token, NAME_KIND, "identifier not found: %s", node.Name.Value)
+	}
+	if !assignable {
+		return newKindError(node.Name.Token, ASSIGNMENT_KIND, "cannot assign to constant: %s", node.Name.Value)
+	}
+	return val
+}
+
+func evalInfixExpression(node *ast.InfixExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	right := Eval(node.Right, env)
+	if isError(right) {
+		return right
+	}
+
+	return infixOperationWithin(env.Usage(), node.Token, node.Operator, left, right)
+}
+
+func evalInfixOperation(
+	tok token.Token,
+	operator string,
+	left, right object.Object,
+) object.Object {
+	switch {
+	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
+		return evalIntegerInfixExpression(tok, operator, left.(*object.Integer).Value,
+			right.(*object.Integer).Value)
+	case isNumber(left) && isNumber(right):
+		return evalFloatInfixExpression(tok, operator, toFloat(left), toFloat(right))
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return evalStringInfixExpression(tok, operator, left.(*object.String).Value,
+			right.(*object.String).Value)
+	case left.Type() == object.ARRAY_OBJ && right.Type() == object.ARRAY_OBJ && operator == "+":
+		elements := append([]object.Object{}, left.(*object.Array).Elements...)
+		return &object.Array{Elements: append(elements, right.(*object.Array).Elements...)}
+	case left.Type() == object.MAP_OBJ && right.Type() == object.MAP_OBJ && operator == "+":
+		return mergeMaps(left.(*object.Map), right.(*object.Map))
+	case operator == "==":
+		return nativeBoolToBooleanObject(objectsEqual(left, right))
+	case operator == "!=":
+		return nativeBoolToBooleanObject(!objectsEqual(left, right))
+	}
+
+	return newKindError(tok, TYPE_KIND, "unsupported operand types: %s %s %s",
+		typeOf(left), operator, typeOf(right))
+}
+
+func evalIntegerInfixExpression(
+	tok token.Token,
+	operator string,
+	left, right int64,
+) object.Object {
+	switch operator {
+	case "+":
+		return &object.Integer{Value: left + right}
+	case "-":
+		return &object.Integer{Value: left - right}
+	case "*":
+		return &object.Integer{Value: left * right}
+	case "/":  // The true division always gives back a float
+		if right == 0 {
+			return newKindError(tok, ZERO_DIVISION_KIND, "division by zero")
+		}
+		return &object.Float{Value: float64(left) / float64(right)}
+	case "//":
+		if right == 0 {
+			return newKindError(tok, ZERO_DIVISION_KIND, "division by zero")
+		}
+		return &object.Integer{Value: floorDiv(left, right)}
+	case "%":
+		if right == 0 {
+			return newKindError(tok, ZERO_DIVISION_KIND, "division by zero")
+		}
+		return &object.Integer{Value: left - right*floorDiv(left, right)}
+	case "**":
+		if power, ok := intPow(left, right); ok {
+			return &object.Integer{Value: power}
+		}
+		// A negative power, or one too big for an int
+		return &object.Float{Value: math.Pow(float64(left), float64(right))}
+	case "<":
+		return nativeBoolToBooleanObject(left < right)
+	case "<=":
+		return nativeBoolToBooleanObject(left <= right)
+	case ">":
+		return nativeBoolToBooleanObject(left > right)
+	case ">=":
+		return nativeBoolToBooleanObject(left >= right)
+	case "==":
+		return nativeBoolToBooleanObject(left == right)
+	case "!=":
+		return nativeBoolToBooleanObject(left != right)
+	}
+
+	return newKindError(tok, TYPE_KIND, "unknown operator: int %s int", operator)
+}
+
+func evalFloatInfixExpression(
+	tok token.Token,
+	operator string,
+	left, right float64,
+) object.Object {
+	switch operator {
+	case "+":
+		return &object.Float{Value: left + right}
+	case "-":
+		return &object.Float{Value: left - right}
+	case "*":
+		return &object.Float{Value: left * right}
+	case "/":
+		if right == 0 {
+			return newKindError(tok, ZERO_DIVISION_KIND, "division by zero")
+		}
+		return &object.Float{Value: left / right}
+	case "//":
+		if right == 0 {
+			return newKindError(tok, ZERO_DIVISION_KIND, "division by zero")
+		}
+		return &object.Float{Value: math.Floor(left / right)}
+	case "%":
+		if right == 0 {
+			return newKindError(tok, ZERO_DIVISION_KIND, "division by zero")
+		}
+		return &object.Float{Value: left - right*math.Floor(left/right)}
+	case "**":
+		return &object.Float{Value: math.Pow(left, right)}
+	case "<":
+		return nativeBoolToBooleanObject(left < right)
+	case "<=":
+		return nativeBoolToBooleanObject(left <= right)
+	case ">":
+		return nativeBoolToBooleanObject(left > right)
+	case ">=":
+		return nativeBoolToBooleanObject(left >= right)
+	case "==":
+		return nativeBoolToBooleanObject(left == right)
+	case "!=":
+		return nativeBoolToBooleanObject(left != right)
+	}
+
+	return newKindError(tok, TYPE_KIND, "unknown operator: float %s float", operator)
+}
+
+func evalStringInfixExpression(
+	tok token.Token,
+	operator string,
+	left, right string,
+) object.Object {
+	switch operator {
+	case "+":
+		return &object.String{Value: left + right}
+	case "<":
+		return nativeBoolToBooleanObject(left < right)
+	case "<=":
+		return nativeBoolToBooleanObject(left <= right)
+	case ">":
+		return nativeBoolToBooleanObject(left > right)
+	case ">=":
+		return nativeBoolToBooleanObject(left >= right)
+	case "==":
+		return nativeBoolToBooleanObject(left == right)
+	case "!=":
+		return nativeBoolToBooleanObject(left != right)
+	}
+
+	return newKindError(tok, TYPE_KIND, "unknown operator: string %s string", operator)
+}
+
+func evalArrayLiteral(node *ast.ArrayLiteral, env *object.Environment) object.Object {
+	elements := evalExpressions(node.Elements, env)
+	if len(elements) == 1 && isError(elements[0]) {
+		return elements[0]
+	}
+	return &object.Array{Elements: elements}
+}
+
+func evalMapLiteral(node *ast.MapLiteral, env *object.Environment) object.Object {
+	var pairs []object.Object
+	for i, key := range node.Keys {
+		evaluated := evalExpressions([]ast.Expression{key, node.Values[i]}, env)
+		if len(evaluated) == 1 && isError(evaluated[0]) {
+			return evaluated[0]
+		}
+		pairs = append(pairs, evaluated...)
+	}
+	return evalMapOperation(node.Token, pairs)
+}
+
+// The pairs go one after the other: key, value, key, value...
+func evalMapOperation(tok token.Token, pairs []object.Object) object.Object {
+	m := object.NewMap()
+	for i := 0; i < len(pairs); i += 2 {
+		if !m.Set(pairs[i], pairs[i+1]) {
+			return newKindError(tok, INDEX_KIND, "unusable as map key: %s", typeOf(pairs[i]))
+		}
+	}
+	return m
+}
+
+// The keys of the right one win, but the ones both have keep the place they had on the left
+func mergeMaps(left, right *object.Map) *object.Map {
+	merged := object.NewMap()
+	for _, m := range []*object.Map{left, right} {
+		for _, key := range m.Keys {
+			value, _ := m.Get(key)
+			merged.Set(key, value)
+		}
+	}
+	return merged
+}
+
+func evalIndexExpression(node *ast.IndexExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	index := Eval(node.Index, env)
+	if isError(index) {
+		return index
+	}
+
+	return evalIndexOperation(node.Token, left, index)
+}
+
+// Negative indexes count from the end, so a[-1] is the last one. Strings are indexed
+// by character, the same way len counts them. A key that a map doesn't have gives nil.
+func evalIndexOperation(tok token.Token, left, index object.Object) object.Object {
+	if m, ok := left.(*object.Map); ok {
+		if _, ok := object.KeyOf(index); !ok {
+			return newKindError(tok, INDEX_KIND, "unusable as map key: %s", typeOf(index))
+		}
+		if value, ok := m.Get(index); ok {
+			return value
+		}
+		return NIL
+	}
+
+	i, ok := index.(*object.Integer)
+	if !ok && (left.Type() == object.ARRAY_OBJ || left.Type() == object.STRING_OBJ) {
+		return newKindError(tok, INDEX_KIND, "index must be int, got %s", typeOf(index))
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		position, ok := indexPosition(i.Value, len(left.Elements))
+		if !ok {
+			return newKindError(tok, INDEX_KIND, "index out of range: %d (length %d)", i.Value, len(left.Elements))
+		}
+		return left.Elements[position]
+	case *object.String:
+		chars := []rune(left.Value)
+		position, ok := indexPosition(i.Value, len(chars))
+		if !ok {
+			return newKindError(tok, INDEX_KIND, "index out of range: %d (length %d)", i.Value, len(chars))
+		}
+		return &object.String{Value: string(chars[position])}
+	}
+	return newKindError(tok, INDEX_KIND, "cannot index %s", typeOf(left))
+}
+
+func indexPosition(index int64, length int) (int, bool) {
+	if index < 0 {
+		index += int64(length)
+	}
+	if index < 0 || index >= int64(length) {
+		return 0, false
+	}
+	return int(index), true
+}
+
+// Modules have their own members, see evalMemberExpression. The rest only has a few
+// fixed ones.
+func evalMemberOperation(tok token.Token, obj object.Object, member string) object.Object {
+	if module, ok := obj.(*object.Module); ok {
+		value, ok := module.Env.GetOwn(member)
+		if !ok {
+			return newKindError(tok, MEMBER_KIND, "module %s has no member: %s", module.Name, member)
+		}
+		return value
+	}
+
+	err, ok := obj.(*object.ErrorValue)
+	if !ok {
+		return newKindError(tok, TYPE_KIND, "cannot get member %s of %s", member, typeOf(obj))
+	}
+
+	switch member {
+	case "message":
+		return &object.String{Value: err.Message}
+	case "kind":
+		return &object.String{Value: err.Kind}
+	case "line":
+		return &object.Integer{Value: int64(err.Line)}
+	case "column":
+		return &object.Integer{Value: int64(err.Column)}
+	case "trace":
+		return traceArray(err.Trace)
+	}
+	return newKindError(tok, MEMBER_KIND, "error has no member: %s", member)
+}
+
+// Inside of a function the error is returned; on the top level there is nobody to
+// return it to, so evalProgram stops the program with it
+func evalPropagateExpression(node *ast.PropagateExpression, env *object.Environment) object.Object {
+	value := Eval(node.Value, env)
+	if isError(value) {
+		return value
+	}
+	if err, ok := value.(*object.ErrorValue); ok {
+		return &object.ReturnValue{Value: err, Propagated: true}
+	}
+	if value == nil {
+		return NIL
+	}
+	return value
+}
+
+func evalIfExpression(node *ast.IfExpression, env *object.Environment) object.Object {
+	condition := Eval(node.Condition, env)
+	if isError(condition) {
+		return condition
+	}
+
+	if isTruthy(condition) {
+		return evalBlockStatement(node.Consequence, env)
+	} else if node.Alternative != nil {
+		return evalBlockStatement(node.Alternative, env)
+	}
+	return NIL
+}
+
+/*
+When the body fails, the catch gets the error as a value and the try is worth what
+the catch is. The finally runs whatever happened, even when the body or the catch
+returned, broke out of a loop or failed; only a finally that does one of those
+itself takes the place of what was going on.
+*/
+func evalTryExpression(node *ast.TryExpression, env *object.Environment) object.Object {
+	if node.Param != nil {
+		if err := checkDeclarable(node.Param); err != nil {
+			return err
+		}
+	}
+
+	result := evalTryBlock(node.Body, env)
+	if isLimitError(result) {
+		return result
+	}
+	if err, ok := result.(*object.Error); ok && node.Catch != nil {
+		if node.Param != nil {
+			env.Set(node.Param.Value, caughtError(err))
+		}
+		result = evalTryBlock(node.Catch, env)
+		if isLimitError(result) {
+			return result
+		}
+	}
+
+	if node.Finally != nil {
+		if finally := evalBlockStatement(node.Finally, env); isInterruption(finally) {
+			return finally
+		}
+	}
+	if result == nil {
+		return NIL
+	}
+	return result
+}
+
+// A `return g(x)` cannot leave g(x) for the caller to make, like it does elsewhere:
+// the try must be there when it fails
+func evalTryBlock(block *ast.BlockStatement, env *object.Environment) object.Object {
+	result := evalBlockStatement(block, env)
+	if returned, ok := result.(*object.ReturnValue); ok {
+		if call, ok := returned.Value.(*tailCall); ok {
+			value := applyFunction(call.node, call.function, call.args, env.Usage())
+			if isError(value) {
+				return value
+			}
+			return &object.ReturnValue{Value: value}
+		}
+	}
+	return result
+}
+
+func evalForExpression(node *ast.ForExpression, env *object.Environment) object.Object {
+	for {
+		if err := step(env.Usage(), node.Token); err != nil {
+			return err
+		}
+
+		condition := Eval(node.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			return NIL
+		}
+
+		result := evalBlockStatement(node.Body, env)
+		switch result.(type) {
+		case *object.Break:
+			return NIL
+		case *object.ReturnValue, *object.Error:
+			return result
+		}
+	}
+}
+
+// The parameters are bindings too, so they cannot hide a builtin
+func evalFunctionLiteral(node *ast.FunctionLiteral, env *object.Environment) object.Object {
+	for _, param := range node.Parameters {
+		if err := checkDeclarable(param); err != nil {
+			return err
+		}
+	}
+	return &object.Function{
+		Parameters: node.Parameters,
+		Body:       node.Body,
+		Env:        env,
+		Name:       node.Name,
+		Line:       node.Token.Line,
+		Column:     node.Token.Column,
+	}
+}
+
+func evalCallExpression(node *ast.CallExpression, env *object.Environment) object.Object {
+	function := Eval(node.Function, env)
+	if isError(function) {
+		return function
+	}
+
+	args := evalExpressions(node.Arguments, env)
+	if len(args) == 1 && isError(args[0]) {
+		return args[0]
+	}
+
+	return applyFunction(node, function, args, env.Usage())
+}
+
+func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
+	var result []object.Object
+
+	for _, exp := range exps {
+		evaluated := Eval(exp, env)
+		if isError(evaluated) {
+			return []object.Object{evaluated}
+		}
+		result = append(result, evaluated)
+	}
+
+	return result
+}
+
+func applyFunction(node *ast.CallExpression, fn object.Object, args []object.Object, usage *object.Usage) object.Object {
+	call := node.Token  // A tail call takes our place, so an error in it was called from here too
+	entered := false
+	for {
+		if err := step(usage, node.Token); err != nil {
+			return err
+		}
+		if builtin, ok := fn.(*object.Builtin); ok {
+			return callBuiltinWithin(usage, node.Token, builtin, args)
+		}
+
+		function, ok := fn.(*object.Function)
+		if !ok {
+			return newKindError(node.Token, TYPE_KIND, "not a function: %s", typeOf(fn))
+		}
+
+		if len(args) != len(function.Parameters) {
+			return newKindError(node.Token, ARGUMENTS_KIND, "wrong number of arguments: want=%d, got=%d",
+				len(function.Parameters), len(args))
+		}
+
+		// Tail calls don't go any deeper, they take our place
+		if !entered {
+			if err := enterCall(usage, node.Token); err != nil {
+				return err
+			}
+			defer leaveCall(usage)
+			entered = true
+		}
+
+		env := object.NewEnclosedEnvironment(function.Env)
+		for i, param := range function.Parameters {
+			env.Set(param.Value, args[i])
+		}
+
+		evaluated := evalBlockStatement(function.Body, env)
+		switch result := evaluated.(type) {
+		case *object.ReturnValue:
+			// The function ended with `return g(x)`, so g(x) is made here instead
+			if call, ok := result.Value.(*tailCall); ok {
+				node, fn, args = call.node, call.function, call.args
+				continue
+			}
+			return result.Value
+		case *object.Break, *object.Continue:
+			return traced(loopControlError(result), function, call)
+		case *object.Error:
+			return traced(result, function, call)
+		case nil:
+			return NIL
+		}
+		return evaluated
+	}
+}
+
+
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.Float:
+		return obj.Value
+	}
+	return 0
+}
+
+// Numbers are compared by value, so 1 == 1.0. Everything else must match its type.
+func objectsEqual(left, right object.Object) bool {
+	if isNumber(left) && isNumber(right) {
+		return toFloat(left) == toFloat(right)
+	}
+	if left.Type() != right.Type() {
+		return false
+	}
+
+	switch left := left.(type) {
+	case *object.String:
+		return left.Value == right.(*object.String).Value
+	case *object.Boolean:
+		return left.Value == right.(*object.Boolean).Value
+	case *object.Nil:
+		return true
+	case *object.Array:
+		other := right.(*object.Array)
+		if len(left.Elements) != len(other.Elements) {
+			return false
+		}
+		for i, element := range left.Elements {
+			if !objectsEqual(element, other.Elements[i]) {
+				return false
+			}
+		}
+		return true
+	case *object.Map:
+		other := right.(*object.Map)
+		if len(left.Pairs) != len(other.Pairs) {
+			return false
+		}
+		for key, value := range left.Pairs {
+			otherValue, ok := other.Pairs[key]
+			if !ok || !objectsEqual(value, otherValue) {
+				return false
+			}
+		}
+		return true
+	}
+	return left == right
+}
+
+// This is the Python way of dividing: it rounds towards -infinite, not towards 0
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// intPow is false when the power is not an int, because it's negative or it doesn't fit
+func intPow(base, exp int64) (int64, bool) {
+	if exp < 0 {
+		return 0, false
+	}
+
+	result := int64(1)
+	ok := true
+	for exp > 0 && ok {
+		if exp&1 == 1 {
+			result, ok = multiply(result, base)
+		}
+		exp >>= 1
+		if exp > 0 && ok {
+			base, ok = multiply(base, base)
+		}
+	}
+	return result, ok
+}
+
+// multiply is false when a * b doesn't fit in an int
+func multiply(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if c/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return c, true
+}
+
+func typeOf(obj object.Object) object.ObjectType {
+	if obj == nil {
+		return object.NIL_OBJ
+	}
+	return obj.Type()
+}
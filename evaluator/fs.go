@@ -0,0 +1,224 @@
+package evaluator
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/santos-404/myte/object"
+)
+
+/*
+The fs module. Relative paths start where myte was run from, not where the file is:
+a build script is run from the folder it works on. When something goes wrong (the
+file is not there, a folder is not empty...) the call gives back an error value
+telling what could not be done and why, so the program can deal with it.
+*/
+func init() {
+	registerStdModule(&StdModule{
+		Name: "fs",
+		Doc:  "Files and folders: reading, writing, listing, and paths",
+		Functions: []*Builtin{
+			{
+				Name:       "read_file",
+				Parameters: []BuiltinParameter{stringParameter("path")},
+				Returns:    "string | error",
+				Doc:        "Everything the file has",
+				fn:         fsReadFile,
+			},
+			{
+				Name:       "write_file",
+				Parameters: []BuiltinParameter{stringParameter("path"), stringParameter("content")},
+				Returns:    "nil | error",
+				Doc:        "Writes the content to the file, which is made when it's not there and emptied when it is",
+				fn:         fsWriteFile(os.O_TRUNC, "write"),
+			},
+			{
+				Name:       "append_file",
+				Parameters: []BuiltinParameter{stringParameter("path"), stringParameter("content")},
+				Returns:    "nil | error",
+				Doc:        "Writes the content at the end of the file, which is made when it's not there",
+				fn:         fsWriteFile(os.O_APPEND, "append to"),
+			},
+			{
+				Name:       "exists",
+				Parameters: []BuiltinParameter{stringParameter("path")},
+				Returns:    "bool",
+				Doc:        "Whether there is a file or a folder at the path",
+				fn:         fsExists,
+			},
+			{
+				Name:       "list_dir",
+				Parameters: []BuiltinParameter{stringParameter("path")},
+				Returns:    "array | error",
+				Doc:        "The names of what the folder has, sorted",
+				fn:         fsListDir,
+			},
+			{
+				Name:       "mkdir",
+				Parameters: []BuiltinParameter{stringParameter("path")},
+				Returns:    "nil | error",
+				Doc:        "Makes the folder and the ones above it that are missing. It's fine when it's already there",
+				fn:         fsMkdir,
+			},
+			{
+				Name: "remove",
+				Parameters: []BuiltinParameter{
+					stringParameter("path"),
+					{Name: "recursive", Types: []object.ObjectType{object.BOOLEAN_OBJ}, Optional: true},
+				},
+				Returns: "nil | error",
+				Doc:     "Removes the file or the empty folder. With recursive, a folder goes with everything it has",
+				fn:      fsRemove,
+			},
+			{
+				Name:       "stat",
+				Parameters: []BuiltinParameter{stringParameter("path")},
+				Returns:    "map | error",
+				Doc:        "What there is to know about the file: name, size (in bytes), is_dir, modified (in seconds since 1970) and mode",
+				fn:         fsStat,
+			},
+			{
+				Name:       "join",
+				Parameters: []BuiltinParameter{stringParameter("path"), stringParameter("paths")},
+				Variadic:   true,
+				Returns:    "string",
+				Doc:        "The paths put together with the separator of the system, and cleaned",
+				fn:         fsJoin,
+			},
+			{
+				Name:       "base",
+				Parameters: []BuiltinParameter{stringParameter("path")},
+				Returns:    "string",
+				Doc:        "The last part of the path, like file.txt on a/b/file.txt",
+				fn:         pathFunction(filepath.Base),
+			},
+			{
+				Name:       "dir",
+				Parameters: []BuiltinParameter{stringParameter("path")},
+				Returns:    "string",
+				Doc:        "Everything but the last part of the path, like a/b on a/b/file.txt",
+				fn:         pathFunction(filepath.Dir),
+			},
+			{
+				Name:       "ext",
+				Parameters: []BuiltinParameter{stringParameter("path")},
+				Returns:    "string",
+				Doc:        "The extension of the path with its dot, like .txt on a/b/file.txt. Empty when it has none",
+				fn:         pathFunction(filepath.Ext),
+			},
+		},
+	})
+}
+
+// Go says "open a.txt: no such file or directory"; the path is already on our
+// message, so only the reason is kept.
+func fsError(action, path string, err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	return recoverableError("cannot %s %s: %s", action, path, err)
+}
+
+func pathFunction(f func(path string) string) func(args []object.Object) (object.Object, error) {
+	return func(args []object.Object) (object.Object, error) {
+		return &object.String{Value: f(stringArg(args, 0))}, nil
+	}
+}
+
+func fsReadFile(args []object.Object) (object.Object, error) {
+	path := stringArg(args, 0)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fsError("read", path, err)
+	}
+	return &object.String{Value: string(content)}, nil
+}
+
+func fsWriteFile(mode int, action string) func(args []object.Object) (object.Object, error) {
+	return func(args []object.Object) (object.Object, error) {
+		path := stringArg(args, 0)
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|mode, 0644)
+		if err != nil {
+			return nil, fsError(action, path, err)
+		}
+		_, err = file.WriteString(stringArg(args, 1))
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, fsError(action, path, err)
+		}
+		return NIL, nil
+	}
+}
+
+func fsExists(args []object.Object) (object.Object, error) {
+	_, err := os.Stat(stringArg(args, 0))
+	return nativeBoolToBooleanObject(err == nil), nil
+}
+
+func fsListDir(args []object.Object) (object.Object, error) {
+	path := stringArg(args, 0)
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fsError("list", path, err)
+	}
+
+	names := make([]object.Object, len(entries))
+	for i, entry := range entries {
+		names[i] = &object.String{Value: entry.Name()}
+	}
+	return &object.Array{Elements: names}, nil
+}
+
+func fsMkdir(args []object.Object) (object.Object, error) {
+	path := stringArg(args, 0)
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return nil, fsError("make", path, err)
+	}
+	return NIL, nil
+}
+
+func fsRemove(args []object.Object) (object.Object, error) {
+	path := stringArg(args, 0)
+
+	// RemoveAll is happy when there is nothing to remove, we want the same error Remove gives
+	if _, err := os.Lstat(path); err != nil {
+		return nil, fsError("remove", path, err)
+	}
+	remove := os.Remove
+	if len(args) == 2 && args[1] == TRUE {
+		remove = os.RemoveAll
+	}
+	if err := remove(path); err != nil {
+		return nil, fsError("remove", path, err)
+	}
+	return NIL, nil
+}
+
+func fsStat(args []object.Object) (object.Object, error) {
+	path := stringArg(args, 0)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fsError("stat", path, err)
+	}
+
+	stat := object.NewMap()
+	stat.Set(&object.String{Value: "name"}, &object.String{Value: info.Name()})
+	stat.Set(&object.String{Value: "size"}, &object.Integer{Value: info.Size()})
+	stat.Set(&object.String{Value: "is_dir"}, nativeBoolToBooleanObject(info.IsDir()))
+	stat.Set(&object.String{Value: "modified"}, &object.Integer{Value: info.ModTime().Unix()})
+	stat.Set(&object.String{Value: "mode"}, &object.String{Value: info.Mode().String()})
+	return stat, nil
+}
+
+func fsJoin(args []object.Object) (object.Object, error) {
+	paths := make([]string, len(args))
+	for i := range args {
+		paths[i] = stringArg(args, i)
+	}
+	return &object.String{Value: filepath.Join(paths...)}, nil
+}
@@ -0,0 +1,76 @@
+package evaluator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFsModule(t *testing.T) {
+	t.Chdir(t.TempDir())
+	// Chmod too, so the mode doesn't depend on the umask
+	if err := os.WriteFile("hello.txt", []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod("hello.txt", 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fs.read_file('hello.txt')", "hello"},
+		{"fs.write_file('new.txt', 'a'); fs.append_file('new.txt', 'b'); fs.read_file('new.txt')", "ab"},
+		{"fs.write_file('new.txt', 'c'); fs.read_file('new.txt')", "c"},
+		{"fs.append_file('log.txt', 'x'); fs.read_file('log.txt')", "x"},
+		{"[fs.exists('hello.txt'), fs.exists('nope.txt'), fs.exists('.')]", "[true, false, true]"},
+		{"fs.mkdir('a/b/c'); fs.mkdir('a/b'); fs.exists('a/b/c')", "true"},
+		{"fs.write_file('a/z.txt', ''); fs.list_dir('a')", "['b', 'z.txt']"},
+		{"fs.remove('a/z.txt'); fs.remove('a/b/c'); fs.list_dir('a')", "['b']"},
+		{"fs.remove('a', true); fs.exists('a')", "false"},
+		{"var s = fs.stat('hello.txt'); [s['name'], s['size'], s['is_dir'], s['mode']]",
+			"['hello.txt', 5, false, '-rw-r--r--']"},
+		{"[fs.stat('.')['is_dir'], fs.stat('.')['modified'] > 0]", "[true, true]"},
+		{"fs.join('a', 'b/', '../c', 'd.txt')", filepath.Join("a", "c", "d.txt")},
+		{"[fs.base('a/b/file.txt'), fs.dir('a/b/file.txt'), fs.ext('a/b/file.txt'), fs.ext('a/b')]",
+			"['file.txt', 'a/b', '.txt', '']"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, "import \"fs\"; "+tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong. expected=%q, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+func TestFsModuleErrors(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll("full/inside", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fs.read_file('nope.txt')", "error('cannot read nope.txt: no such file or directory')"},
+		{"fs.read_file('full')", "error('cannot read full: is a directory')"},
+		{"fs.write_file('nope/a.txt', '')", "error('cannot write nope/a.txt: no such file or directory')"},
+		{"fs.list_dir('nope')", "error('cannot list nope: no such file or directory')"},
+		{"fs.remove('nope')", "error('cannot remove nope: no such file or directory')"},
+		{"fs.remove('full')", "error('cannot remove full: directory not empty')"},
+		{"fs.stat('nope')", "error('cannot stat nope: no such file or directory')"},
+		{"var e = fs.stat('nope'); [e.line, e.column]", "[0, 29]"},
+		{"fs.join()", "ERROR: wrong number of arguments to fs.join: want=at least 1, got=0. Line: 0, column: 21"},
+		{"fs.remove('full', 1)", "ERROR: argument 2 of fs.remove must be bool, got int. Line: 0, column: 23"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, "import \"fs\"; "+tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong.\nexpected=%q\ngot=     %v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package evaluator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/santos-404/myte/object"
+)
+
+/*
+The json module. Objects become maps (keeping the order of their keys), arrays
+become arrays, and a number is an int when it's written like one (1, -20) and a
+float otherwise (1.0, 2e3). Going the other way, floats always keep their dot, so
+whatever parse gives back is stringified to the same text and parsed to the same
+value again.
+*/
+func init() {
+	registerStdModule(&StdModule{
+		Name: "json",
+		Doc:  "Reading and writing JSON",
+		Functions: []*Builtin{
+			{
+				Name:       "parse",
+				Parameters: []BuiltinParameter{stringParameter("text")},
+				Returns:    "any",
+				Doc:        "The value the JSON text has. Objects are maps, arrays are arrays and null is nil. Gives back an error when the text is not valid JSON",
+				fn:         jsonParse,
+			},
+			{
+				Name: "stringify",
+				Parameters: []BuiltinParameter{
+					{Name: "value"},
+					{Name: "indent", Types: []object.ObjectType{object.INTEGER_OBJ, object.STRING_OBJ}, Optional: true},
+				},
+				Returns: "string",
+				Doc:     "The value as JSON, all in one line, or one element per line with indent (a number of spaces, or the whitespace to use)",
+				fn:      jsonStringify,
+			},
+		},
+	})
+}
+
+// Nothing sensible is nested that deep, and it keeps us far from the limits of the Go
+// stack. The Go decoder has the same limit when parsing.
+const MAX_JSON_DEPTH = 10000
+
+func jsonParse(args []object.Object) (object.Object, error) {
+	decoder := json.NewDecoder(strings.NewReader(stringArg(args, 0)))
+	decoder.UseNumber()
+
+	value, err := parseJSONValue(decoder)
+	if err == nil {
+		if _, err = decoder.Token(); err == io.EOF {
+			return value, nil
+		} else if err == nil {
+			err = fmt.Errorf("there is more after the value")
+		}
+	}
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF  // The text was empty
+	}
+	return nil, recoverableError("invalid JSON at offset %d: %s", decoder.InputOffset(), err)
+}
+
+func parseJSONValue(decoder *json.Decoder) (object.Object, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token := token.(type) {
+	case json.Delim:
+		if token == '[' {
+			return parseJSONArray(decoder)
+		}
+		return parseJSONObject(decoder)
+	case string:
+		return &object.String{Value: token}, nil
+	case json.Number:
+		return parseJSONNumber(token)
+	case bool:
+		return nativeBoolToBooleanObject(token), nil
+	}
+	return NIL, nil
+}
+
+func parseJSONArray(decoder *json.Decoder) (object.Object, error) {
+	elements := []object.Object{}
+	for decoder.More() {
+		element, err := parseJSONValue(decoder)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+	}
+
+	if _, err := decoder.Token(); err != nil {  // The ']'
+		return nil, err
+	}
+	return &object.Array{Elements: elements}, nil
+}
+
+// When a key is there twice, the last value wins
+func parseJSONObject(decoder *json.Decoder) (object.Object, error) {
+	m := object.NewMap()
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		value, err := parseJSONValue(decoder)
+		if err != nil {
+			return nil, err
+		}
+		m.Set(&object.String{Value: key.(string)}, value)
+	}
+
+	if _, err := decoder.Token(); err != nil {  // The '}'
+		return nil, err
+	}
+	return m, nil
+}
+
+// An int too big for us is still a number, so it becomes a float
+func parseJSONNumber(number json.Number) (object.Object, error) {
+	if !strings.ContainsAny(string(number), ".eE") {
+		if value, err := strconv.ParseInt(string(number), 10, 64); err == nil {
+			return &object.Integer{Value: value}, nil
+		}
+	}
+	value, err := strconv.ParseFloat(string(number), 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return nil, err
+	}
+	return &object.Float{Value: value}, nil
+}
+
+// The widest indent stringify takes, past it it's surely a mistake
+const MAX_JSON_INDENT = 10
+
+func jsonStringify(args []object.Object) (object.Object, error) {
+	encoder := &jsonEncoder{visiting: make(map[object.Object]bool)}
+	if len(args) == 2 {
+		switch indent := args[1].(type) {
+		case *object.Integer:
+			if indent.Value < 0 || indent.Value > MAX_JSON_INDENT {
+				return nil, recoverableError("the indent of json.stringify must be between 0 and %d spaces, got %d",
+					MAX_JSON_INDENT, indent.Value)
+			}
+			encoder.indent = strings.Repeat(" ", int(indent.Value))
+		case *object.String:
+			// Anything else would not be JSON anymore
+			if len(indent.Value) > MAX_JSON_INDENT || strings.Trim(indent.Value, " \t\n\r") != "" {
+				return nil, recoverableError("the indent of json.stringify must be up to %d spaces, tabs or newlines, got %q",
+					MAX_JSON_INDENT, indent.Value)
+			}
+			encoder.indent = indent.Value
+		}
+	}
+
+	if err := encoder.encode(args[0], 0); err != nil {
+		return nil, err
+	}
+	return &object.String{Value: encoder.out.String()}, nil
+}
+
+type jsonEncoder struct {
+	out    bytes.Buffer
+	indent string  // Empty when it all goes in one line
+
+	// The arrays and maps we are inside of. Finding one of them again means the value
+	// contains itself, and it would never end.
+	visiting map[object.Object]bool
+}
+
+func (e *jsonEncoder) encode(value object.Object, depth int) error {
+	switch value := value.(type) {
+	case *object.Integer:
+		e.out.WriteString(value.Inspect())
+	case *object.Float:
+		if math.IsNaN(value.Value) || math.IsInf(value.Value, 0) {
+			return fmt.Errorf("cannot convert %s to JSON", value.Inspect())
+		}
+		e.out.WriteString(value.Inspect())
+	case *object.String:
+		e.writeString(value.Value)
+	case *object.Boolean:
+		e.out.WriteString(value.Inspect())
+	case *object.Nil:
+		e.out.WriteString("null")
+	case *object.Array:
+		return e.encodeCollection(value, depth, '[', ']', len(value.Elements), func(i int) error {
+			return e.encode(value.Elements[i], depth+1)
+		})
+	case *object.Map:
+		return e.encodeCollection(value, depth, '{', '}', len(value.Keys), func(i int) error {
+			key := value.Keys[i]
+			e.writeString(key.Inspect())  // JSON keys are always strings, 1 is written "1"
+			e.out.WriteString(":")
+			if e.indent != "" {
+				e.out.WriteString(" ")
+			}
+			element, _ := value.Get(key)
+			return e.encode(element, depth+1)
+		})
+	default:
+		return fmt.Errorf("cannot convert %s to JSON", typeOf(value))
+	}
+	return nil
+}
+
+func (e *jsonEncoder) encodeCollection(
+	collection object.Object,
+	depth int,
+	open, close byte,
+	length int,
+	element func(i int) error,
+) error {
+	if e.visiting[collection] {
+		return fmt.Errorf("cannot convert to JSON a value that contains itself")
+	}
+	if depth >= MAX_JSON_DEPTH {
+		return fmt.Errorf("cannot convert to JSON a value nested more than %d times", MAX_JSON_DEPTH)
+	}
+	e.visiting[collection] = true
+	defer delete(e.visiting, collection)
+
+	e.out.WriteByte(open)
+	for i := 0; i < length; i++ {
+		if i > 0 {
+			e.out.WriteByte(',')
+		}
+		e.newLine(depth + 1)
+		if err := element(i); err != nil {
+			return err
+		}
+	}
+	if length > 0 {
+		e.newLine(depth)
+	}
+	e.out.WriteByte(close)
+	return nil
+}
+
+func (e *jsonEncoder) newLine(depth int) {
+	if e.indent != "" {
+		e.out.WriteByte('\n')
+		e.out.WriteString(strings.Repeat(e.indent, depth))
+	}
+}
+
+// Go escapes <, > and & by default so the JSON can go inside of HTML; we don't need that
+func (e *jsonEncoder) writeString(s string) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	e.out.Write(bytes.TrimSuffix(buffer.Bytes(), []byte("\n")))
+}
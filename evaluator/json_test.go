@@ -0,0 +1,225 @@
+package evaluator
+
+import (
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/santos-404/myte/object"
+)
+
+func TestJSONModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`json.parse('{"b": 1, "a": [true, null, "x"]}')`, "{'b': 1, 'a': [true, nil, 'x']}"},
+		{`json.parse('[1, -20, 1.0, 2e3, 1.5E-1]')`, "[1, -20, 1.0, 2000.0, 0.15]"},
+		{`type(json.parse('99999999999999999999'))`, "float"},
+		{`json.parse(' "tab\\there \u00e9" ')`, "tab\there é"},
+		{`json.parse('{"a": 1, "a": 2}')`, "{'a': 2}"},
+		{`json.parse('{}') == {}`, "true"},
+		{`json.parse('{"k": {"n": [[], {}]}}')['k']['n']`, "[[], {}]"},
+		{`json.stringify({'b': 1, 'a': [1.0, nil, true, 'x']})`, `{"b":1,"a":[1.0,null,true,"x"]}`},
+		{`json.stringify({1: 'one', true: 'yes'})`, `{"1":"one","true":"yes"}`},
+		{"json.stringify('she said \"<hi>\" &\n')", `"she said \"<hi>\" &\n"`},
+		{`json.stringify([])`, "[]"},
+		{`json.stringify({'a': [1, {}], 'b': []}, 2)`, "{\n  \"a\": [\n    1,\n    {}\n  ],\n  \"b\": []\n}"},
+		{"json.stringify([1], '\t')", "[\n\t1\n]"},
+		{`json.stringify([1], 0)`, "[1]"},
+		{`json.parse(json.stringify({'x': [1, 2.5, 'y']}))`, "{'x': [1, 2.5, 'y']}"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, "import \"json\"; "+tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong. expected=%q, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+func TestJSONModuleErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`json.stringify(fn() {})`, "cannot convert fn to JSON"},
+		{`json.stringify({'f': [print]})`, "cannot convert fn to JSON"},
+		{`json.stringify(json)`, "cannot convert module to JSON"},
+		{`json.stringify([10.0 ** 400])`, "cannot convert +Inf to JSON"},
+		{`json.stringify([], true)`, "argument 2 of json.stringify must be int or string, got bool"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, "import \"json\"; "+tt.input)
+		err, ok := evaluated.(*object.Error)
+		if !ok || err.Message != tt.expected {
+			t.Errorf("%q - result wrong.\nexpected=%q\ngot=     %v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+// A bad indent would give something that is not JSON
+func TestJSONStringifyIndentErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`json.stringify([], -1)`, "the indent of json.stringify must be between 0 and 10 spaces, got -1"},
+		{`json.stringify([], 11)`, "the indent of json.stringify must be between 0 and 10 spaces, got 11"},
+		{`json.stringify([1], 'x')`, `the indent of json.stringify must be up to 10 spaces, tabs or newlines, got "x"`},
+		{`json.stringify([1], ' // ')`, `the indent of json.stringify must be up to 10 spaces, tabs or newlines, got " // "`},
+		{`json.stringify([1], '           ')`, `the indent of json.stringify must be up to 10 spaces, tabs or newlines, got "           "`},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, "import \"json\"; "+tt.input)
+		err, ok := evaluated.(*object.ErrorValue)
+		if !ok || err.Message != tt.expected {
+			t.Errorf("%q - result wrong.\nexpected=%q\ngot=     %v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+// Bad JSON comes from outside, so the program gets an error value it can deal with
+func TestJSONParseGivesBackErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`json.parse('{"a": }')`, "invalid JSON at offset 4: missing value after object key"},
+		{`json.parse('[1, 2')`, "invalid JSON at offset 5: unexpected end of JSON input"},
+		{`json.parse('')`, "invalid JSON at offset 0: unexpected EOF"},
+		{`json.parse('1 2')`, "invalid JSON at offset 3: there is more after the value"},
+		{`json.parse('{1: 2}')`, "invalid JSON at offset 1: object member name must be a string"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, "import \"json\"; "+tt.input)
+		err, ok := evaluated.(*object.ErrorValue)
+		if !ok || err.Message != tt.expected || err.Column != 26 {
+			t.Errorf("%q - result wrong.\nexpected=%q\ngot=     %v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+func TestJSONRejectsValuesContainingThemselves(t *testing.T) {
+	stringify := jsonFunction(t, "stringify")
+
+	array := &object.Array{}
+	array.Elements = []object.Object{&object.Integer{Value: 1}, array}
+	m := object.NewMap()
+	m.Set(&object.String{Value: "self"}, &object.Array{Elements: []object.Object{m}})
+
+	for _, value := range []object.Object{array, m} {
+		_, err := stringify([]object.Object{value})
+		if err == nil || err.Error() != "cannot convert to JSON a value that contains itself" {
+			t.Errorf("%s - error wrong, got=%v", value.Type(), err)
+		}
+	}
+
+	// The same value twice is fine, as long as it's not inside of itself
+	shared := &object.Array{Elements: []object.Object{&object.Integer{Value: 1}}}
+	result, err := stringify([]object.Object{&object.Array{Elements: []object.Object{shared, shared}}})
+	if err != nil || result.Inspect() != "[[1],[1]]" {
+		t.Errorf("shared value wrong. got=%v, %v", result, err)
+	}
+}
+
+func TestJSONDepthLimit(t *testing.T) {
+	parse := jsonFunction(t, "parse")
+
+	text := strings.Repeat("[", MAX_JSON_DEPTH+2) + strings.Repeat("]", MAX_JSON_DEPTH+2)
+	_, err := parse([]object.Object{&object.String{Value: text}})
+	if err == nil || !strings.Contains(err.Error(), "exceeded max depth") {
+		t.Errorf("error wrong, got=%v", err)
+	}
+}
+
+// Whatever we make, stringify and then parse gives back the same value, with the
+// same types and the keys in the same order
+func TestJSONRoundTrip(t *testing.T) {
+	parse, stringify := jsonFunction(t, "parse"), jsonFunction(t, "stringify")
+	r := rand.New(rand.NewPCG(41, 45))
+
+	for i := 0; i < 300; i++ {
+		value := randomJSONValue(r, 0)
+
+		for _, indent := range []object.Object{nil, &object.Integer{Value: 2}, &object.String{Value: "\t"}} {
+			args := []object.Object{value}
+			if indent != nil {
+				args = append(args, indent)
+			}
+			text, err := stringify(args)
+			if err != nil {
+				t.Fatalf("stringify error on %s: %s", value.Inspect(), err)
+			}
+			parsed, err := parse([]object.Object{text})
+			if err != nil {
+				t.Fatalf("parse error on %s: %s", text.Inspect(), err)
+			}
+			if parsed.Inspect() != value.Inspect() || !objectsEqual(parsed, value) {
+				t.Fatalf("round trip wrong.\nvalue= %s\ntext=  %s\ngot=   %s",
+					value.Inspect(), text.Inspect(), parsed.Inspect())
+			}
+		}
+	}
+}
+
+func jsonFunction(t *testing.T, name string) func(args []object.Object) (object.Object, error) {
+	module, ok := LookupStdModule("json")
+	if !ok {
+		t.Fatalf("there is no json module")
+	}
+	for _, fn := range module.Functions {
+		if fn.Name == name {
+			return fn.Object().Fn
+		}
+	}
+	t.Fatalf("there is no json.%s", name)
+	return nil
+}
+
+func randomJSONValue(r *rand.Rand, depth int) object.Object {
+	kinds := 8
+	if depth > 4 {
+		kinds = 6  // No more arrays or maps, so it ends
+	}
+
+	switch r.IntN(kinds) {
+	case 0:
+		return &object.Integer{Value: r.Int64() - r.Int64()}
+	case 1:
+		return &object.Float{Value: r.NormFloat64() * float64(r.IntN(1e6))}
+	case 2:
+		return randomJSONString(r)
+	case 3:
+		return nativeBoolToBooleanObject(r.IntN(2) == 0)
+	case 4:
+		return NIL
+	case 5:
+		return &object.Float{Value: float64(r.IntN(100))}  // Whole floats must stay floats
+	case 6:
+		array := &object.Array{Elements: []object.Object{}}
+		for i := r.IntN(5); i > 0; i-- {
+			array.Elements = append(array.Elements, randomJSONValue(r, depth+1))
+		}
+		return array
+	}
+
+	m := object.NewMap()
+	for i := r.IntN(5); i > 0; i-- {
+		m.Set(randomJSONString(r), randomJSONValue(r, depth+1))
+	}
+	return m
+}
+
+func randomJSONString(r *rand.Rand) *object.String {
+	const chars = "abc XYZ019\"\\/\n\t\r\x01<>&'é漢🙂"
+	runes := []rune(chars)
+	var b strings.Builder
+	for i := r.IntN(8); i > 0; i-- {
+		b.WriteRune(runes[r.IntN(len(runes))])
+	}
+	return &object.String{Value: b.String()}
+}
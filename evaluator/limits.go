@@ -0,0 +1,151 @@
+package evaluator
+
+import (
+	"github.com/santos-404/myte/object"
+	"github.com/santos-404/myte/token"
+)
+
+/*
+The kinds of the errors a program gets when it goes over one of its limits (see
+object.Limits), or when it calls exit() and the process doesn't end (see
+object.IO). These are not like the rest: no try catches them and no finally runs,
+otherwise the program could just keep going.
+*/
+const (
+	STEP_LIMIT_KIND  = "step_limit"
+	DEPTH_LIMIT_KIND = "depth_limit"
+	SIZE_LIMIT_KIND  = "size_limit"
+	CANCELLED_KIND   = "cancelled"
+	EXIT_KIND        = "exit"
+)
+
+// Asking the context is not free, so it's only done every so many steps
+const CONTEXT_CHECK_INTERVAL = 1024
+
+// The calls that can be going on at once even without limits, counting the program
+// itself the way the vm counts its frames
+const MAX_CALL_DEPTH = 100000
+
+// The Evals that can be going on inside of each other. This is what really takes the
+// Go stack: a call in the middle of a few loops, blocks and expressions takes a lot
+// more of it than one on its own, so counting the calls is not enough.
+const MAX_NESTING = 400000
+
+func isLimitError(obj object.Object) bool {
+	err, ok := obj.(*object.Error)
+	if !ok {
+		return false
+	}
+	switch err.Kind {
+	case STEP_LIMIT_KIND, DEPTH_LIMIT_KIND, SIZE_LIMIT_KIND, CANCELLED_KIND, EXIT_KIND:
+		return true
+	}
+	return false
+}
+
+func step(usage *object.Usage, tok token.Token) *object.Error {
+	if usage == nil {
+		return nil
+	}
+	usage.Steps++
+
+	limits := usage.Limits
+	if limits.MaxSteps > 0 && usage.Steps > limits.MaxSteps {
+		return newKindError(tok, STEP_LIMIT_KIND, "step limit exceeded: %d", limits.MaxSteps)
+	}
+	if limits.Context != nil && usage.Steps%CONTEXT_CHECK_INTERVAL == 1 {
+		if err := limits.Context.Err(); err != nil {
+			return newKindError(tok, CANCELLED_KIND, "execution cancelled: %s", err)
+		}
+	}
+	return nil
+}
+
+// enterCall goes one call deeper; whoever gets no error must call leaveCall later
+func enterCall(usage *object.Usage, tok token.Token) *object.Error {
+	if usage == nil {
+		return nil
+	}
+	if limit := usage.Limits.MaxCallDepth; limit > 0 && usage.Depth >= limit {
+		return callDepthError(tok, limit)
+	}
+	if usage.Depth+1 >= MAX_CALL_DEPTH || usage.Nesting >= MAX_NESTING {
+		return newKindError(tok, STACK_OVERFLOW_KIND, "stack overflow")
+	}
+	usage.Depth++
+	return nil
+}
+
+func leaveCall(usage *object.Usage) {
+	if usage != nil {
+		usage.Depth--
+	}
+}
+
+func callDepthError(tok token.Token, limit int) *object.Error {
+	return newKindError(tok, DEPTH_LIMIT_KIND, "call depth limit exceeded: %d", limit)
+}
+
+// checkSize gives back the value, or an error when it's a collection bigger than allowed
+func checkSize(usage *object.Usage, tok token.Token, obj object.Object) object.Object {
+	var size int
+	switch obj := obj.(type) {
+	case *object.Array:
+		size = len(obj.Elements)
+	case *object.Map:
+		size = len(obj.Keys)
+	case *object.String:
+		size = len(obj.Value)
+	default:
+		return obj
+	}
+	if err := checkPlannedSize(usage, tok, size); err != nil {
+		return err
+	}
+	return obj
+}
+
+// checkPlannedSize is checkSize for a value that is not made yet, so a big one fails
+// before it takes the memory
+func checkPlannedSize(usage *object.Usage, tok token.Token, size int) *object.Error {
+	if usage == nil || usage.Limits.MaxCollectionSize <= 0 {
+		return nil
+	}
+	if limit := usage.Limits.MaxCollectionSize; size > limit {
+		return newKindError(tok, SIZE_LIMIT_KIND, "collection size limit exceeded: %d, the limit is %d", size, limit)
+	}
+	return nil
+}
+
+// callBuiltinWithin is callBuiltin within the size limit. The builtins that can make
+// a big value tell how big first.
+func callBuiltinWithin(usage *object.Usage, tok token.Token, builtin *object.Builtin, args []object.Object) object.Object {
+	if builtin.Size != nil {
+		if err := checkPlannedSize(usage, tok, builtin.Size(args)); err != nil {
+			return err
+		}
+	}
+	return checkSize(usage, tok, callBuiltin(usage, tok, builtin, args))
+}
+
+// infixOperationWithin is evalInfixOperation within the size limit. Only + makes a
+// string or an array out of two; maps are merged, so they can't grow past both of them.
+func infixOperationWithin(usage *object.Usage, tok token.Token, operator string, left, right object.Object) object.Object {
+	if operator == "+" {
+		size := 0
+		switch left := left.(type) {
+		case *object.String:
+			if right, ok := right.(*object.String); ok {
+				size = len(left.Value) + len(right.Value)
+			}
+		case *object.Array:
+			if right, ok := right.(*object.Array); ok {
+				size = len(left.Elements) + len(right.Elements)
+			}
+		}
+		if err := checkPlannedSize(usage, tok, size); err != nil {
+			return err
+		}
+	}
+	return checkSize(usage, tok, evalInfixOperation(tok, operator, left, right))
+}
@@ -0,0 +1,108 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/santos-404/myte/lexer"
+	"github.com/santos-404/myte/object"
+	"github.com/santos-404/myte/parser"
+)
+
+func testEvalWithLimits(t *testing.T, input string, limits object.Limits) object.Object {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has %d errors for %q: %v", len(p.Errors()), input, p.Errors())
+	}
+
+	env := object.NewEnvironment()
+	env.SetLimits(limits)
+	return Eval(program, env)
+}
+
+func TestLimits(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		input    string
+		limits   object.Limits
+		kind     string
+		expected string
+	}{
+		{"for true { 1 }", object.Limits{MaxSteps: 100}, STEP_LIMIT_KIND, "step limit exceeded: 100"},
+		{"var f = fn() { f() }; f()", object.Limits{MaxSteps: 100}, STEP_LIMIT_KIND, "step limit exceeded: 100"},
+		{"var f = fn(n) { 1 + f(n + 1) }; f(0)", object.Limits{MaxCallDepth: 20}, DEPTH_LIMIT_KIND, "call depth limit exceeded: 20"},
+		{`var s = "ab"; for true { s = s + s; }`, object.Limits{MaxCollectionSize: 100}, SIZE_LIMIT_KIND, "collection size limit exceeded: 128, the limit is 100"},
+		{"var a = [1]; for true { a += a; }", object.Limits{MaxCollectionSize: 10}, SIZE_LIMIT_KIND, "collection size limit exceeded: 16, the limit is 10"},
+		{`import "strings"; strings.repeat("ab", 1000)`, object.Limits{MaxCollectionSize: 100}, SIZE_LIMIT_KIND, "collection size limit exceeded: 2000, the limit is 100"},
+		// These are too big to be made at all, the limit stops them before they are
+		{`import "strings"; strings.repeat("ab", 1099511627776)`, object.Limits{MaxCollectionSize: 100}, SIZE_LIMIT_KIND, "collection size limit exceeded: 2199023255552, the limit is 100"},
+		{`import "strings"; strings.pad("a", -1099511627776, "é")`, object.Limits{MaxCollectionSize: 100}, SIZE_LIMIT_KIND, "collection size limit exceeded: 2199023255551, the limit is 100"},
+		{`import "strings"; strings.join(["ab", "cd"], ", ")`, object.Limits{MaxCollectionSize: 5}, SIZE_LIMIT_KIND, "collection size limit exceeded: 6, the limit is 5"},
+		{"for true { 1 }", object.Limits{Context: cancelled}, CANCELLED_KIND, "execution cancelled: context canceled"},
+		// Nothing catches them, or the program would just keep going
+		{"for true { try { 1 } catch e { 2 }; }", object.Limits{MaxSteps: 100}, STEP_LIMIT_KIND, "step limit exceeded: 100"},
+		{"var f = fn() { try { f() } catch e { 1 } }; f()", object.Limits{MaxCallDepth: 20}, DEPTH_LIMIT_KIND, "call depth limit exceeded: 20"},
+		{"var f = fn() { try { f() } finally { f() } }; f()", object.Limits{MaxSteps: 1000}, STEP_LIMIT_KIND, "step limit exceeded: 1000"},
+	}
+
+	for _, tt := range tests {
+		err, ok := testEvalWithLimits(t, tt.input, tt.limits).(*object.Error)
+		if !ok {
+			t.Errorf("%q: no error", tt.input)
+			continue
+		}
+		if err.Message != tt.expected || err.Kind != tt.kind {
+			t.Errorf("%q: wrong error. expected=%q (%s), got=%q (%s)", tt.input, tt.expected, tt.kind, err.Message, err.Kind)
+		}
+	}
+}
+
+func TestWithinLimits(t *testing.T) {
+	limits := object.Limits{MaxSteps: 10000, MaxCallDepth: 20, MaxCollectionSize: 100, Context: context.Background()}
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"var f = fn(n) { if n == 0 { return 0; }; 1 + f(n - 1) }; f(19) + f(19)", 38},
+		// Tail calls take the place of the caller, they don't go any deeper
+		{"var f = fn(n, acc) { if n == 0 { return acc; }; return f(n - 1, acc + 1); }; f(1000, 0)", 1000},
+		{"var a = [0]; for len(a) < 64 { a += a; }; len(a)", 64},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEvalWithLimits(t, tt.input, limits), tt.expected)
+	}
+}
+
+// A call in the middle of loops, blocks and expressions takes a lot more of the Go
+// stack than one on its own. The evaluator must stop it before Go does, which would
+// crash the whole process.
+func TestStackOverflowInsideExpressions(t *testing.T) {
+	tests := []string{
+		`const deep = fn(n) {
+			if n == 0 { return 0; }
+			for true { try { if true { return 1 + (1 + [[deep(n - 1)][0]][0]) } } finally { } }
+		};
+		deep(99990)`,
+		`const deep = fn(n) {
+			if n == 0 { return 0; }
+			for true { try { if true { for true { if true { return -(-{"a": [deep(n - 1)]}["a"][0]) } } } } finally { } }
+		};
+		deep(99990)`,
+		"const deep = fn(n) { if n == 0 { return 0; }; 1 + (fn() { 1 + (fn() { deep(n - 1) })() })() }; deep(99990)",
+	}
+
+	for _, input := range tests {
+		err, ok := testEvalWithLimits(t, input, object.Limits{}).(*object.Error)
+		if !ok {
+			t.Errorf("%q: no error", input)
+			continue
+		}
+		if err.Message != "stack overflow" || err.Kind != STACK_OVERFLOW_KIND {
+			t.Errorf("%q: wrong error. expected=%q (%s), got=%q (%s)", input, "stack overflow", STACK_OVERFLOW_KIND, err.Message, err.Kind)
+		}
+	}
+}
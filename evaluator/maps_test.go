@@ -0,0 +1,57 @@
+package evaluator
+
+import (
+	"testing"
+)
+
+func TestMaps(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"{}", "{}"},
+		{"{'a': 1, 2: 'two', true: [nil]}", "{'a': 1, 2: 'two', true: [nil]}"},
+		{"{'b': 1, 'a': 2, 'b': 3}", "{'b': 3, 'a': 2}"},
+		{"var m = {'a': 1, 'b': {'c': 2}}; m['a'] + m['b']['c']", "3"},
+		{"var k = 'x'; {k: 1}['x']", "1"},
+		{"{1: 'int', '1': 'string'}[1]", "int"},
+		{"{'a': 1}['z']", "nil"},
+		{"{'a': 1, 'b': 2} + {'b': 3, 'c': 4}", "{'a': 1, 'b': 3, 'c': 4}"},
+		{"var m = {'a': 1}; var n = m + {'b': 2}; m", "{'a': 1}"},
+		{"{'a': 1, 'b': 2} == {'b': 2, 'a': 1.0}", "true"},
+		{"{'a': 1} == {'a': 2}", "false"},
+		{"{'a': 1} == {'b': 1}", "false"},
+		{"len({'a': 1, 'b': 2})", "2"},
+		{"keys({'b': 1, 'a': 2})", "['b', 'a']"},
+		{"keys({})", "[]"},
+		{"type({})", "map"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong. expected=%q, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+func TestMapErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"{[1]: 2}", "ERROR: unusable as map key: array. Line: 0, column: 1"},
+		{"{1.5: 2}", "ERROR: unusable as map key: float. Line: 0, column: 1"},
+		{"{'a': 1}[nil]", "ERROR: unusable as map key: nil. Line: 0, column: 9"},
+		{"{'a': x}", "ERROR: identifier not found: x. Line: 0, column: 7"},
+		{"{'a': 1} + [1]", "ERROR: unsupported operand types: map + array. Line: 0, column: 10"},
+		{"keys([1])", "ERROR: argument 1 of keys must be map, got array. Line: 0, column: 5"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong.\nexpected=%q\ngot=     %v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
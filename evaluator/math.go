@@ -0,0 +1,230 @@
+package evaluator
+
+import (
+	"math"
+
+	"github.com/santos-404/myte/object"
+	"github.com/santos-404/myte/token"
+)
+
+/*
+The math module. Ints stay ints wherever the result can be one (abs, floor, min...)
+and everything else gives back a float. floor rounds towards -infinite, just like
+// does, so floor(a / b) and a // b are the same number even when one of them is
+negative: floor(-7 / 2) is -4.0 and -7 // 2 is -4.
+*/
+func init() {
+	registerStdModule(&StdModule{
+		Name: "math",
+		Doc:  "Numbers: rounding, powers, roots, trigonometry and logarithms",
+		Functions: []*Builtin{
+			{
+				Name:       "sqrt",
+				Parameters: []BuiltinParameter{numberParameter("x")},
+				Returns:    "float",
+				Doc:        "The square root of x, nan when x is negative",
+				fn:         floatFunction(math.Sqrt),
+			},
+			{
+				Name:       "pow",
+				Parameters: []BuiltinParameter{numberParameter("base"), numberParameter("exponent")},
+				Returns:    "int | float",
+				Doc:        "The same as base ** exponent",
+				fn:         mathPow,
+			},
+			{
+				Name:       "floor",
+				Parameters: []BuiltinParameter{numberParameter("x")},
+				Returns:    "int | float",
+				Doc:        "Rounds x towards -inf. It agrees with //, so floor(-7 / 2) == -7 // 2",
+				fn:         roundingFunction(math.Floor),
+			},
+			{
+				Name:       "ceil",
+				Parameters: []BuiltinParameter{numberParameter("x")},
+				Returns:    "int | float",
+				Doc:        "Rounds x towards +inf",
+				fn:         roundingFunction(math.Ceil),
+			},
+			{
+				Name:       "round",
+				Parameters: []BuiltinParameter{numberParameter("x")},
+				Returns:    "int | float",
+				Doc:        "Rounds x to the closest whole number, halves away from zero",
+				fn:         roundingFunction(math.Round),
+			},
+			{
+				Name:       "abs",
+				Parameters: []BuiltinParameter{numberParameter("x")},
+				Returns:    "int | float",
+				Doc:        "x without its sign",
+				fn:         mathAbs,
+			},
+			{
+				Name:       "min",
+				Parameters: []BuiltinParameter{numberParameter("value"), numberParameter("values")},
+				Variadic:   true,
+				Returns:    "int | float",
+				Doc:        "The smallest of the values, as it was given",
+				fn:         extremeFunction(func(a, b float64) bool { return a < b }),
+			},
+			{
+				Name:       "max",
+				Parameters: []BuiltinParameter{numberParameter("value"), numberParameter("values")},
+				Variadic:   true,
+				Returns:    "int | float",
+				Doc:        "The biggest of the values, as it was given",
+				fn:         extremeFunction(func(a, b float64) bool { return a > b }),
+			},
+			{
+				Name:       "sin",
+				Parameters: []BuiltinParameter{numberParameter("x")},
+				Returns:    "float",
+				Doc:        "The sine of x, in radians",
+				fn:         floatFunction(math.Sin),
+			},
+			{
+				Name:       "cos",
+				Parameters: []BuiltinParameter{numberParameter("x")},
+				Returns:    "float",
+				Doc:        "The cosine of x, in radians",
+				fn:         floatFunction(math.Cos),
+			},
+			{
+				Name:       "tan",
+				Parameters: []BuiltinParameter{numberParameter("x")},
+				Returns:    "float",
+				Doc:        "The tangent of x, in radians",
+				fn:         floatFunction(math.Tan),
+			},
+			{
+				Name:       "asin",
+				Parameters: []BuiltinParameter{numberParameter("x")},
+				Returns:    "float",
+				Doc:        "The arcsine of x, in radians",
+				fn:         floatFunction(math.Asin),
+			},
+			{
+				Name:       "acos",
+				Parameters: []BuiltinParameter{numberParameter("x")},
+				Returns:    "float",
+				Doc:        "The arccosine of x, in radians",
+				fn:         floatFunction(math.Acos),
+			},
+			{
+				Name:       "atan",
+				Parameters: []BuiltinParameter{numberParameter("x")},
+				Returns:    "float",
+				Doc:        "The arctangent of x, in radians",
+				fn:         floatFunction(math.Atan),
+			},
+			{
+				Name:       "atan2",
+				Parameters: []BuiltinParameter{numberParameter("y"), numberParameter("x")},
+				Returns:    "float",
+				Doc:        "The angle of the point (x, y), in radians",
+				fn: func(args []object.Object) (object.Object, error) {
+					return &object.Float{Value: math.Atan2(toFloat(args[0]), toFloat(args[1]))}, nil
+				},
+			},
+			{
+				Name: "log",
+				Parameters: []BuiltinParameter{
+					numberParameter("x"),
+					{Name: "base", Types: numberTypes, Optional: true},
+				},
+				Returns: "float",
+				Doc:     "The logarithm of x, the natural one when there is no base",
+				fn:      mathLog,
+			},
+			{
+				Name:       "log2",
+				Parameters: []BuiltinParameter{numberParameter("x")},
+				Returns:    "float",
+				Doc:        "The base 2 logarithm of x",
+				fn:         floatFunction(math.Log2),
+			},
+			{
+				Name:       "log10",
+				Parameters: []BuiltinParameter{numberParameter("x")},
+				Returns:    "float",
+				Doc:        "The base 10 logarithm of x",
+				fn:         floatFunction(math.Log10),
+			},
+			{
+				Name:       "exp",
+				Parameters: []BuiltinParameter{numberParameter("x")},
+				Returns:    "float",
+				Doc:        "e to the power of x",
+				fn:         floatFunction(math.Exp),
+			},
+		},
+		Values: map[string]object.Object{
+			"pi":  &object.Float{Value: math.Pi},
+			"e":   &object.Float{Value: math.E},
+			"inf": &object.Float{Value: math.Inf(1)},
+			"nan": &object.Float{Value: math.NaN()},
+		},
+	})
+}
+
+// Ints are taken as floats; the result is always a float
+func floatFunction(f func(float64) float64) func(args []object.Object) (object.Object, error) {
+	return func(args []object.Object) (object.Object, error) {
+		return &object.Float{Value: f(toFloat(args[0]))}, nil
+	}
+}
+
+// An int is already whole, so it's given back as it is
+func roundingFunction(f func(float64) float64) func(args []object.Object) (object.Object, error) {
+	return func(args []object.Object) (object.Object, error) {
+		if integer, ok := args[0].(*object.Integer); ok {
+			return integer, nil
+		}
+		return &object.Float{Value: f(toFloat(args[0]))}, nil
+	}
+}
+
+// The first value that wins against every other one. 1 and 1.0 are equal, so the
+// one that comes first is the one given back.
+func extremeFunction(wins func(a, b float64) bool) func(args []object.Object) (object.Object, error) {
+	return func(args []object.Object) (object.Object, error) {
+		best := args[0]
+		for _, arg := range args[1:] {
+			if wins(toFloat(arg), toFloat(best)) {
+				best = arg
+			}
+		}
+		return best, nil
+	}
+}
+
+// An error is given back as a Go one, so callBuiltin puts it where the call is
+func mathPow(args []object.Object) (object.Object, error) {
+	result := evalInfixOperation(token.Token{}, "**", args[0], args[1])
+	if err, ok := result.(*object.Error); ok {
+		return nil, kindErrorf(err.Kind, "%s", err.Message)
+	}
+	return result, nil
+}
+
+func mathAbs(args []object.Object) (object.Object, error) {
+	switch arg := args[0].(type) {
+	case *object.Integer:
+		if arg.Value < 0 {
+			return &object.Integer{Value: -arg.Value}, nil
+		}
+		return arg, nil
+	case *object.Float:
+		return &object.Float{Value: math.Abs(arg.Value)}, nil
+	}
+	return nil, nil
+}
+
+func mathLog(args []object.Object) (object.Object, error) {
+	x := math.Log(toFloat(args[0]))
+	if len(args) == 2 {
+		x /= math.Log(toFloat(args[1]))
+	}
+	return &object.Float{Value: x}, nil
+}
@@ -0,0 +1,73 @@
+package evaluator
+
+import (
+	"testing"
+)
+
+func TestMathModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"math.sqrt(16)", "4.0"},
+		{"math.sqrt(2.25)", "1.5"},
+		{"math.sqrt(-1)", "NaN"},
+		{"math.pow(2, 10)", "1024"},
+		{"math.pow(2, -1)", "0.5"},
+		{"math.pow(2.0, 3)", "8.0"},
+		{"math.pow(2, 64)", "1.8446744073709552e+19"},
+		{"math.pow(2, 62)", "4611686018427387904"},
+		{"math.floor(3)", "3"},
+		{"math.floor(3.7)", "3.0"},
+		{"math.floor(-3.2)", "-4.0"},
+		{"math.ceil(-3.7)", "-3.0"},
+		{"math.round(2.5)", "3.0"},
+		{"math.round(-2.5)", "-3.0"},
+		{"math.abs(-4)", "4"},
+		{"math.abs(-4.5)", "4.5"},
+		{"math.min(3, 1.5, 2)", "1.5"},
+		{"math.max(3, 1.5, 2)", "3"},
+		{"math.max(1, 1.0)", "1"},
+		{"math.min(7)", "7"},
+		{"math.sin(0)", "0.0"},
+		{"math.cos(math.pi)", "-1.0"},
+		{"math.atan2(1, 1) * 4 == math.pi", "true"},
+		{"math.log(math.e)", "1.0"},
+		{"math.log(8, 2)", "3.0"},
+		{"math.log2(1024)", "10.0"},
+		{"math.log10(1000)", "3.0"},
+		{"math.exp(0)", "1.0"},
+		{"math.inf > 10 ** 300", "true"},
+		{"math.nan == math.nan", "false"},
+		// floor and // round the same way, negative numbers too
+		{"math.floor(-7 / 2) == -7 // 2", "true"},
+		{"math.floor(7 / -2) == 7 // -2", "true"},
+		{"math.floor(-7.5 / 2) == -7.5 // 2", "true"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, "import \"math\"; "+tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong. expected=%q, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+func TestMathModuleErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"math.sqrt('4')", "ERROR: argument 1 of math.sqrt must be int or float, got string. Line: 0, column: 25"},
+		{"math.min()", "ERROR: wrong number of arguments to math.min: want=at least 1, got=0. Line: 0, column: 24"},
+		{"math.max(1, nil)", "ERROR: argument 2 of math.max must be int or float, got nil. Line: 0, column: 24"},
+		{"math.tau", "ERROR: module math has no member: tau. Line: 0, column: 21"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, "import \"math\"; "+tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong.\nexpected=%q\ngot=     %v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
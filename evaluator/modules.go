@@ -0,0 +1,238 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/lexer"
+	"github.com/santos-404/myte/object"
+	"github.com/santos-404/myte/parser"
+)
+
+const (
+	SEARCH_PATH_VARIABLE = "MYTE_PATH"  // A list of directories, the same way PATH is
+	MODULE_EXTENSION     = ".myte"
+	IMPORT_CYCLE         = "import cycle: "
+)
+
+/*
+Modules loads the files a program imports. Each one runs once, the first time it's
+imported, and whoever imports it after that gets the same module. Paths that start
+with ./ or ../ are relative to the file that imports; any other is a standard module
+(see StdModule) or else is looked up on the directory of the program first and then
+on every directory of SearchPath, in order.
+*/
+type Modules struct {
+	SearchPath []string
+
+	file    string                     // The program, empty when it has no file
+	wd      string                     // Where code without a file imports from
+	cache   map[string]*object.Module  // By absolute path
+	loading []string                   // The files running right now, each one imports the next
+}
+
+// NewModules gets ready to run file, or code without a file (like the REPL's) when it's
+// empty. The search path starts as whatever MYTE_PATH says.
+func NewModules(file string) *Modules {
+	wd, _ := os.Getwd()
+	return &Modules{
+		SearchPath: filepath.SplitList(os.Getenv(SEARCH_PATH_VARIABLE)),
+		file:       file,
+		wd:         wd,
+		cache:      make(map[string]*object.Module),
+	}
+}
+
+// Importer is the one for the program itself
+func (m *Modules) Importer() object.Importer {
+	return m.ProgramImporter(m.file)
+}
+
+// ProgramImporter is the one for another program (or code without a file, when it's
+// empty) that shares the modules with this one: a module both of them import runs once.
+func (m *Modules) ProgramImporter(file string) object.Importer {
+	if file == "" {
+		return &fileImporter{modules: m, dir: m.wd, root: m.wd}
+	}
+	file = absolute(file)
+	dir := filepath.Dir(file)
+	return &fileImporter{modules: m, dir: dir, root: dir, program: file}
+}
+
+type fileImporter struct {
+	modules *Modules
+	dir     string  // Of the file that imports
+	root    string  // The directory of the program
+	program string
+}
+
+func (i *fileImporter) Import(path string, usage *object.Usage) (*object.Module, error) {
+	return i.modules.load(path, i, usage)
+}
+
+func (m *Modules) load(path string, from *fileImporter, usage *object.Usage) (*object.Module, error) {
+	if std, ok := LookupStdModule(path); ok {
+		return std.Module(), nil
+	}
+
+	file, err := m.resolve(path, from)
+	if err != nil {
+		return nil, err
+	}
+
+	// Importing the program from one of its modules is a cycle too
+	running := m.loading
+	if from.program != "" {
+		running = append([]string{from.program}, m.loading...)
+	}
+	for _, loading := range running {
+		if loading == file {
+			var chain []string
+			for _, link := range append(running, file) {
+				chain = append(chain, display(link))
+			}
+			return nil, fmt.Errorf("%s%s", IMPORT_CYCLE, strings.Join(chain, " -> "))
+		}
+	}
+	if module, ok := m.cache[file]; ok {
+		return module, nil
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	p := parser.New(lexer.New(string(content)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, fmt.Errorf("cannot parse %s: %s", display(file), p.Errors()[0])
+	}
+
+	env := object.NewEnvironment()
+	env.SetImporter(&fileImporter{modules: m, dir: filepath.Dir(file), root: from.root, program: from.program})
+	if usage != nil {
+		env.ShareUsage(usage)
+	}
+
+	m.loading = append(m.loading, file)
+	result := Eval(program, env)
+	m.loading = m.loading[:len(m.loading)-1]
+
+	// The position of the error is on the module, the one of the import is added later.
+	// A cycle already tells every file it goes through.
+	if err, ok := result.(*object.Error); ok {
+		if strings.HasPrefix(err.Message, IMPORT_CYCLE) {
+			return nil, errors.New(err.Message)
+		}
+		return nil, &ModuleError{File: file, Err: err}
+	}
+
+	module := &object.Module{
+		Name: strings.TrimSuffix(filepath.Base(file), MODULE_EXTENSION),
+		Path: file,
+		Env:  env,
+	}
+	m.cache[file] = module
+	return module, nil
+}
+
+// ModuleError is a module that failed while it ran. The error is kept as it is, so
+// the import fails with its kind: a limit it went over is still one.
+type ModuleError struct {
+	File string
+	Err  *object.Error
+}
+
+func (e *ModuleError) Error() string {
+	return fmt.Sprintf("%s (%s, line %d, column %d)", e.Err.Message, display(e.File), e.Err.Line, e.Err.Column)
+}
+
+// importErrorKind is the kind the module failed with, or a runtime one when it
+// couldn't even run (it's not there, it doesn't parse...)
+func importErrorKind(err error) string {
+	var moduleErr *ModuleError
+	if errors.As(err, &moduleErr) {
+		return moduleErr.Err.Kind
+	}
+	return RUNTIME_KIND
+}
+
+func (m *Modules) resolve(path string, from *fileImporter) (string, error) {
+	name := filepath.FromSlash(path)
+	if !strings.HasSuffix(name, MODULE_EXTENSION) {
+		name += MODULE_EXTENSION
+	}
+
+	var candidates []string
+	switch {
+	case filepath.IsAbs(name):
+		candidates = []string{name}
+	case strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../"):
+		candidates = []string{filepath.Join(from.dir, name)}
+	default:
+		candidates = append(candidates, filepath.Join(from.root, name))
+		for _, directory := range m.SearchPath {
+			candidates = append(candidates, filepath.Join(directory, name))
+		}
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return absolute(candidate), nil
+		}
+	}
+	return "", fmt.Errorf("module not found: %s", path)
+}
+
+func absolute(file string) string {
+	if abs, err := filepath.Abs(file); err == nil {
+		return filepath.Clean(abs)
+	}
+	return file
+}
+
+// Files are shown relative to where we are whenever they are below it
+func display(file string) string {
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, file); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return file
+}
+
+
+func evalImportStatement(is *ast.ImportStatement, env *object.Environment) object.Object {
+	if err := checkDeclarable(is.Name); err != nil {
+		return err
+	}
+
+	importer := env.Importer()
+	if importer == nil {
+		// Nobody said where this code comes from, so it imports from where we are
+		importer = NewModules("").Importer()
+		env.SetImporter(importer)
+	}
+
+	module, err := importer.Import(is.Path, env.Usage())
+	if err != nil {
+		return newKindError(is.Token, importErrorKind(err), "%s", err)
+	}
+
+	env.SetConst(is.Name.Value, module)
+	return nil
+}
+
+func evalMemberExpression(node *ast.MemberExpression, env *object.Environment) object.Object {
+	obj := Eval(node.Object, env)
+	if isError(obj) {
+		return obj
+	}
+
+	// A missing member is the name's fault, not the dot's
+	return evalMemberOperation(node.Member.Token, obj, node.Member.Value)
+}
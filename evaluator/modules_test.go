@@ -0,0 +1,148 @@
+package evaluator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/santos-404/myte/lexer"
+	"github.com/santos-404/myte/object"
+	"github.com/santos-404/myte/parser"
+)
+
+// writeFiles makes a tree of modules on a new directory and moves there, so the
+// paths on the errors are short
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+	return dir
+}
+
+func runFile(t *testing.T, modules *Modules, file string) object.Object {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := parser.New(lexer.New(string(content)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has errors for %s: %v", file, p.Errors())
+	}
+
+	env := object.NewEnvironment()
+	env.SetImporter(modules.Importer())
+	return Eval(program, env)
+}
+
+func TestImports(t *testing.T) {
+	writeFiles(t, map[string]string{
+		"lib/text.myte":    "const shout = fn(s) { s + '!' };",
+		"lib/twice.myte":   "import \"./helper\"; const apply = fn(f, x) { helper.call(f, helper.call(f, x)) };",
+		"lib/helper.myte":  "const call = fn(f, x) { f(x) };",
+		"app/counter.myte": "var n = 0; const inc = fn() { n += 1; n };",
+		"app/main.myte":    "",
+		"vendor/pad.myte":  "const left = fn(s) { ' ' + s };",
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "lib/text"; text.shout('hi')`, "hi!"},
+		{`import "lib/text.myte" as t; t.shout('a')`, "a!"},
+		{`import "lib/twice"; twice.apply(fn(x) { x * 3 }, 2)`, "18"},
+		{`import "./counter"; counter.n`, "0"},
+		// Every module runs once, so both names get the same bindings
+		{`import "./counter"; import "./counter" as again; counter.inc(); again.inc()`, "2"},
+		{`import "../vendor/pad"; pad.left('x')`, " x"},
+		{`import "pad"; pad.left('y')`, " y"},
+		{`import "lib/text"; text`, "module text"},
+		{`const f = fn() { import "lib/text"; text.shout('in') }; f()`, "in!"},
+	}
+
+	for _, tt := range tests {
+		if err := os.WriteFile("app/main.myte", []byte(tt.input), 0644); err != nil {
+			t.Fatal(err)
+		}
+		modules := NewModules("app/main.myte")
+		modules.SearchPath = []string{".", "vendor"}  // lib/ is not on the directory of the program
+
+		result := runFile(t, modules, "app/main.myte")
+		if result == nil || result.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong. expected=%q, got=%v", tt.input, tt.expected, result)
+		}
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	writeFiles(t, map[string]string{
+		"a.myte":      "import \"./b\"; const x = 1;",
+		"b.myte":      "import \"./a\";",
+		"self.myte":   "import \"./self\";",
+		"broken.myte": "var = 1;",
+		"fails.myte":  "const ok = 1;\nconst bad = 1 / 0;",
+		"nested.myte": "import \"./fails\";",
+		"plain.myte":  "const value = 1;",
+		"main.myte":   "",
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "./missing"`, "ERROR: module not found: ./missing. Line: 0, column: 1"},
+		{`import "./a"`, "ERROR: import cycle: main.myte -> a.myte -> b.myte -> a.myte. Line: 0, column: 1"},
+		{`import "./self"`, "ERROR: import cycle: main.myte -> self.myte -> self.myte. Line: 0, column: 1"},
+		{`import "./main"`, "ERROR: import cycle: main.myte -> main.myte. Line: 0, column: 1"},
+		{`import "./broken"`,
+			"ERROR: cannot parse broken.myte: expected next token to be: IDENT, got: = instead. Line: 0, column: 5. Line: 0, column: 1"},
+		{`import "./fails"`, "ERROR: division by zero (fails.myte, line 1, column 15). Line: 0, column: 1"},
+		{`try { import "./nested" } catch e { e.kind }`, "zero_division"},
+		{"\n import \"./nested\"",
+			"ERROR: division by zero (fails.myte, line 1, column 15) (nested.myte, line 0, column 1). Line: 1, column: 2"},
+		{`import "./plain"; plain.other`, "ERROR: module plain has no member: other. Line: 0, column: 25"},
+		{`var n = 1; n.value`, "ERROR: cannot get member value of int. Line: 0, column: 14"},
+		{`import "./plain"; plain = 2`, "ERROR: cannot assign to constant: plain. Line: 0, column: 19"},
+	}
+
+	for _, tt := range tests {
+		if err := os.WriteFile("main.myte", []byte(tt.input), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		result := runFile(t, NewModules("main.myte"), "main.myte")
+		if result == nil || result.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong.\nexpected=%q\ngot=     %v", tt.input, tt.expected, result)
+		}
+	}
+}
+
+// A module runs within the limits of the program that imports it, and going over
+// them there is not something the program can catch either
+func TestLimitInsideModule(t *testing.T) {
+	writeFiles(t, map[string]string{
+		"loop.myte": "for true { 1 }",
+		"uses.myte": "import \"./loop\";",
+	})
+
+	for _, input := range []string{`try { import "./loop" } catch e { e.kind }`, `try { import "./uses" } catch e { e.kind }`} {
+		program := parser.New(lexer.New(input)).ParseProgram()
+		env := object.NewEnvironment()
+		env.SetImporter(NewModules("main.myte").Importer())
+		env.SetLimits(object.Limits{MaxSteps: 1000})
+
+		err, ok := Eval(program, env).(*object.Error)
+		if !ok || err.Kind != STEP_LIMIT_KIND {
+			t.Errorf("%q - expected a step limit error, got=%v", input, err)
+		}
+	}
+}
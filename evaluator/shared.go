@@ -0,0 +1,101 @@
+package evaluator
+
+import (
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/object"
+	"github.com/santos-404/myte/token"
+)
+
+/*
+The vm runs the same programs as the evaluator, so instead of writing every operator
+twice it borrows them from here. That way both engines can't disagree on what 7 // -2
+is, or on the error you get for "a" - 1. The token only tells where the error is.
+*/
+
+func IsTruthy(obj object.Object) bool {
+	return isTruthy(obj)
+}
+
+func InfixOperation(tok token.Token, operator string, left, right object.Object) object.Object {
+	return evalInfixOperation(tok, operator, left, right)
+}
+
+// Only "!" and "-". The increments have their own function, they work on numbers only
+func PrefixOperation(tok token.Token, operator string, right object.Object) object.Object {
+	return evalPrefixOperation(tok, operator, right)
+}
+
+// The pairs go one after the other: key, value, key, value...
+func MapOperation(tok token.Token, pairs []object.Object) object.Object {
+	return evalMapOperation(tok, pairs)
+}
+
+func IndexOperation(tok token.Token, left, index object.Object) object.Object {
+	return evalIndexOperation(tok, left, index)
+}
+
+func MemberOperation(tok token.Token, obj object.Object, member string) object.Object {
+	return evalMemberOperation(tok, obj, member)
+}
+
+// ApplyFunction calls a function (or a builtin) from outside of any program, as if
+// it was called at the place the token points to
+func ApplyFunction(tok token.Token, fn object.Object, args []object.Object, usage *object.Usage) object.Object {
+	return applyFunction(&ast.CallExpression{Token: tok}, fn, args, usage)
+}
+
+// Builtins that fail in a way the program can deal with give back an error value
+func CallBuiltin(usage *object.Usage, tok token.Token, builtin *object.Builtin, args []object.Object) object.Object {
+	return callBuiltinWithin(usage, tok, builtin, args)
+}
+
+func UncaughtError(err *object.ErrorValue) *object.Error {
+	return uncaughtError(err)
+}
+
+// What a catch gets when the error happened in the vm
+func CaughtError(err *object.Error) *object.ErrorValue {
+	return caughtError(err)
+}
+
+func ThrowValue(tok token.Token, value object.Object) *object.Error {
+	return throwValue(tok, value)
+}
+
+// The statement is "break" or "continue"
+func OutsideLoopError(tok token.Token, statement string) *object.Error {
+	return outsideLoopError(tok, statement)
+}
+
+// The limits work the same on both engines, only what a step is changes
+func Step(usage *object.Usage, tok token.Token) *object.Error {
+	return step(usage, tok)
+}
+
+func InfixOperationWithin(usage *object.Usage, tok token.Token, operator string, left, right object.Object) object.Object {
+	return infixOperationWithin(usage, tok, operator, left, right)
+}
+
+func CallDepthError(tok token.Token, limit int) *object.Error {
+	return callDepthError(tok, limit)
+}
+
+func IsLimitError(obj object.Object) bool {
+	return isLimitError(obj)
+}
+
+func IncrementOperation(tok token.Token, operator string, right object.Object) object.Object {
+	return evalIncrementOperation(tok, operator, right)
+}
+
+func NativeBool(value bool) *object.Boolean {
+	return nativeBoolToBooleanObject(value)
+}
+
+func DecodeString(raw string) string {
+	return decodeString(raw)
+}
+
+func ImportErrorKind(err error) string {
+	return importErrorKind(err)
+}
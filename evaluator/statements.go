@@ -0,0 +1,132 @@
+package evaluator
+
+import (
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/object"
+)
+
+func evalProgram(program *ast.Program, env *object.Environment) object.Object {
+	var result object.Object
+
+	for _, statement := range program.Statements {
+		result = Eval(statement, env)
+
+		switch result := result.(type) {
+		case *object.ReturnValue:
+			if call, ok := result.Value.(*tailCall); ok {
+				return applyFunction(call.node, call.function, call.args, env.Usage())
+			}
+			// A ? outside of any function has nobody to give the error to
+			if err, ok := result.Value.(*object.ErrorValue); ok && result.Propagated {
+				return uncaughtError(err)
+			}
+			return result.Value
+		case *object.Error:
+			return result
+		case *object.Break, *object.Continue:
+			return loopControlError(result)
+		}
+	}
+
+	return result
+}
+
+func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
+	var result object.Object
+
+	for _, statement := range block.Statements {
+		result = Eval(statement, env)
+
+		// We don't unwrap the return value here; the function call (or the loop) will do it
+		if isInterruption(result) {
+			return result
+		}
+	}
+
+	return result
+}
+
+func evalVarStatement(vs *ast.VarStatement, env *object.Environment) object.Object {
+	if err := checkDeclarable(vs.Name); err != nil {
+		return err
+	}
+
+	val := Eval(vs.Value, env)
+	if isError(val) {
+		return val
+	}
+	if val == nil {
+		val = NIL
+	}
+
+	env.Set(vs.Name.Value, val)
+	return nil
+}
+
+func evalConstStatement(cs *ast.ConstStatement, env *object.Environment) object.Object {
+	if err := checkDeclarable(cs.Name); err != nil {
+		return err
+	}
+
+	val := Eval(cs.Value, env)
+	if isError(val) {
+		return val
+	}
+	if val == nil {
+		val = NIL
+	}
+
+	env.SetConst(cs.Name.Value, val)
+	return nil
+}
+
+func evalReturnStatement(rs *ast.ReturnStatement, env *object.Environment) object.Object {
+	if call, ok := rs.ReturnValue.(*ast.CallExpression); ok {
+		return evalTailCall(call, env)
+	}
+
+	val := Eval(rs.ReturnValue, env)
+	if isError(val) {
+		return val
+	}
+	if val == nil {
+		val = NIL
+	}
+	return &object.ReturnValue{Value: val}
+}
+
+func evalThrowStatement(ts *ast.ThrowStatement, env *object.Environment) object.Object {
+	value := Eval(ts.Value, env)
+	if isError(value) {
+		return value
+	}
+	return throwValue(ts.Token, value)
+}
+
+/*
+A call in tail position is not made here. We give back what it needs to be made,
+and the function call that gets it (see applyFunction) makes it in its own loop, so
+the Go stack doesn't grow with every recursive call.
+*/
+type tailCall struct {
+	node     *ast.CallExpression
+	function object.Object
+	args     []object.Object
+}
+
+func (tc *tailCall) Type() object.ObjectType { return object.FUNCTION_OBJ }
+func (tc *tailCall) Inspect() string          { return "tail call" }
+
+func evalTailCall(call *ast.CallExpression, env *object.Environment) object.Object {
+	function := Eval(call.Function, env)
+	if isError(function) {
+		return function
+	}
+
+	args := evalExpressions(call.Arguments, env)
+	if len(args) == 1 && isError(args[0]) {
+		return args[0]
+	}
+
+	return &object.ReturnValue{Value: &tailCall{node: call, function: function, args: args}}
+}
@@ -0,0 +1,69 @@
+package evaluator
+
+import (
+	"sort"
+
+	"github.com/santos-404/myte/object"
+)
+
+/*
+StdModule is a module that comes with the language. It's written in Go, and an
+import with its bare name (like `import "math"`) finds it before any file. Its
+functions are builtins too, so their arguments are checked the same way.
+*/
+type StdModule struct {
+	Name      string
+	Doc       string
+	Functions []*Builtin
+	Values    map[string]object.Object  // The constants it has, like math.pi
+
+	module *object.Module
+}
+
+var stdModules = make(map[string]*StdModule)
+
+// Each module registers itself on the init() of its own file
+func registerStdModule(m *StdModule) {
+	env := object.NewEnvironment()
+	for _, fn := range m.Functions {
+		fn.register(m.Name)
+		env.SetConst(fn.Name, fn.Object())
+	}
+	for name, value := range m.Values {
+		env.SetConst(name, value)
+	}
+
+	m.module = &object.Module{Name: m.Name, Env: env}
+	stdModules[m.Name] = m
+}
+
+// StdModules gives back every standard module, sorted by name
+func StdModules() []*StdModule {
+	var modules []*StdModule
+	for _, m := range stdModules {
+		modules = append(modules, m)
+	}
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Name < modules[j].Name })
+	return modules
+}
+
+func LookupStdModule(name string) (*StdModule, bool) {
+	m, ok := stdModules[name]
+	return m, ok
+}
+
+// They have no state, so every import of the program gets the same one
+func (m *StdModule) Module() *object.Module {
+	return m.module
+}
+
+// The parameters most of them take
+var numberTypes = []object.ObjectType{object.INTEGER_OBJ, object.FLOAT_OBJ}
+
+func numberParameter(name string) BuiltinParameter {
+	return BuiltinParameter{Name: name, Types: numberTypes}
+}
+
+func stringParameter(name string) BuiltinParameter {
+	return BuiltinParameter{Name: name, Types: []object.ObjectType{object.STRING_OBJ}}
+}
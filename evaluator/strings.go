@@ -0,0 +1,313 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/santos-404/myte/object"
+)
+
+/*
+The strings module. It works on the values programs get, not on the literals: the
+quotes are gone by then. Positions and widths are counted in characters, the same
+way len and indexing count them, never in bytes.
+*/
+func init() {
+	registerStdModule(&StdModule{
+		Name: "strings",
+		Doc:  "Text: splitting, joining, searching, replacing and padding",
+		Functions: []*Builtin{
+			{
+				Name: "split",
+				Parameters: []BuiltinParameter{
+					stringParameter("s"),
+					{Name: "sep", Types: []object.ObjectType{object.STRING_OBJ}, Optional: true},
+				},
+				Returns: "array",
+				Doc:     "The pieces of s between every sep. Without sep, it splits on whitespace and drops the empty pieces",
+				fn:      stringsSplit,
+			},
+			{
+				Name: "join",
+				Parameters: []BuiltinParameter{
+					{Name: "values", Types: []object.ObjectType{object.ARRAY_OBJ}},
+					stringParameter("sep"),
+				},
+				Returns: "string",
+				Doc:     "The strings of values with sep between them",
+				fn:      stringsJoin,
+				size:    joinSize,
+			},
+			{
+				Name: "trim",
+				Parameters: []BuiltinParameter{
+					stringParameter("s"),
+					{Name: "chars", Types: []object.ObjectType{object.STRING_OBJ}, Optional: true},
+				},
+				Returns: "string",
+				Doc:     "s without the chars at both ends, or without whitespace when there are none",
+				fn:      stringsTrim,
+			},
+			{
+				Name: "replace",
+				Parameters: []BuiltinParameter{
+					stringParameter("s"),
+					stringParameter("old"),
+					stringParameter("new"),
+					{Name: "count", Types: []object.ObjectType{object.INTEGER_OBJ}, Optional: true},
+				},
+				Returns: "string",
+				Doc:     "s with the first count old replaced by new, or every one of them when there is no count",
+				fn:      stringsReplace,
+			},
+			{
+				Name:       "contains",
+				Parameters: []BuiltinParameter{stringParameter("s"), stringParameter("sub")},
+				Returns:    "bool",
+				Doc:        "Whether sub is somewhere in s",
+				fn:         stringsTest(strings.Contains),
+			},
+			{
+				Name:       "starts_with",
+				Parameters: []BuiltinParameter{stringParameter("s"), stringParameter("prefix")},
+				Returns:    "bool",
+				Doc:        "Whether s begins with prefix",
+				fn:         stringsTest(strings.HasPrefix),
+			},
+			{
+				Name:       "ends_with",
+				Parameters: []BuiltinParameter{stringParameter("s"), stringParameter("suffix")},
+				Returns:    "bool",
+				Doc:        "Whether s ends with suffix",
+				fn:         stringsTest(strings.HasSuffix),
+			},
+			{
+				Name:       "upper",
+				Parameters: []BuiltinParameter{stringParameter("s")},
+				Returns:    "string",
+				Doc:        "s in upper case",
+				fn:         stringsMap(strings.ToUpper),
+			},
+			{
+				Name:       "lower",
+				Parameters: []BuiltinParameter{stringParameter("s")},
+				Returns:    "string",
+				Doc:        "s in lower case",
+				fn:         stringsMap(strings.ToLower),
+			},
+			{
+				Name: "repeat",
+				Parameters: []BuiltinParameter{
+					stringParameter("s"),
+					{Name: "count", Types: []object.ObjectType{object.INTEGER_OBJ}},
+				},
+				Returns: "string",
+				Doc:     "s count times in a row",
+				fn:      stringsRepeat,
+				size:    repeatSize,
+			},
+			{
+				Name:       "index",
+				Parameters: []BuiltinParameter{stringParameter("s"), stringParameter("sub")},
+				Returns:    "int",
+				Doc:        "Where the first sub in s is, or -1 when there is none",
+				fn:         stringsIndex,
+			},
+			{
+				Name: "pad",
+				Parameters: []BuiltinParameter{
+					stringParameter("s"),
+					{Name: "width", Types: []object.ObjectType{object.INTEGER_OBJ}},
+					{Name: "fill", Types: []object.ObjectType{object.STRING_OBJ}, Optional: true},
+				},
+				Returns: "string",
+				Doc:     "s filled up to width characters on the left, or on the right when the width is negative (like %5s and %-5s). The fill is a space when there is none",
+				fn:      stringsPad,
+				size:    padSize,
+			},
+		},
+	})
+}
+
+func stringsTest(f func(s, sub string) bool) func(args []object.Object) (object.Object, error) {
+	return func(args []object.Object) (object.Object, error) {
+		return nativeBoolToBooleanObject(f(stringArg(args, 0), stringArg(args, 1))), nil
+	}
+}
+
+func stringsMap(f func(s string) string) func(args []object.Object) (object.Object, error) {
+	return func(args []object.Object) (object.Object, error) {
+		return &object.String{Value: f(stringArg(args, 0))}, nil
+	}
+}
+
+func stringArg(args []object.Object, i int) string {
+	return args[i].(*object.String).Value
+}
+
+func stringsSplit(args []object.Object) (object.Object, error) {
+	var pieces []string
+	if len(args) == 2 {
+		pieces = strings.Split(stringArg(args, 0), stringArg(args, 1))
+	} else {
+		pieces = strings.Fields(stringArg(args, 0))
+	}
+
+	elements := make([]object.Object, len(pieces))
+	for i, piece := range pieces {
+		elements[i] = &object.String{Value: piece}
+	}
+	return &object.Array{Elements: elements}, nil
+}
+
+func stringsJoin(args []object.Object) (object.Object, error) {
+	values := args[0].(*object.Array).Elements
+	pieces := make([]string, len(values))
+	for i, value := range values {
+		s, ok := value.(*object.String)
+		if !ok {
+			return nil, fmt.Errorf("strings.join takes an array of strings, got %s at index %d", typeOf(value), i)
+		}
+		pieces[i] = s.Value
+	}
+	return &object.String{Value: strings.Join(pieces, stringArg(args, 1))}, nil
+}
+
+// The sizes are worked out before the arguments are checked, so they make do with
+// what they get: whatever is not a string counts as nothing
+
+func joinSize(args []object.Object) int {
+	if len(args) < 2 {
+		return 0
+	}
+	values, _ := args[0].(*object.Array)
+	sep, _ := args[1].(*object.String)
+	if values == nil || sep == nil || len(values.Elements) == 0 {
+		return 0
+	}
+	size := len(sep.Value) * (len(values.Elements) - 1)
+	for _, value := range values.Elements {
+		if s, ok := value.(*object.String); ok {
+			size += len(s.Value)
+		}
+	}
+	return size
+}
+
+func stringsTrim(args []object.Object) (object.Object, error) {
+	if len(args) == 2 {
+		return &object.String{Value: strings.Trim(stringArg(args, 0), stringArg(args, 1))}, nil
+	}
+	return &object.String{Value: strings.TrimSpace(stringArg(args, 0))}, nil
+}
+
+func stringsReplace(args []object.Object) (object.Object, error) {
+	count := -1
+	if len(args) == 4 {
+		count = int(args[3].(*object.Integer).Value)
+		if count < 0 {
+			return nil, fmt.Errorf("the count of strings.replace cannot be negative, got %d", count)
+		}
+	}
+	return &object.String{Value: strings.Replace(stringArg(args, 0), stringArg(args, 1), stringArg(args, 2), count)}, nil
+}
+
+// The biggest string repeat is allowed to make, so a typo cannot take the whole memory
+const MAX_STRING_LENGTH = 1 << 30
+
+func stringsRepeat(args []object.Object) (object.Object, error) {
+	s, count := stringArg(args, 0), args[1].(*object.Integer).Value
+	if count < 0 {
+		return nil, fmt.Errorf("the count of strings.repeat cannot be negative, got %d", count)
+	}
+	if len(s) > 0 && count > int64(MAX_STRING_LENGTH/len(s)) {
+		return nil, fmt.Errorf("strings.repeat would make a string too long")
+	}
+	return &object.String{Value: strings.Repeat(s, int(count))}, nil
+}
+
+func repeatSize(args []object.Object) int {
+	if len(args) < 2 {
+		return 0
+	}
+	s, _ := args[0].(*object.String)
+	count, _ := args[1].(*object.Integer)
+	if s == nil || count == nil || count.Value <= 0 || len(s.Value) == 0 {
+		return 0
+	}
+	if count.Value > int64(math.MaxInt/len(s.Value)) {
+		return math.MaxInt
+	}
+	return len(s.Value) * int(count.Value)
+}
+
+func stringsIndex(args []object.Object) (object.Object, error) {
+	s := stringArg(args, 0)
+	i := strings.Index(s, stringArg(args, 1))
+	if i < 0 {
+		return &object.Integer{Value: -1}, nil
+	}
+	return &object.Integer{Value: int64(utf8.RuneCountInString(s[:i]))}, nil
+}
+
+func padSize(args []object.Object) int {
+	if len(args) < 2 {
+		return 0
+	}
+	s, _ := args[0].(*object.String)
+	width, _ := args[1].(*object.Integer)
+	if s == nil || width == nil {
+		return 0
+	}
+	fill := 1
+	if len(args) == 3 {
+		if f, ok := args[2].(*object.String); ok && utf8.RuneCountInString(f.Value) == 1 {
+			fill = len(f.Value)
+		}
+	}
+
+	missing := width.Value
+	if missing < 0 {
+		missing = -missing
+	}
+	missing -= int64(utf8.RuneCountInString(s.Value))
+	if missing <= 0 {
+		return len(s.Value)
+	}
+	if missing > int64(math.MaxInt/4 - len(s.Value)) {
+		return math.MaxInt
+	}
+	return len(s.Value) + int(missing)*fill
+}
+
+func stringsPad(args []object.Object) (object.Object, error) {
+	s, width := stringArg(args, 0), args[1].(*object.Integer).Value
+	fill := " "
+	if len(args) == 3 {
+		fill = stringArg(args, 2)
+		if utf8.RuneCountInString(fill) != 1 {
+			return nil, fmt.Errorf("the fill of strings.pad must be one character, got %q", fill)
+		}
+	}
+
+	left := width > 0
+	if width < 0 {
+		width = -width
+	}
+	missing := width - int64(utf8.RuneCountInString(s))
+	if missing <= 0 {
+		return &object.String{Value: s}, nil
+	}
+	// The fill can take up to 4 bytes, so it's the bytes of the result that count
+	if missing > int64((MAX_STRING_LENGTH-len(s))/len(fill)) {
+		return nil, fmt.Errorf("strings.pad would make a string too long")
+	}
+
+	padding := strings.Repeat(fill, int(missing))
+	if left {
+		return &object.String{Value: padding + s}, nil
+	}
+	return &object.String{Value: s + padding}, nil
+}
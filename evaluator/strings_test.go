@@ -0,0 +1,77 @@
+package evaluator
+
+import (
+	"testing"
+)
+
+func TestStringsModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"strings.split('a,b,,c', ',')", "['a', 'b', '', 'c']"},
+		{"strings.split('  one two\\tthree ')", "['one', 'two', 'three']"},
+		{`strings.split("one\ntwo\n", "\n")`, "['one', 'two', '']"},
+		{`strings.join(['a', 'b'], "\n")`, "a\nb"},
+		{`strings.split(strings.join(['a', 'b'], '\t'), "\t")`, "['a', 'b']"},
+		{"strings.split(' one  two ')", "['one', 'two']"},
+		{"strings.split('héllo', '')", "['h', 'é', 'l', 'l', 'o']"},
+		{"strings.join(['a', 'b', 'c'], ', ')", "a, b, c"},
+		{"strings.join([], '-')", ""},
+		{"strings.join(strings.split('a b c', ' '), '+')", "a+b+c"},
+		{"strings.trim('  hi  ')", "hi"},
+		{"strings.trim('--hi-', '-')", "hi"},
+		{"strings.replace('aaa', 'a', 'b')", "bbb"},
+		{"strings.replace('aaa', 'a', 'b', 2)", "bba"},
+		{"strings.replace('aaa', 'a', 'b', 0)", "aaa"},
+		{"strings.contains('hello', 'ell')", "true"},
+		{"strings.contains('hello', 'z')", "false"},
+		{"strings.starts_with('hello', 'he')", "true"},
+		{"strings.ends_with('hello', 'he')", "false"},
+		{"strings.upper('héllo')", "HÉLLO"},
+		{"strings.lower('HeLLo')", "hello"},
+		{"strings.repeat('ab', 3)", "ababab"},
+		{"strings.repeat('ab', 0)", ""},
+		{"strings.index('héllo', 'l')", "2"},
+		{"strings.index('hello', 'z')", "-1"},
+		{"strings.pad('7', 3, '0')", "007"},
+		{"strings.pad('ab', -4) + '|'", "ab  |"},
+		{"strings.pad('é', 3, '·')", "··é"},
+		{"strings.pad('hello', 2)", "hello"},
+		// The quotes of the literals are not part of the values
+		{"strings.upper(\"it's\")", "IT'S"},
+		{"len(strings.trim(' \"a\" '))", "3"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, "import \"strings\"; "+tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong. expected=%q, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+func TestStringsModuleErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"strings.upper(1)", "ERROR: argument 1 of strings.upper must be string, got int. Line: 0, column: 32"},
+		{"strings.join('abc', '')", "ERROR: argument 1 of strings.join must be array, got string. Line: 0, column: 31"},
+		{"strings.join(['a', 1], '')", "ERROR: strings.join takes an array of strings, got int at index 1. Line: 0, column: 31"},
+		{"strings.repeat('a', -1)", "ERROR: the count of strings.repeat cannot be negative, got -1. Line: 0, column: 33"},
+		{"strings.repeat('ab', 1000000000000)", "ERROR: strings.repeat would make a string too long. Line: 0, column: 33"},
+		{"strings.pad('a', 400000000, '€')", "ERROR: strings.pad would make a string too long. Line: 0, column: 30"},
+		{"strings.pad('a', -400000000, '€')", "ERROR: strings.pad would make a string too long. Line: 0, column: 30"},
+		{"strings.replace('a', 'a', 'b', -1)", "ERROR: the count of strings.replace cannot be negative, got -1. Line: 0, column: 34"},
+		{"strings.pad('a', 3, '--')", "ERROR: the fill of strings.pad must be one character, got \"--\". Line: 0, column: 30"},
+		{"strings.split()", "ERROR: wrong number of arguments to strings.split: want=1 or 2, got=0. Line: 0, column: 32"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, "import \"strings\"; "+tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong.\nexpected=%q\ngot=     %v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/santos-404/myte/object"
+)
+
+func TestTraces(t *testing.T) {
+	input := `const divide = fn(a, b) { a / b };
+const half = fn(n) { divide(n, 0) };
+const run = fn(f) { f(10) };
+run(fn(n) { half(n) })`
+
+	err, ok := testEval(t, input).(*object.Error)
+	if !ok {
+		t.Fatalf("the program didn't fail")
+	}
+
+	expected := []object.TraceFrame{
+		{Function: "divide", FnLine: 0, FnColumn: 16, Line: 1, Column: 28},
+		{Function: "half", FnLine: 1, FnColumn: 14, Line: 3, Column: 17},
+		{Function: "", FnLine: 3, FnColumn: 5, Line: 2, Column: 22},
+		{Function: "run", FnLine: 2, FnColumn: 13, Line: 3, Column: 4},
+	}
+	if len(err.Trace) != len(expected) {
+		t.Fatalf("wrong number of frames. expected=%d, got=%d (%v)", len(expected), len(err.Trace), err.Trace)
+	}
+	for i, frame := range err.Trace {
+		if frame != expected[i] {
+			t.Errorf("trace[%d] wrong. expected=%+v, got=%+v", i, expected[i], frame)
+		}
+	}
+	if name := err.Trace[2].Name(); name != "<anonymous fn> (line 3, column 5)" {
+		t.Errorf("the anonymous fn has the wrong name. got=%q", name)
+	}
+}
+
+func TestTracesFromCatch(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"try { 1 / 0 } catch e { e.trace }", "[]"},
+		{"const f = fn() { 1 / 0 }; try { f() } catch e { e.trace }", "[{'function': 'f', 'line': 0, 'column': 34}]"},
+		// Only the calls inside of the try are there
+		{"const f = fn() { 1 / 0 }; const g = fn() { try { f() } catch e { e.trace } }; g()",
+			"[{'function': 'f', 'line': 0, 'column': 51}]"},
+		// Throwing it again adds the calls it goes out of after that
+		{`
+			const f = fn() { 1 / 0 };
+			const g = fn() { try { f() } catch e { throw e } };
+			try { g() } catch e { len(e.trace) }
+		`, "2"},
+		// A tail call takes the place of the caller, so the caller is not there
+		{"const f = fn() { 1 / 0 }; const g = fn() { return f() }; try { g() } catch e { e.trace[0]['function'] }", "f"},
+		{"try { fn() { 1 / 0 }() } catch e { e.trace[0]['function'] }", "<anonymous fn> (line 0, column 7)"},
+		{"error('made').trace", "[]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong. expected=%q, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
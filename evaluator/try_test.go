@@ -0,0 +1,123 @@
+package evaluator
+
+import (
+	"testing"
+)
+
+func TestTryCatch(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"try { 1 } catch e { 2 }", "1"},
+		{"try { 1 / 0 } catch e { 2 }", "2"},
+		{"try { 1 / 0 } catch { 'no name' }", "no name"},
+		{"var x = 1 + try { [][0] } catch e { 10 }; x", "11"},
+		{"try { 1 / 0; 'not here' } catch e { e.message }", "division by zero"},
+		{"try { 1 / 0 } catch e { [e.kind, e.line, e.column] }", "['zero_division', 0, 9]"},
+		{"try { [1][3] } catch e { e.kind }", "index"},
+		{"try { nope } catch e { e.kind }", "name"},
+		{"try { 'a' - 1 } catch e { e.kind }", "type"},
+		{"try { len(1) } catch e { e.kind }", "type"},
+		{"try { fn(a) { a }() } catch e { e.kind }", "arguments"},
+		{"try { const c = 1; c = 2 } catch e { e.kind }", "assignment"},
+		{"try { assert(false) } catch e { e.kind }", "assertion"},
+		{"try { error('made').code } catch e { e.kind }", "member"},
+		{"import 'math'; try { math.tau } catch e { e.kind }", "member"},
+		{"import 'math'; try { math.pow(2) } catch e { e.kind }", "arguments"},
+		{"import 'strings'; try { strings.repeat('a', -1) } catch e { e.kind }", "runtime"},
+		// Errors from deep inside of calls are caught too
+		{`
+			const deep = fn(n) { if n == 0 { return 1 / 0 } deep(n - 1) + 1 };
+			try { deep(10) } catch e { e.kind }
+		`, "zero_division"},
+		{"const f = fn(n) { try { return g(n) } catch e { 'caught' } }; const g = fn(n) { 1 / n }; [f(0), f(1)]",
+			"['caught', 1.0]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong. expected=%q, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+func TestThrow(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"try { throw 'bad' } catch e { [e.message, e.kind, e.line, e.column] }", "['bad', 'error', 0, 7]"},
+		{"try { throw error('bad') } catch e { e }", "error('bad')"},
+		// An error keeps where it was made, even when it's thrown somewhere else
+		{"var made = error('x');\n try { throw made } catch e { [e.line, e.column] }", "[0, 17]"},
+		{"try { throw 1 } catch e { [e.kind, e.message] }", "['type', 'throw takes an error or a string, got int']"},
+		// Throwing what was caught doesn't change it
+		{"try { try { 1 / 0 } catch e { throw e } } catch e { [e.kind, e.column] }", "['zero_division', 15]"},
+		{"const f = fn() { throw 'from f' }; try { f() } catch e { e.message }", "from f"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong. expected=%q, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+func TestFinally(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var log = []; try { log = log + [1] } finally { log = log + [2] }; log", "[1, 2]"},
+		{"var log = []; try { 1 / 0 } catch e { log = log + ['c'] } finally { log = log + ['f'] }; log", "['c', 'f']"},
+		// The value is the one of the body or the catch, never the finally
+		{"try { 1 } finally { 2 }", "1"},
+		{"try { 1 / 0 } catch e { 2 } finally { 3 }", "2"},
+		{"var log = []; const f = fn() { try { return 1 } finally { log = log + ['f'] } }; [f(), log]", "[1, ['f']]"},
+		{"const f = fn() { try { return 1 } finally { return 2 } }; f()", "2"},
+		{"var log = []; for true { try { break } finally { log = log + ['f'] } }; log", "['f']"},
+		{`
+			var log = [];
+			var i = 0;
+			for i < 3 { i += 1; try { if i == 2 { continue } log = log + [i] } finally { log = log + ['f'] } };
+			log
+		`, "[1, 'f', 'f', 3, 'f']"},
+		{"var log = []; try { try { 1 / 0 } finally { log = log + ['inner'] } } catch e { log = log + [e.kind] }; log",
+			"['inner', 'zero_division']"},
+		// A catch that fails still runs the finally
+		{"var log = []; try { try { 1 / 0 } catch e { throw 'again' } finally { log = log + ['f'] } } catch e { [log, e.message] }",
+			"[['f'], 'again']"},
+		{"var log = []; const f = fn() { try { int('x')? } finally { log = log + ['f'] } }; [f(), log]",
+			"[error('cannot convert \"x\" to int'), ['f']]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong. expected=%q, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+func TestUncaughtThrow(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"throw 'stop'", "ERROR: stop. Line: 0, column: 1"},
+		{"\nconst e = error('made');\nthrow e", "ERROR: made. Line: 1, column: 16"},
+		{"throw 1", "ERROR: throw takes an error or a string, got int. Line: 0, column: 1"},
+		{"try { 1 / 0 } finally { 2 }", "ERROR: division by zero. Line: 0, column: 9"},
+		{"try { 1 } catch error { 2 }", "ERROR: cannot redeclare builtin: error. Line: 0, column: 17"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - result wrong.\nexpected=%q\ngot=     %v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
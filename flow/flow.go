@@ -0,0 +1,228 @@
+package flow
+
+import (
+	"fmt"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/checker"
+	"github.com/santos-404/myte/token"
+)
+
+/*
+Check looks at how control goes through the program and each of its functions. It
+warns about code that can never run, loops that can never end, and functions that
+return a value on some paths but get to their end without one on others.
+*/
+func Check(program *ast.Program) []checker.Finding {
+	var findings []checker.Finding
+	warn := func(tok token.Token, format string, a ...interface{}) {
+		findings = append(findings, checker.Finding{
+			Severity: checker.Warning,
+			Message:  fmt.Sprintf(format, a...),
+			Line:     tok.Line,
+			Column:   tok.Column,
+		})
+	}
+
+	check := func(g *Graph, fn *ast.FunctionLiteral) {
+		reachable := g.Reachable()
+
+		// Only where the dead code starts is reported, not every block after it
+		for _, block := range g.Blocks {
+			if reachable[block] || len(block.Predecessors) > 0 {
+				continue
+			}
+			if block.parent != nil && !reachable[block.parent] {
+				continue
+			}
+			if stmt := firstStatement(block); stmt != nil {
+				warn(statementToken(stmt), "unreachable code")
+			}
+		}
+
+		for _, l := range g.loops {
+			if reachable[l.header] && isTrue(l.node.Condition) && !anyReachable(l.exits, reachable) {
+				warn(l.node.Token, "this loop never ends, nothing breaks out of it")
+			}
+		}
+
+		if fn != nil && anyReachable(g.returns, reachable) {
+			for _, end := range g.ends {
+				if reachable[end] && !leavesValue(end) {
+					warn(fn.Token, "not every path of this function returns a value")
+					break
+				}
+			}
+		}
+	}
+
+	check(Build(program.Statements), nil)
+	for _, fn := range functions(program) {
+		if fn.Body != nil {
+			check(Build(fn.Body.Statements), fn)
+		}
+	}
+
+	checker.SortFindings(findings)
+	return findings
+}
+
+func anyReachable(blocks []*Block, reachable map[*Block]bool) bool {
+	for _, block := range blocks {
+		if reachable[block] {
+			return true
+		}
+	}
+	return false
+}
+
+// A block gives a value when it ends with an expression. Ifs and fors don't get
+// here when they have one; what ends up here is an if without else, or a loop.
+func leavesValue(block *Block) bool {
+	if len(block.Statements) == 0 {
+		return false
+	}
+	stmt, ok := block.Statements[len(block.Statements)-1].(*ast.ExpressionStatement)
+	if !ok {
+		return false
+	}
+	switch stmt.Expression.(type) {
+	case nil, *ast.IfExpression, *ast.ForExpression, *ast.TryExpression, *ast.CommentExpression:
+		return false
+	}
+	return true
+}
+
+// Comments are statements too, but nobody wants to hear they are unreachable
+func firstStatement(block *Block) ast.Statement {
+	for _, stmt := range block.Statements {
+		if exp, ok := stmt.(*ast.ExpressionStatement); ok {
+			if _, isComment := exp.Expression.(*ast.CommentExpression); isComment {
+				continue
+			}
+		}
+		return stmt
+	}
+	return nil
+}
+
+// The token of an expression statement is the first one of the expression, which is
+// where we want to point
+func statementToken(stmt ast.Statement) token.Token {
+	switch stmt := stmt.(type) {
+	case *ast.ExpressionStatement:
+		return stmt.Token
+	case *ast.VarStatement:
+		if stmt != nil {
+			return stmt.Token
+		}
+	case *ast.ConstStatement:
+		if stmt != nil {
+			return stmt.Token
+		}
+	case *ast.ReturnStatement:
+		return stmt.Token
+	case *ast.ThrowStatement:
+		return stmt.Token
+	case *ast.BreakStatement:
+		return stmt.Token
+	case *ast.ContinueStatement:
+		return stmt.Token
+	case *ast.BlockStatement:
+		return stmt.Token
+	}
+	return token.Token{}
+}
+
+// functions finds every function literal of the program, the nested ones too
+func functions(program *ast.Program) []*ast.FunctionLiteral {
+	var found []*ast.FunctionLiteral
+
+	var statements func([]ast.Statement)
+	var expression func(ast.Expression)
+	statements = func(list []ast.Statement) {
+		for _, stmt := range list {
+			switch stmt := stmt.(type) {
+			case *ast.ExpressionStatement:
+				expression(stmt.Expression)
+			case *ast.VarStatement:
+				if stmt != nil {
+					expression(stmt.Value)
+				}
+			case *ast.ConstStatement:
+				if stmt != nil {
+					expression(stmt.Value)
+				}
+			case *ast.ReturnStatement:
+				expression(stmt.ReturnValue)
+			case *ast.ThrowStatement:
+				expression(stmt.Value)
+			case *ast.BlockStatement:
+				if stmt != nil {
+					statements(stmt.Statements)
+				}
+			}
+		}
+	}
+	expression = func(exp ast.Expression) {
+		switch exp := exp.(type) {
+		case *ast.PrefixExpression:
+			expression(exp.Right)
+		case *ast.InfixExpression:
+			expression(exp.Left)
+			expression(exp.Right)
+		case *ast.AssignExpression:
+			expression(exp.Value)
+		case *ast.CallExpression:
+			expression(exp.Function)
+			for _, arg := range exp.Arguments {
+				expression(arg)
+			}
+		case *ast.MemberExpression:
+			expression(exp.Object)
+		case *ast.ArrayLiteral:
+			for _, element := range exp.Elements {
+				expression(element)
+			}
+		case *ast.MapLiteral:
+			for i, key := range exp.Keys {
+				expression(key)
+				expression(exp.Values[i])
+			}
+		case *ast.IndexExpression:
+			expression(exp.Left)
+			expression(exp.Index)
+		case *ast.PropagateExpression:
+			expression(exp.Value)
+		case *ast.TryExpression:
+			statements(exp.Body.Statements)
+			if exp.Catch != nil {
+				statements(exp.Catch.Statements)
+			}
+			if exp.Finally != nil {
+				statements(exp.Finally.Statements)
+			}
+		case *ast.IfExpression:
+			expression(exp.Condition)
+			if exp.Consequence != nil {
+				statements(exp.Consequence.Statements)
+			}
+			if exp.Alternative != nil {
+				statements(exp.Alternative.Statements)
+			}
+		case *ast.ForExpression:
+			expression(exp.Condition)
+			if exp.Body != nil {
+				statements(exp.Body.Statements)
+			}
+		case *ast.FunctionLiteral:
+			found = append(found, exp)
+			if exp.Body != nil {
+				statements(exp.Body.Statements)
+			}
+		}
+	}
+
+	statements(program.Statements)
+	return found
+}
@@ -0,0 +1,102 @@
+package flow
+
+import (
+	"testing"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/lexer"
+	"github.com/santos-404/myte/parser"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has errors for %q: %v", input, p.Errors())
+	}
+	return program
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"fn(a) { return a; a + 1 };", []string{"warning: unreachable code. Line: 0, column: 19"}},
+		{"fn(a) { return a;\n # done\n var b = 1;\n b };", []string{"warning: unreachable code. Line: 2, column: 2"}},
+		{"fn(a) { return a; # just a comment\n };", nil},
+		{"for x { break; x = 1; }", []string{"warning: unreachable code. Line: 0, column: 16"}},
+		{"for x { continue; x }", []string{"warning: unreachable code. Line: 0, column: 19"}},
+		// The whole dead part is one warning, wherever it nests
+		{"fn(a) { return a; if a { return 1; a } a };", []string{"warning: unreachable code. Line: 0, column: 19"}},
+		{"fn(a) { if a { return 1; } else { return 2; } a };", []string{"warning: unreachable code. Line: 0, column: 47"}},
+		{"fn(a) { if a { return 1; } a };", nil},
+		{"for true { 1 }; 2", []string{
+			"warning: this loop never ends, nothing breaks out of it. Line: 0, column: 1",
+			"warning: unreachable code. Line: 0, column: 17",
+		}},
+		{"for true { if x { break; } }; 2", nil},
+		{"fn() { for true { return 1; } };", nil},
+		{"for true { fn() { return 1; }; for x { break; } }", []string{
+			"warning: this loop never ends, nothing breaks out of it. Line: 0, column: 1",
+		}},
+		{"fn(n) { if n < 0 { return -1; } };", []string{
+			"warning: not every path of this function returns a value. Line: 0, column: 1",
+		}},
+		{"fn(n) { if n < 0 { return -1; } var x = n; };", []string{
+			"warning: not every path of this function returns a value. Line: 0, column: 1",
+		}},
+		{"fn(n) { for n { return 1; } };", []string{
+			"warning: not every path of this function returns a value. Line: 0, column: 1",
+		}},
+		{"fn(n) { if n < 0 { return -1; } n };", nil},
+		{"fn(n) { if n { return 1; } else { 2 } };", nil},
+		{"fn(n) { if n { return 1; } else { } };", []string{
+			"warning: not every path of this function returns a value. Line: 0, column: 1",
+		}},
+		{"fn(n) { if n { 1 } };", nil},
+		{"fn(n) { var x = n; };", nil},
+		{"fn(a) { throw 'no'; a };", []string{"warning: unreachable code. Line: 0, column: 21"}},
+		{"fn(a) { try { return a; } catch e { return 0; } a };", []string{"warning: unreachable code. Line: 0, column: 49"}},
+		{"fn(a) { try { return a; } finally { 1 } a };", []string{"warning: unreachable code. Line: 0, column: 41"}},
+		{"fn(a) { try { f(); } catch e { return 0; } a };", nil},
+		{"for true { try { break; } finally { 1 } }", nil},
+		{"fn(n) { fn() { if n { return 1; } }; 2 };", []string{
+			"warning: not every path of this function returns a value. Line: 0, column: 9",
+		}},
+	}
+
+	for _, tt := range tests {
+		findings := Check(parse(t, tt.input))
+
+		if len(findings) != len(tt.expected) {
+			t.Errorf("%q - wrong number of findings. expected=%q, got=%q", tt.input, tt.expected, findings)
+			continue
+		}
+		for i, finding := range findings {
+			if finding.String() != tt.expected[i] {
+				t.Errorf("%q - findings[%d] wrong. expected=%q, got=%q", tt.input, i, tt.expected[i], finding)
+			}
+		}
+	}
+}
+
+func TestGraph(t *testing.T) {
+	g := Build(parse(t, "var i = 0; for i < 3 { if i == 1 { continue; } i += 1; } i").Statements)
+
+	// entry, var, loop header, if, then, the rest of the body, after the loop
+	if len(g.Blocks) != 7 {
+		t.Fatalf("wrong number of blocks. want=7, got=%d", len(g.Blocks))
+	}
+	header := g.Blocks[2]
+	if len(header.Predecessors) != 3 {
+		t.Errorf("the loop header should come from before the loop, the end of the body and the continue. got=%d",
+			len(header.Predecessors))
+	}
+	if len(g.Reachable()) != len(g.Blocks)+1 {
+		t.Errorf("every block should be reachable, the exit too. got=%d", len(g.Reachable()))
+	}
+	if len(g.Exit.Predecessors) != 1 || g.Exit.Predecessors[0] != g.Blocks[6] {
+		t.Errorf("only the block after the loop should get to the exit")
+	}
+}
@@ -0,0 +1,213 @@
+package flow
+
+import (
+	"github.com/santos-404/myte/ast"
+)
+
+// Block is a run of statements that always go one after the other. Control only
+// gets in at the top and leaves at the bottom, to one of the Successors.
+type Block struct {
+	Statements   []ast.Statement
+	Successors   []*Block
+	Predecessors []*Block
+
+	parent *Block  // The block with the if or for this one is part of
+}
+
+// Graph is the flow of a function body, or of the whole program. Returns go to Exit,
+// and so does the end of the body when it's reached.
+type Graph struct {
+	Entry  *Block
+	Exit   *Block
+	Blocks []*Block  // In the order they are written. Exit is not one of them.
+
+	returns []*Block  // The ones that end with a return
+	ends    []*Block  // The ones that get to the end of the body
+	loops   []*loop
+}
+
+type loop struct {
+	node   *ast.ForExpression
+	header *Block    // Where the condition is checked; every iteration goes back here
+	breaks []*Block
+	exits  []*Block  // Breaks, and returns from anywhere inside of it
+}
+
+type builder struct {
+	graph *Graph
+	loops []*loop  // The ones we are in, the innermost is the last
+}
+
+// Build makes the graph of a list of statements. Functions found inside of them are
+// not followed, each one has a graph of its own.
+func Build(statements []ast.Statement) *Graph {
+	g := &Graph{Exit: &Block{}}
+	b := &builder{graph: g}
+
+	g.Entry = b.newBlock(nil)
+	g.ends = b.statements([]*Block{g.Entry}, nil, statements)
+	for _, end := range g.ends {
+		link(end, g.Exit)
+	}
+	return g
+}
+
+// Reachable gives back every block that can run, starting from the entry
+func (g *Graph) Reachable() map[*Block]bool {
+	reachable := make(map[*Block]bool)
+	pending := []*Block{g.Entry}
+	for len(pending) > 0 {
+		block := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if reachable[block] {
+			continue
+		}
+		reachable[block] = true
+		pending = append(pending, block.Successors...)
+	}
+	return reachable
+}
+
+func (b *builder) newBlock(parent *Block, predecessors ...*Block) *Block {
+	block := &Block{parent: parent}
+	for _, predecessor := range predecessors {
+		link(predecessor, block)
+	}
+	b.graph.Blocks = append(b.graph.Blocks, block)
+	return block
+}
+
+func link(from, to *Block) {
+	for _, successor := range from.Successors {
+		if successor == to {
+			return
+		}
+	}
+	from.Successors = append(from.Successors, to)
+	to.Predecessors = append(to.Predecessors, from)
+}
+
+/*
+statements adds the statements after the open blocks, the ones control comes from,
+and gives back the blocks that are open after them. A statement that comes when
+nothing is open (after a return, say) starts a block nobody can get to.
+*/
+func (b *builder) statements(open []*Block, parent *Block, statements []ast.Statement) []*Block {
+	var current *Block
+
+	for _, stmt := range statements {
+		if current == nil {
+			current = b.newBlock(parent, open...)
+			open = nil
+		}
+		current.Statements = append(current.Statements, stmt)
+
+		switch stmt := stmt.(type) {
+		case *ast.ReturnStatement:
+			link(current, b.graph.Exit)
+			b.graph.returns = append(b.graph.returns, current)
+			for _, l := range b.loops {
+				l.exits = append(l.exits, current)
+			}
+			current = nil
+		case *ast.BreakStatement:
+			if l := b.innermostLoop(); l != nil {
+				l.breaks = append(l.breaks, current)
+				l.exits = append(l.exits, current)
+			}
+			current = nil
+		case *ast.ContinueStatement:
+			if l := b.innermostLoop(); l != nil {
+				link(current, l.header)
+			}
+			current = nil
+		case *ast.ThrowStatement:
+			// It leaves the loops too, but it's not a return: the function gives no value
+			for _, l := range b.loops {
+				l.exits = append(l.exits, current)
+			}
+			current = nil
+		case *ast.ExpressionStatement:
+			switch exp := stmt.Expression.(type) {
+			case *ast.IfExpression:
+				open, current = b.ifExpression(current, exp), nil
+			case *ast.ForExpression:
+				open, current = b.forExpression(current, exp), nil
+			case *ast.TryExpression:
+				open, current = b.tryExpression(current, exp), nil
+			}
+		}
+	}
+
+	if current != nil {
+		return []*Block{current}
+	}
+	return open
+}
+
+// Without an else, the block with the condition goes on by itself when it's false
+func (b *builder) ifExpression(current *Block, exp *ast.IfExpression) []*Block {
+	var open []*Block
+	if exp.Consequence != nil {
+		open = b.statements([]*Block{current}, current, exp.Consequence.Statements)
+	}
+	if exp.Alternative != nil {
+		open = append(open, b.statements([]*Block{current}, current, exp.Alternative.Statements)...)
+	} else {
+		open = append(open, current)
+	}
+	return open
+}
+
+// A `for true` only ends with a break, there's no way out through the condition
+func (b *builder) forExpression(current *Block, exp *ast.ForExpression) []*Block {
+	header := b.newBlock(current.parent, current)
+	l := &loop{node: exp, header: header}
+
+	b.loops = append(b.loops, l)
+	if exp.Body != nil {
+		for _, end := range b.statements([]*Block{header}, header, exp.Body.Statements) {
+			link(end, header)
+		}
+	}
+	b.loops = b.loops[:len(b.loops)-1]
+	b.graph.loops = append(b.graph.loops, l)
+
+	if isTrue(exp.Condition) {
+		return l.breaks
+	}
+	return append([]*Block{header}, l.breaks...)
+}
+
+/*
+Any statement of the body may fail, so the catch can start from where the try is. The
+finally runs after whatever happened, but what comes after the try is only reached
+when the body or the catch got to their end.
+*/
+func (b *builder) tryExpression(current *Block, exp *ast.TryExpression) []*Block {
+	open := b.statements([]*Block{current}, current, exp.Body.Statements)
+	if exp.Catch != nil {
+		open = append(open, b.statements([]*Block{current}, current, exp.Catch.Statements)...)
+	}
+	if exp.Finally == nil {
+		return open
+	}
+
+	ends := b.statements(append([]*Block{current}, open...), current, exp.Finally.Statements)
+	if len(open) == 0 {
+		return nil
+	}
+	return ends
+}
+
+func (b *builder) innermostLoop() *loop {
+	if len(b.loops) == 0 {
+		return nil
+	}
+	return b.loops[len(b.loops)-1]
+}
+
+func isTrue(exp ast.Expression) bool {
+	boolean, ok := exp.(*ast.BooleanLiteral)
+	return ok && boolean.Value
+}
@@ -2,7 +2,6 @@ package lexer
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/santos-404/myte/token"
 )
@@ -100,16 +99,17 @@ func (l *Lexer) NextToken() token.Token {
 				tok.Literal = "Comment"
 				tok.Type = token.COMMENT
 				if err := l.readComment("block"); err != nil {
-					log.Fatal(err)
+					// The parser has nothing to do with this, so it will report it
+					tok.Literal = "#-"
+					tok.Type = token.ILLEGAL
+					return tok
 				}
 			} else {  // In-line comments
 				tok.Column = l.column
 				tok.Line = l.line
 				tok.Literal = "Comment"
 				tok.Type = token.COMMENT
-				if err := l.readComment("line"); err != nil {
-					log.Fatal(err)
-				}
+				l.readComment("line")  // This one cannot fail
 			}
 		case ',':
 			tok = l.newToken(token.COMMA, l.char)
@@ -117,6 +117,8 @@ func (l *Lexer) NextToken() token.Token {
 			tok = l.newToken(token.SEMICOLON, l.char)
 		case ':':
 			tok = l.newToken(token.COLON, l.char)
+		case '?':
+			tok = l.newToken(token.QUESTION, l.char)
 		case '(':
 			tok = l.newToken(token.LPAREN, l.char)
 		case ')':
@@ -130,6 +132,10 @@ func (l *Lexer) NextToken() token.Token {
 		case ']':
 			tok = l.newToken(token.RBRACKET, l.char)
 		case '.':
+			if !isDigit(l.peekNextChar()) {  // module.member
+				tok = l.newToken(token.DOT, l.char)
+				break
+			}
 			tok.Line = l.line
 			tok.Column = l.column
 			tok.Literal, tok.Type = l.readNumber()
@@ -137,18 +143,18 @@ func (l *Lexer) NextToken() token.Token {
 		case '"':
 			tok.Column = l.column  // I did it first of all to store the position of the beginning
 			tok.Line = l.line
-			tok.Literal = l.readString('"')
-			tok.Type = token.STRING
+			tok.Literal, tok.Type = l.readString('"')
 			return tok
 		case '\'':
 			tok.Column = l.column 
 			tok.Line = l.line
-			tok.Literal = l.readString('\'')
-			tok.Type = token.STRING
+			tok.Literal, tok.Type = l.readString('\'')
 			return tok
 		case 0:
 			tok.Literal = ""
 			tok.Type = token.EOF
+			tok.Line = l.line  // So errors about a missing token can point to the end
+			tok.Column = l.column
 		default:
 			// It's really important we start checking for digits beacuse we've added support to digits
 			// on isValidCharForIdent. Then, we don't want to go in that branch with an initial digit.
@@ -212,14 +218,22 @@ func (l* Lexer) newComplexToken(tokenType token.TokenType) token.Token {
 	}
 }
 
-func (l *Lexer) readString(quoteType byte) string {
+// A string that is never closed is returned as ILLEGAL, so the parser can report it.
+// The escapes are kept as they are written, we only skip them so \" doesn't close it.
+func (l *Lexer) readString(quoteType byte) (string, token.TokenType) {
 	startPos := l.position	
 	l.readChar()	
 	for l.char != quoteType {
+		if l.char == 0 {
+			return l.input[startPos:l.position], token.ILLEGAL
+		}
+		if l.char == '\\' && l.peekNextChar() != 0 {
+			l.readChar()
+		}
 		l.readChar()	
 	}
 	l.readChar()	
-	return l.input[startPos:l.position]
+	return l.input[startPos:l.position], token.STRING
 }
 
 func (l *Lexer) readIdentifier() string {
@@ -254,7 +268,7 @@ func (l *Lexer) readNumber() (string, token.TokenType) {
 func (l* Lexer) readComment(commentType string) error {
 	switch commentType {
 	case "line":
-		for l.char != '\n' {
+		for l.char != '\n' && l.char != 0 {
 			l.readChar()
 		}
 	case "block":  // The structure is:  #- whatever -#
@@ -0,0 +1,31 @@
+package lexer
+
+import (
+	"testing"
+
+	"github.com/santos-404/myte/token"
+)
+
+// The escapes are kept in the literal, the evaluator is the one that decodes them
+func TestStringEscapes(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{`"a\"b"`, token.STRING, `"a\"b"`},
+		{`'it\'s'`, token.STRING, `'it\'s'`},
+		{`"one\ntwo\t"`, token.STRING, `"one\ntwo\t"`},
+		{`"back\\"`, token.STRING, `"back\\"`},
+		{`"open\"`, token.ILLEGAL, `"open\"`},
+		{`"end\`, token.ILLEGAL, `"end\`},
+	}
+
+	for _, tt := range tests {
+		tok := New(tt.input).NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Errorf("%s - wrong token. expected=%s %q, got=%s %q",
+				tt.input, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
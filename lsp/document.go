@@ -0,0 +1,114 @@
+package lsp
+
+import (
+	"strings"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/checker"
+	"github.com/santos-404/myte/flow"
+	"github.com/santos-404/myte/lexer"
+	"github.com/santos-404/myte/optimize"
+	"github.com/santos-404/myte/parser"
+	"github.com/santos-404/myte/token"
+	"github.com/santos-404/myte/typecheck"
+)
+
+// Every time a document changes we parse it again from scratch and keep the results here
+type document struct {
+	uri     string
+	text    string
+	lines   []string
+	program *ast.Program
+	errors  []parser.ParserError
+	checks  *checker.Result
+	types   *typecheck.Result
+	folds   []checker.Finding  // What the optimizer found out, like a division by zero
+	flow    []checker.Finding  // Unreachable code and the like
+	refs    map[tokenPosition]*checker.Declaration  // Both the uses and the declarations themselves
+}
+
+// Identifiers are found by where they are written, that's unique enough
+type tokenPosition struct {
+	line   int
+	column int
+}
+
+func newDocument(uri, text string) *document {
+	p := parser.New(lexer.New(text))
+	program := p.ParseProgram()
+	checks := checker.Check(program)
+	_, folds := optimize.Program(program)
+
+	refs := make(map[tokenPosition]*checker.Declaration)
+	for ident, decl := range checks.Refs {
+		refs[tokenPosition{ident.Token.Line, ident.Token.Column}] = decl
+	}
+
+	return &document{
+		uri:     uri,
+		text:    text,
+		lines:   strings.Split(text, "\n"),
+		program: program,
+		errors:  p.ParserErrors(),
+		checks:  checks,
+		types:   typecheck.Check(program),
+		folds:   folds,
+		flow:    flow.Check(program),
+		refs:    refs,
+	}
+}
+
+/*
+The lexer counts lines from 0 like the protocol does, but its columns are not
+offsets: they start at 1, point to the last char read and a tab counts as 4. This
+helper translates them.
+*/
+func (d *document) toCharacter(line, column int) int {
+	if line < 0 || line >= len(d.lines) {
+		return 0
+	}
+
+	width := 0
+	for i := 0; i < len(d.lines[line]); i++ {
+		width += charWidth(d.lines[line][i])
+		if width >= column {
+			return i
+		}
+	}
+	return len(d.lines[line])
+}
+
+func charWidth(char byte) int {
+	if char == '\t' {
+		return 4  // Same as the lexer
+	}
+	return 1
+}
+
+// The range a token covers, as long as it doesn't span more than one line
+func (d *document) tokenRange(tok token.Token) Range {
+	start := d.toCharacter(tok.Line, tok.Column)
+	length := len(tok.Literal)
+	if length == 0 {
+		length = 1
+	}
+	return Range{
+		Start: Position{Line: tok.Line, Character: start},
+		End:   Position{Line: tok.Line, Character: start + length},
+	}
+}
+
+// tokenAt gives back the token under the cursor, if there is any
+func (d *document) tokenAt(pos Position) (token.Token, bool) {
+	l := lexer.New(d.text)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		if tok.Line != pos.Line || tok.Type == token.COMMENT {
+			continue
+		}
+		r := d.tokenRange(tok)
+		if r.Start.Character <= pos.Character && pos.Character < r.End.Character {
+			return tok, true
+		}
+	}
+	return token.Token{}, false
+}
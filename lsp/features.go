@@ -0,0 +1,369 @@
+package lsp
+
+import (
+	"strings"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/checker"
+	"github.com/santos-404/myte/evaluator"
+	"github.com/santos-404/myte/lexer"
+	"github.com/santos-404/myte/token"
+)
+
+func (d *document) diagnostics() []Diagnostic {
+	diagnostics := []Diagnostic{}  // Never nil, an empty list is what clears the old ones
+
+	for _, err := range d.errors {
+		tok := token.Token{Line: err.Line, Column: err.Column}
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    d.tokenRange(tok),
+			Severity: SeverityError,
+			Source:   "myte",
+			Message:  err.Message,
+		})
+	}
+
+	// A broken tree makes the checker see problems that aren't there, so we wait
+	// until the syntax is right to show what it found
+	if len(d.errors) > 0 {
+		return diagnostics
+	}
+	findings := append([]checker.Finding{}, d.checks.Findings...)
+	findings = append(findings, d.types.Findings...)
+	findings = append(findings, checker.Unreported(d.folds, d.types.Findings)...)
+	findings = append(findings, d.flow...)
+	checker.SortFindings(findings)
+
+	for _, finding := range findings {
+		severity := SeverityError
+		if finding.Severity == checker.Warning {
+			severity = SeverityWarning
+		}
+		tok := token.Token{Line: finding.Line, Column: finding.Column}
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    d.tokenRange(tok),
+			Severity: severity,
+			Source:   "myte",
+			Message:  finding.Message,
+		})
+	}
+
+	return diagnostics
+}
+
+
+func (d *document) symbols() []DocumentSymbol {
+	return d.statementSymbols(d.program.Statements)
+}
+
+func (d *document) statementSymbols(statements []ast.Statement) []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+
+	for _, stmt := range statements {
+		switch stmt := stmt.(type) {
+		case *ast.VarStatement:
+			if stmt != nil {
+				symbols = append(symbols, d.bindingSymbol(stmt.Name, stmt.Value, SymbolKindVariable))
+			}
+		case *ast.ConstStatement:
+			if stmt != nil {
+				symbols = append(symbols, d.bindingSymbol(stmt.Name, stmt.Value, SymbolKindConstant))
+			}
+		case *ast.ExpressionStatement:
+			symbols = append(symbols, d.expressionSymbols(stmt.Expression)...)
+		case *ast.ReturnStatement:
+			symbols = append(symbols, d.expressionSymbols(stmt.ReturnValue)...)
+		case *ast.ThrowStatement:
+			symbols = append(symbols, d.expressionSymbols(stmt.Value)...)
+		case *ast.ImportStatement:
+			symbols = append(symbols, DocumentSymbol{
+				Name:           stmt.Name.Value,
+				Detail:         stmt.Path,
+				Kind:           SymbolKindModule,
+				Range:          d.tokenRange(stmt.Token),
+				SelectionRange: d.tokenRange(stmt.Name.Token),
+			})
+		}
+	}
+
+	return symbols
+}
+
+func (d *document) bindingSymbol(name *ast.Identifier, value ast.Expression, kind int) DocumentSymbol {
+	symbol := DocumentSymbol{
+		Name:           name.Value,
+		Kind:           kind,
+		Range:          d.tokenRange(name.Token),
+		SelectionRange: d.tokenRange(name.Token),
+	}
+
+	// A binding holding a function is shown as the function itself
+	if fn, ok := value.(*ast.FunctionLiteral); ok {
+		symbol.Kind = SymbolKindFunction
+		symbol.Detail = functionSignature(fn)
+		symbol.Children = d.statementSymbols(fn.Body.Statements)
+	} else {
+		symbol.Children = d.expressionSymbols(value)
+	}
+	return symbol
+}
+
+// These are the functions nobody gave a name to, like the ones passed as arguments
+func (d *document) expressionSymbols(exp ast.Expression) []DocumentSymbol {
+	var symbols []DocumentSymbol
+
+	switch exp := exp.(type) {
+	case *ast.FunctionLiteral:
+		symbols = append(symbols, DocumentSymbol{
+			Name:           "<anonymous fn>",
+			Detail:         functionSignature(exp),
+			Kind:           SymbolKindFunction,
+			Range:          d.tokenRange(exp.Token),
+			SelectionRange: d.tokenRange(exp.Token),
+			Children:       d.statementSymbols(exp.Body.Statements),
+		})
+	case *ast.PrefixExpression:
+		symbols = append(symbols, d.expressionSymbols(exp.Right)...)
+	case *ast.InfixExpression:
+		symbols = append(symbols, d.expressionSymbols(exp.Left)...)
+		symbols = append(symbols, d.expressionSymbols(exp.Right)...)
+	case *ast.AssignExpression:
+		symbols = append(symbols, d.expressionSymbols(exp.Value)...)
+	case *ast.CallExpression:
+		symbols = append(symbols, d.expressionSymbols(exp.Function)...)
+		for _, arg := range exp.Arguments {
+			symbols = append(symbols, d.expressionSymbols(arg)...)
+		}
+	case *ast.MemberExpression:
+		symbols = append(symbols, d.expressionSymbols(exp.Object)...)
+	case *ast.ArrayLiteral:
+		for _, element := range exp.Elements {
+			symbols = append(symbols, d.expressionSymbols(element)...)
+		}
+	case *ast.MapLiteral:
+		for i, key := range exp.Keys {
+			symbols = append(symbols, d.expressionSymbols(key)...)
+			symbols = append(symbols, d.expressionSymbols(exp.Values[i])...)
+		}
+	case *ast.IndexExpression:
+		symbols = append(symbols, d.expressionSymbols(exp.Left)...)
+		symbols = append(symbols, d.expressionSymbols(exp.Index)...)
+	case *ast.PropagateExpression:
+		symbols = append(symbols, d.expressionSymbols(exp.Value)...)
+	case *ast.IfExpression:
+		symbols = append(symbols, d.expressionSymbols(exp.Condition)...)
+		if exp.Consequence != nil {
+			symbols = append(symbols, d.statementSymbols(exp.Consequence.Statements)...)
+		}
+		if exp.Alternative != nil {
+			symbols = append(symbols, d.statementSymbols(exp.Alternative.Statements)...)
+		}
+	case *ast.ForExpression:
+		symbols = append(symbols, d.expressionSymbols(exp.Condition)...)
+		if exp.Body != nil {
+			symbols = append(symbols, d.statementSymbols(exp.Body.Statements)...)
+		}
+	case *ast.TryExpression:
+		for _, block := range []*ast.BlockStatement{exp.Body, exp.Catch, exp.Finally} {
+			if block != nil {
+				symbols = append(symbols, d.statementSymbols(block.Statements)...)
+			}
+		}
+	}
+
+	return symbols
+}
+
+func functionSignature(fn *ast.FunctionLiteral) string {
+	var params []string
+	for _, param := range fn.Parameters {
+		params = append(params, param.Value)
+	}
+	return "fn(" + strings.Join(params, ", ") + ")"
+}
+
+
+// declarationAt finds what the identifier under the cursor refers to
+func (d *document) declarationAt(pos Position) (*checker.Declaration, token.Token, bool) {
+	tok, ok := d.tokenAt(pos)
+	if !ok || tok.Type != token.IDENT {
+		return nil, tok, false
+	}
+
+	decl, ok := d.refs[tokenPosition{tok.Line, tok.Column}]
+	return decl, tok, ok
+}
+
+func (d *document) hover(pos Position) *Hover {
+	decl, tok, ok := d.declarationAt(pos)
+	if !ok {
+		return d.builtinHover(tok)
+	}
+
+	r := d.tokenRange(tok)
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: "```myte\n" + d.describe(decl) + "\n```"},
+		Range:    &r,
+	}
+}
+
+// Builtins are declared nowhere, so what they do is shown below their signature
+func (d *document) builtinHover(tok token.Token) *Hover {
+	builtin, ok := evaluator.LookupBuiltin(tok.Literal)
+	if !ok || tok.Type != token.IDENT {
+		return nil
+	}
+
+	r := d.tokenRange(tok)
+	return &Hover{
+		Contents: MarkupContent{
+			Kind:  "markdown",
+			Value: "```myte\n(builtin) " + builtin.Signature() + "\n```\n" + builtin.Doc,
+		},
+		Range: &r,
+	}
+}
+
+// The type is the inferred one, or the annotation if there is one
+func (d *document) describe(decl *checker.Declaration) string {
+	name := decl.Name.Value
+	if t, ok := d.types.Bindings[decl.Name]; ok {
+		name += ": " + t.String()
+	}
+
+	switch decl.Kind {
+	case checker.ParamDeclaration:
+		return "(parameter) " + name
+	case checker.ImportDeclaration:
+		return "(module) " + decl.Name.Value
+	case checker.ConstDeclaration:
+		return "const " + name + describeValue(decl.Value)
+	default:
+		return "var " + name + describeValue(decl.Value)
+	}
+}
+
+// Short values are shown whole. Functions are not, their type already says it all.
+func describeValue(value ast.Expression) string {
+	switch value := value.(type) {
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral,
+		*ast.BooleanLiteral, *ast.NilLiteral, *ast.Identifier:
+		return " = " + value.String()
+	}
+	return ""
+}
+
+func (d *document) definition(pos Position) *Location {
+	decl, _, ok := d.declarationAt(pos)
+	if !ok {
+		return nil
+	}
+	return &Location{URI: d.uri, Range: d.tokenRange(decl.Name.Token)}
+}
+
+
+// The order here is the legend we announce on initialize; the index is what we send
+var semanticTokenTypes = []string{
+	"keyword", "variable", "function", "parameter", "number", "string", "comment", "operator",
+}
+
+const (
+	semanticKeyword = iota
+	semanticVariable
+	semanticFunction
+	semanticParameter
+	semanticNumber
+	semanticString
+	semanticComment
+	semanticOperator
+)
+
+type semanticToken struct {
+	line, character, length, tokenType int
+}
+
+/*
+The tokens come straight from the lexer. Identifiers are told apart (variable,
+function or parameter) using what the resolver found. The protocol wants every
+token relative to the previous one, that's why we encode them at the end.
+*/
+func (d *document) semanticTokens() SemanticTokens {
+	var tokens []semanticToken
+
+	l := lexer.New(d.text)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		start := d.toCharacter(tok.Line, tok.Column)
+
+		switch {
+		case tok.Type == token.COMMENT:
+			tokens = append(tokens, d.commentTokens(tok.Line, start)...)
+		case tok.Type == token.IDENT:
+			tokens = append(tokens, semanticToken{tok.Line, start, len(tok.Literal), d.identifierType(tok)})
+		case tok.Type == token.INT || tok.Type == token.FLOAT:
+			tokens = append(tokens, semanticToken{tok.Line, start, len(tok.Literal), semanticNumber})
+		case tok.Type == token.STRING:
+			tokens = append(tokens, semanticToken{tok.Line, start, len(tok.Literal), semanticString})
+		case tok.Type >= token.FUNCTION:
+			tokens = append(tokens, semanticToken{tok.Line, start, len(tok.Literal), semanticKeyword})
+		case tok.Type >= token.ASSIGN && tok.Type <= token.NOTEQ:
+			tokens = append(tokens, semanticToken{tok.Line, start, len(tok.Literal), semanticOperator})
+		}
+	}
+
+	data := []int{}
+	previousLine, previousStart := 0, 0
+	for _, tok := range tokens {
+		if tok.line != previousLine {
+			previousStart = 0
+		}
+		data = append(data, tok.line-previousLine, tok.character-previousStart, tok.length, tok.tokenType, 0)
+		previousLine, previousStart = tok.line, tok.character
+	}
+
+	return SemanticTokens{Data: data}
+}
+
+func (d *document) identifierType(tok token.Token) int {
+	decl, ok := d.refs[tokenPosition{tok.Line, tok.Column}]
+	if !ok {
+		if evaluator.IsBuiltin(tok.Literal) {
+			return semanticFunction
+		}
+		return semanticVariable
+	}
+	if decl.Kind == checker.ParamDeclaration {
+		return semanticParameter
+	}
+	if _, isFunction := decl.Value.(*ast.FunctionLiteral); isFunction {
+		return semanticFunction
+	}
+	return semanticVariable
+}
+
+// The lexer doesn't tell where a comment ends, so we find it on the text. Block
+// comments get one token per line because tokens cannot span lines.
+func (d *document) commentTokens(line, start int) []semanticToken {
+	if line >= len(d.lines) {
+		return nil
+	}
+	text := d.lines[line][start:]
+
+	if !strings.HasPrefix(text, "#-") {
+		return []semanticToken{{line, start, len(text), semanticComment}}
+	}
+
+	var tokens []semanticToken
+	for ; line < len(d.lines); line++ {
+		if end := strings.Index(text, "-#"); end >= 0 {
+			return append(tokens, semanticToken{line, start, end + 2, semanticComment})
+		}
+		if len(text) > 0 {
+			tokens = append(tokens, semanticToken{line, start, len(text), semanticComment})
+		}
+		if line+1 < len(d.lines) {
+			text, start = d.lines[line+1], 0
+		}
+	}
+	return tokens
+}
+
@@ -0,0 +1,336 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"testing"
+)
+
+// testClient talks to a real Server through in-memory pipes
+type testClient struct {
+	t      *testing.T
+	writer *io.PipeWriter
+	reader *bufio.Reader
+	nextID int
+	done   chan error
+}
+
+func newTestClient(t *testing.T) *testClient {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	c := &testClient{
+		t:      t,
+		writer: clientOut,
+		reader: bufio.NewReader(clientIn),
+		done:   make(chan error, 1),
+	}
+	go func() {
+		c.done <- Serve(serverIn, serverOut)
+		serverOut.Close()
+	}()
+
+	c.request("initialize", map[string]interface{}{})
+	c.send("initialized", nil, nil)
+	return c
+}
+
+func (c *testClient) send(method string, id *int, params interface{}) {
+	message := map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}
+	if id != nil {
+		message["id"] = *id
+	}
+	body, _ := json.Marshal(message)
+	fmt.Fprintf(c.writer, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func (c *testClient) read() map[string]json.RawMessage {
+	headers, err := textproto.NewReader(c.reader).ReadMIMEHeader()
+	if err != nil {
+		c.t.Fatalf("could not read headers: %s", err)
+	}
+	length, _ := strconv.Atoi(headers.Get("Content-Length"))
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, body); err != nil {
+		c.t.Fatalf("could not read body: %s", err)
+	}
+
+	var message map[string]json.RawMessage
+	if err := json.Unmarshal(body, &message); err != nil {
+		c.t.Fatalf("invalid json from server: %s", body)
+	}
+	return message
+}
+
+// request sends a request and decodes the "result" of its response into result
+func (c *testClient) request(method string, params interface{}) json.RawMessage {
+	c.nextID++
+	id := c.nextID
+	c.send(method, &id, params)
+
+	message := c.read()
+	if errBody, ok := message["error"]; ok {
+		c.t.Fatalf("%s failed: %s", method, errBody)
+	}
+	return message["result"]
+}
+
+func (c *testClient) open(uri, text string) []Diagnostic {
+	c.send("textDocument/didOpen", nil, map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "version": 1, "text": text},
+	})
+	return c.readDiagnostics()
+}
+
+func (c *testClient) readDiagnostics() []Diagnostic {
+	message := c.read()
+	var params publishDiagnosticsParams
+	json.Unmarshal(message["params"], &params)
+	return params.Diagnostics
+}
+
+func (c *testClient) close() {
+	c.request("shutdown", nil)
+	c.send("exit", nil, nil)
+	if err := <-c.done; err != nil {
+		c.t.Errorf("server ended with an error: %s", err)
+	}
+}
+
+func position(uri string, line, character int) map[string]interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]string{"uri": uri},
+		"position":     Position{Line: line, Character: character},
+	}
+}
+
+const testSource = `const add = fn(x, y) {
+	return x + y;
+};
+var total = add(1, 2);
+`
+
+func TestDiagnostics(t *testing.T) {
+	c := newTestClient(t)
+	defer c.close()
+
+	diagnostics := c.open("file:///a.myte", testSource)
+	if len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics. got=%+v", diagnostics)
+	}
+
+	c.send("textDocument/didChange", nil, map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": "file:///a.myte", "version": 2},
+		"contentChanges": []map[string]string{{"text": "var x = fn(a {"}},
+	})
+	diagnostics = c.readDiagnostics()
+	if len(diagnostics) == 0 {
+		t.Fatalf("expected diagnostics for broken code")
+	}
+	if diagnostics[0].Severity != SeverityError || diagnostics[0].Range.Start.Line != 0 {
+		t.Errorf("unexpected diagnostic: %+v", diagnostics[0])
+	}
+
+	diagnostics = c.open("file:///b.myte", "var s = 'never closed")
+	if len(diagnostics) != 1 || diagnostics[0].Message != "the string is not closed" {
+		t.Errorf("unexpected diagnostics for an open string: %+v", diagnostics)
+	}
+}
+
+func TestDocumentSymbols(t *testing.T) {
+	c := newTestClient(t)
+	defer c.close()
+	c.open("file:///a.myte", testSource+"apply(fn(z) { var inner = z; });\n")
+
+	var symbols []DocumentSymbol
+	json.Unmarshal(c.request("textDocument/documentSymbol",
+		map[string]interface{}{"textDocument": map[string]string{"uri": "file:///a.myte"}}), &symbols)
+
+	if len(symbols) != 3 {
+		t.Fatalf("wrong number of symbols. want=3, got=%d (%+v)", len(symbols), symbols)
+	}
+
+	tests := []struct {
+		name   string
+		kind   int
+		detail string
+	}{
+		{"add", SymbolKindFunction, "fn(x, y)"},
+		{"total", SymbolKindVariable, ""},
+		{"<anonymous fn>", SymbolKindFunction, "fn(z)"},
+	}
+	for i, tt := range tests {
+		if symbols[i].Name != tt.name || symbols[i].Kind != tt.kind || symbols[i].Detail != tt.detail {
+			t.Errorf("symbols[%d] wrong. expected=%+v, got=%+v", i, tt, symbols[i])
+		}
+	}
+	if len(symbols[2].Children) != 1 || symbols[2].Children[0].Name != "inner" {
+		t.Errorf("anonymous fn children wrong. got=%+v", symbols[2].Children)
+	}
+}
+
+func TestHoverAndDefinition(t *testing.T) {
+	c := newTestClient(t)
+	defer c.close()
+	c.open("file:///a.myte", testSource)
+
+	var hover Hover
+	json.Unmarshal(c.request("textDocument/hover", position("file:///a.myte", 3, 13)), &hover)
+	if hover.Contents.Value != "```myte\nconst add: fn(any, any): any\n```" {
+		t.Errorf("hover wrong. got=%q", hover.Contents.Value)
+	}
+
+	json.Unmarshal(c.request("textDocument/hover", position("file:///a.myte", 1, 8)), &hover)
+	if hover.Contents.Value != "```myte\n(parameter) x: any\n```" {
+		t.Errorf("hover on parameter wrong. got=%q", hover.Contents.Value)
+	}
+
+	c.open("file:///b.myte", "var half = 1 / 2;\nconst count = 3;\n")
+	json.Unmarshal(c.request("textDocument/hover", position("file:///b.myte", 0, 5)), &hover)
+	if hover.Contents.Value != "```myte\nvar half: float\n```" {
+		t.Errorf("hover on inferred var wrong. got=%q", hover.Contents.Value)
+	}
+	json.Unmarshal(c.request("textDocument/hover", position("file:///b.myte", 1, 7)), &hover)
+	if hover.Contents.Value != "```myte\nconst count: int = 3\n```" {
+		t.Errorf("hover on inferred const wrong. got=%q", hover.Contents.Value)
+	}
+
+	var location Location
+	json.Unmarshal(c.request("textDocument/definition", position("file:///a.myte", 1, 12)), &location)
+	expected := Range{Start: Position{0, 18}, End: Position{0, 19}}
+	if location.URI != "file:///a.myte" || location.Range != expected {
+		t.Errorf("definition of y wrong. got=%+v", location)
+	}
+
+	result := c.request("textDocument/definition", position("file:///a.myte", 0, 2))
+	if string(result) != "null" {
+		t.Errorf("definition on a keyword should be null. got=%s", result)
+	}
+}
+
+func TestSemanticTokens(t *testing.T) {
+	c := newTestClient(t)
+	defer c.close()
+	c.open("file:///a.myte", "var f = fn(a) { a + 1 } # hi\n#- two\nlines -#\n")
+
+	var tokens SemanticTokens
+	json.Unmarshal(c.request("textDocument/semanticTokens/full",
+		map[string]interface{}{"textDocument": map[string]string{"uri": "file:///a.myte"}}), &tokens)
+
+	expected := []int{
+		0, 0, 3, semanticKeyword, 0,    // var
+		0, 4, 1, semanticFunction, 0,   // f
+		0, 2, 1, semanticOperator, 0,   // =
+		0, 2, 2, semanticKeyword, 0,    // fn
+		0, 3, 1, semanticParameter, 0,  // a
+		0, 5, 1, semanticParameter, 0,  // a
+		0, 2, 1, semanticOperator, 0,   // +
+		0, 2, 1, semanticNumber, 0,     // 1
+		0, 4, 4, semanticComment, 0,    // # hi
+		1, 0, 6, semanticComment, 0,    // #- two
+		1, 0, 8, semanticComment, 0,    // lines -#
+	}
+	if fmt.Sprint(tokens.Data) != fmt.Sprint(expected) {
+		t.Errorf("semantic tokens wrong.\nexpected=%v\ngot=     %v", expected, tokens.Data)
+	}
+}
+
+func TestUnknownMethod(t *testing.T) {
+	c := newTestClient(t)
+	defer c.close()
+
+	c.nextID++
+	id := c.nextID
+	c.send("workspace/symbol", &id, map[string]string{})
+	message := c.read()
+	if _, ok := message["error"]; !ok {
+		t.Errorf("expected an error for an unsupported method. got=%v", message)
+	}
+}
+
+func TestCheckerDiagnostics(t *testing.T) {
+	c := newTestClient(t)
+	defer c.close()
+
+	diagnostics := c.open("file:///a.myte", "const c = 1;\nc = 2;\nfn() { var unused = 1; };\n")
+	if len(diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics. got=%+v", diagnostics)
+	}
+	if diagnostics[0].Severity != SeverityError || diagnostics[0].Message != "cannot assign to constant: c" {
+		t.Errorf("diagnostics[0] wrong. got=%+v", diagnostics[0])
+	}
+	if diagnostics[1].Severity != SeverityWarning || diagnostics[1].Range.Start != (Position{2, 11}) {
+		t.Errorf("diagnostics[1] wrong. got=%+v", diagnostics[1])
+	}
+}
+
+func TestFoldingDiagnostics(t *testing.T) {
+	c := newTestClient(t)
+	defer c.close()
+
+	diagnostics := c.open("file:///a.myte", "var day = 60 * 60 * 24;\nvar oops = day / 0;\nvar never = 1 // 0;\n")
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic. got=%+v", diagnostics)
+	}
+	if diagnostics[0].Severity != SeverityWarning || diagnostics[0].Message != "this always fails: division by zero" ||
+		diagnostics[0].Range.Start != (Position{2, 14}) {
+		t.Errorf("diagnostic wrong. got=%+v", diagnostics[0])
+	}
+}
+
+// What the types already tell is not said again by the optimizer
+func TestFoldingDiagnosticsOnce(t *testing.T) {
+	c := newTestClient(t)
+	defer c.close()
+
+	diagnostics := c.open("file:///a.myte", "var x = 'a' - 1;\n")
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic. got=%+v", diagnostics)
+	}
+	if diagnostics[0].Severity != SeverityError || diagnostics[0].Message != "unsupported operand types: string - int" {
+		t.Errorf("diagnostic wrong. got=%+v", diagnostics[0])
+	}
+}
+
+func TestFlowDiagnostics(t *testing.T) {
+	c := newTestClient(t)
+	defer c.close()
+
+	diagnostics := c.open("file:///a.myte", "const f = fn(n) {\n\treturn n;\n\tn + 1\n};\n")
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic. got=%+v", diagnostics)
+	}
+	if diagnostics[0].Severity != SeverityWarning || diagnostics[0].Message != "unreachable code" ||
+		diagnostics[0].Range.Start != (Position{2, 1}) {
+		t.Errorf("diagnostic wrong. got=%+v", diagnostics[0])
+	}
+}
+
+func TestBuiltins(t *testing.T) {
+	c := newTestClient(t)
+	defer c.close()
+
+	diagnostics := c.open("file:///a.myte", "println(len('abc'));\nvar str = 1;\n")
+	if len(diagnostics) != 1 || diagnostics[0].Message != "cannot redeclare builtin: str" {
+		t.Fatalf("expected the redeclaration only. got=%+v", diagnostics)
+	}
+
+	var hover Hover
+	json.Unmarshal(c.request("textDocument/hover", position("file:///a.myte", 0, 9)), &hover)
+	expected := "```myte\n(builtin) len(value: string | array | map): int\n```\n" +
+		"Gives back the number of characters of a string, elements of an array or keys of a map"
+	if hover.Contents.Value != expected {
+		t.Errorf("hover on builtin wrong. got=%q", hover.Contents.Value)
+	}
+
+	var tokens SemanticTokens
+	json.Unmarshal(c.request("textDocument/semanticTokens/full",
+		map[string]interface{}{"textDocument": map[string]string{"uri": "file:///a.myte"}}), &tokens)
+	if len(tokens.Data) < 10 || tokens.Data[3] != semanticFunction || tokens.Data[8] != semanticFunction {
+		t.Errorf("println and len should be functions. got=%v", tokens.Data)
+	}
+}
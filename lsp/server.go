@@ -0,0 +1,214 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+type Server struct {
+	reader    *bufio.Reader
+	writer    io.Writer
+	documents map[string]*document
+	shutdown  bool
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		reader:    bufio.NewReader(in),
+		writer:    out,
+		documents: make(map[string]*document),
+	}
+}
+
+// Serve speaks the protocol over in/out until the client says exit (or closes the input)
+func Serve(in io.Reader, out io.Writer) error {
+	return NewServer(in, out).Run()
+}
+
+func (s *Server) Run() error {
+	for {
+		body, err := s.readMessage()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.replyError(nil, codeParseError, err.Error())
+			continue
+		}
+
+		if req.Method == "exit" {
+			return nil
+		}
+		if err := s.handle(&req); err != nil {
+			return err
+		}
+	}
+}
+
+/*
+Every message comes with some headers, like HTTP. The only one we need is
+Content-Length, which tells how many bytes of JSON follow the empty line.
+*/
+func (s *Server) readMessage() ([]byte, error) {
+	headers, err := textproto.NewReader(s.reader).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(strings.TrimSpace(headers.Get("Content-Length")))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length header: %q", headers.Get("Content-Length"))
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.reader, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func (s *Server) writeMessage(message interface{}) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.writer, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+func (s *Server) reply(id *json.RawMessage, result interface{}) error {
+	return s.writeMessage(response{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (s *Server) replyError(id *json.RawMessage, code int, message string) error {
+	return s.writeMessage(errorResponse{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error:   responseError{Code: code, Message: message},
+	})
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	return s.writeMessage(notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (s *Server) handle(req *request) error {
+	if s.shutdown && req.ID != nil {
+		return s.replyError(req.ID, codeInvalidRequest, "the server is shutting down")
+	}
+
+	switch req.Method {
+	case "initialize":
+		return s.reply(req.ID, map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":       1,  // We always get the full text
+				"documentSymbolProvider": true,
+				"hoverProvider":          true,
+				"definitionProvider":     true,
+				"semanticTokensProvider": map[string]interface{}{
+					"legend": map[string]interface{}{
+						"tokenTypes":     semanticTokenTypes,
+						"tokenModifiers": []string{},
+					},
+					"full": true,
+				},
+			},
+			"serverInfo": map[string]string{"name": "myte"},
+		})
+	case "initialized":
+		return nil
+	case "shutdown":
+		s.shutdown = true
+		return s.reply(req.ID, nil)
+
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil  // Notifications cannot be answered, not even with an error
+		}
+		return s.update(params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := json.Unmarshal(req.Params, &params); err != nil || len(params.ContentChanges) == 0 {
+			return nil
+		}
+		last := params.ContentChanges[len(params.ContentChanges)-1]
+		return s.update(params.TextDocument.URI, last.Text)
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil
+		}
+		delete(s.documents, params.TextDocument.URI)
+		return s.notify("textDocument/publishDiagnostics",
+			publishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []Diagnostic{}})
+
+	case "textDocument/documentSymbol":
+		doc, ok := s.documentFor(req)
+		if !ok {
+			return s.replyError(req.ID, codeInvalidParams, "unknown document")
+		}
+		return s.reply(req.ID, doc.symbols())
+	case "textDocument/semanticTokens/full":
+		doc, ok := s.documentFor(req)
+		if !ok {
+			return s.replyError(req.ID, codeInvalidParams, "unknown document")
+		}
+		return s.reply(req.ID, doc.semanticTokens())
+	case "textDocument/hover":
+		doc, pos, ok := s.positionFor(req)
+		if !ok {
+			return s.replyError(req.ID, codeInvalidParams, "unknown document")
+		}
+		return s.reply(req.ID, doc.hover(pos))
+	case "textDocument/definition":
+		doc, pos, ok := s.positionFor(req)
+		if !ok {
+			return s.replyError(req.ID, codeInvalidParams, "unknown document")
+		}
+		return s.reply(req.ID, doc.definition(pos))
+	}
+
+	if req.ID == nil {
+		return nil  // Unknown notifications (like $/cancelRequest) can be ignored
+	}
+	return s.replyError(req.ID, codeMethodNotFound, "method not supported: "+req.Method)
+}
+
+func (s *Server) update(uri, text string) error {
+	doc := newDocument(uri, text)
+	s.documents[uri] = doc
+	return s.notify("textDocument/publishDiagnostics",
+		publishDiagnosticsParams{URI: uri, Diagnostics: doc.diagnostics()})
+}
+
+func (s *Server) documentFor(req *request) (*document, bool) {
+	var params textDocumentParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return nil, false
+	}
+	doc, ok := s.documents[params.TextDocument.URI]
+	return doc, ok
+}
+
+func (s *Server) positionFor(req *request) (*document, Position, bool) {
+	var params textDocumentPositionParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return nil, Position{}, false
+	}
+	doc, ok := s.documents[params.TextDocument.URI]
+	return doc, params.Position, ok
+}
@@ -1,11 +1,28 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/user"
+	"path/filepath"
+	"strings"
 
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/checker"
+	"github.com/santos-404/myte/compiler"
+	"github.com/santos-404/myte/evaluator"
+	"github.com/santos-404/myte/flow"
+	"github.com/santos-404/myte/lexer"
+	"github.com/santos-404/myte/lsp"
+	"github.com/santos-404/myte/object"
+	"github.com/santos-404/myte/optimize"
+	"github.com/santos-404/myte/parser"
 	"github.com/santos-404/myte/repl"
+	"github.com/santos-404/myte/typecheck"
+	"github.com/santos-404/myte/vm"
 )
 
 const ASCII_ART = `░▒▓██████████████▓▒░░▒▓█▓▒░░▒▓█▓▒░▒▓████████▓▒░▒▓████████▓▒░ 
@@ -18,6 +35,31 @@ const ASCII_ART = `░▒▓██████████████▓▒░
                                                                 `
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "run":
+			os.Exit(runFile(os.Args[2:]))
+		case "build":
+			os.Exit(runBuild(os.Args[2:]))
+		case "disasm":
+			os.Exit(runDisasm(os.Args[2:]))
+		case "check":
+			os.Exit(runCheck(os.Args[2:]))
+		case "builtins":
+			os.Exit(runBuiltins(os.Args[2:]))
+		case "lsp":
+			// Nothing else can be printed here, stdout belongs to the client
+			if err := lsp.Serve(os.Stdin, os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		default:
+			fmt.Fprintf(os.Stderr, "unknown command: %s\n", os.Args[1])
+			os.Exit(2)
+		}
+	}
+
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
@@ -30,3 +72,356 @@ func main() {
 	repl.Start(os.Stdin, os.Stdout)
 }
 
+// runCheck reports every problem it finds on the files without running them. The
+// exit code is 1 when any of them has errors, so it can be used on CI.
+func runCheck(files []string) int {
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: myte check <file>...")
+		return 2
+	}
+
+	status := 0
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 1
+			continue
+		}
+
+		p := parser.New(lexer.New(string(content)))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			for _, err := range p.Errors() {
+				fmt.Printf("%s: error: %s\n", file, err)
+			}
+			status = 1
+			continue
+		}
+
+		result := checker.Check(program)
+		_, folds := optimize.Program(program)
+		types := typecheck.Check(program).Findings
+		findings := append(result.Findings, types...)
+		findings = append(findings, checker.Unreported(folds, types)...)
+		findings = append(findings, flow.Check(program)...)
+		checker.SortFindings(findings)
+
+		for _, finding := range findings {
+			fmt.Printf("%s: %s\n", file, finding)
+			if finding.Severity == checker.Error {
+				status = 1
+			}
+		}
+	}
+	return status
+}
+
+// runFile runs a program and prints the value it ends with, the way the REPL does.
+// A .mytec file is already compiled, so it goes straight to the vm.
+func runFile(args []string) int {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	engine := flags.String("engine", "eval", "what runs the program: eval (the tree walker) or vm")
+	var limits object.Limits
+	flags.IntVar(&limits.MaxSteps, "max-steps", 0, "stop after this many steps (0 is no limit)")
+	flags.IntVar(&limits.MaxCallDepth, "max-depth", 0, "the deepest the calls can go (0 is no limit)")
+	flags.IntVar(&limits.MaxCollectionSize, "max-size", 0, "the biggest array, map or string it can make (0 is no limit)")
+	timeout := flags.Duration("timeout", 0, "stop after this long, like 2s or 500ms (0 is no limit)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: myte run [--engine=eval|vm] [--max-steps=n] [--max-depth=n] [--max-size=n] [--timeout=d] <file>")
+		return 2
+	}
+	if *timeout > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		limits.Context = ctx
+	}
+	if *engine != "eval" && *engine != "vm" {
+		fmt.Fprintf(os.Stderr, "unknown engine: %s\n", *engine)
+		return 2
+	}
+
+	file := flags.Arg(0)
+	content, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	var result object.Object
+	source := string(content)
+	if filepath.Ext(file) == BYTECODE_EXTENSION {
+		source = ""  // Nothing to show on the traces
+		if *engine != "vm" && isFlagSet(flags, "engine") {
+			fmt.Fprintf(os.Stderr, "%s: compiled files only run on the vm engine\n", file)
+			return 2
+		}
+
+		bytecode, err := compiler.Decode(content)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", file, err)
+			return 1
+		}
+		machine := vm.New(bytecode)
+		machine.SetImporter(evaluator.NewModules(file).Importer())
+		machine.SetLimits(limits)
+		result = machine.Run()
+	} else {
+		program, ok := parseFile(file, string(content))
+		if !ok {
+			return 1
+		}
+
+		result, err = execute(file, program, *engine, limits)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: error: %s\n", file, err)
+			return 1
+		}
+	}
+
+	if result == nil || result == evaluator.NIL {
+		return 0
+	}
+	if err, ok := result.(*object.Error); ok {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", file, err.Inspect())
+		printTrace(os.Stderr, err, source)
+		return 1
+	}
+	fmt.Println(result.Inspect())
+	return 0
+}
+
+/*
+printTrace shows the line where the error happened and then the calls it went out
+of, the innermost first, each one with the line it was called from. Without the
+source (a .mytec file) only the calls are shown. A deep recursion goes out of the
+same call thousands of times, so a frame that repeats is shown once with how many
+times it did, and of the rest only the first and last TRACE_EDGE are shown.
+*/
+func printTrace(w io.Writer, err *object.Error, source string) {
+	var lines []string
+	if source != "" {
+		lines = strings.Split(source, "\n")
+	}
+	excerpt := func(line int) {
+		if line < len(lines) {
+			fmt.Fprintf(w, "    %4d | %s\n", line, strings.TrimSpace(lines[line]))
+		}
+	}
+
+	excerpt(err.Line)
+	groups := groupFrames(err.Trace)
+	for i, group := range groups {
+		if len(groups) > 2*TRACE_EDGE && i >= TRACE_EDGE && i < len(groups)-TRACE_EDGE {
+			if i == TRACE_EDGE {
+				hidden := 0
+				for _, g := range groups[TRACE_EDGE : len(groups)-TRACE_EDGE] {
+					hidden += g.count
+				}
+				fmt.Fprintf(w, "  ... %d more calls\n", hidden)
+			}
+			continue
+		}
+
+		frame := group.frame
+		fmt.Fprintf(w, "  in %s, called at line %d, column %d\n", frame.Name(), frame.Line, frame.Column)
+		excerpt(frame.Line)
+		if group.count > 1 {
+			fmt.Fprintf(w, "  ... previous frame repeated %d more times\n", group.count-1)
+		}
+	}
+}
+
+const TRACE_EDGE = 10
+
+type frameGroup struct {
+	frame object.TraceFrame
+	count int
+}
+
+// groupFrames puts together the frames that follow one another and are the same call
+func groupFrames(trace []object.TraceFrame) []frameGroup {
+	var groups []frameGroup
+	for _, frame := range trace {
+		if len(groups) > 0 && groups[len(groups)-1].frame == frame {
+			groups[len(groups)-1].count++
+			continue
+		}
+		groups = append(groups, frameGroup{frame, 1})
+	}
+	return groups
+}
+
+// Both engines give back the same things; the error is for what the vm cannot compile
+func execute(file string, program *ast.Program, engine string, limits object.Limits) (object.Object, error) {
+	if engine == "eval" {
+		env := object.NewEnvironment()
+		env.SetImporter(evaluator.NewModules(file).Importer())
+		env.SetLimits(limits)
+		return evaluator.Eval(program, env), nil
+	}
+
+	bytecode, err := compile(program)
+	if err != nil {
+		return nil, err
+	}
+	machine := vm.New(bytecode)
+	machine.SetImporter(evaluator.NewModules(file).Importer())
+	machine.SetLimits(limits)
+	return machine.Run(), nil
+}
+
+// Whatever goes to the vm is optimized first. The findings are for myte check.
+func compile(program *ast.Program) (*compiler.Bytecode, error) {
+	optimized, _ := optimize.Program(program)
+
+	c := compiler.New()
+	if err := c.Compile(optimized); err != nil {
+		return nil, err
+	}
+	return c.Bytecode(), nil
+}
+
+const BYTECODE_EXTENSION = ".mytec"
+
+// runBuild compiles a file into bytecode, so running it later skips the parser
+func runBuild(args []string) int {
+	flags := flag.NewFlagSet("build", flag.ContinueOnError)
+	output := flags.String("o", "", "where to write the bytecode (file.mytec by default)")
+
+	// The flag package stops on the first file name, but "build file -o out" is
+	// the natural way to write it
+	var files []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return 2
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		files = append(files, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+	if len(files) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: myte build <file> [-o <output>]")
+		return 2
+	}
+
+	file := files[0]
+	if *output == "" {
+		*output = strings.TrimSuffix(file, filepath.Ext(file)) + BYTECODE_EXTENSION
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	program, ok := parseFile(file, string(content))
+	if !ok {
+		return 1
+	}
+
+	bytecode, err := compile(program)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: error: %s\n", file, err)
+		return 1
+	}
+	data, err := bytecode.Encode()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: error: %s\n", file, err)
+		return 1
+	}
+
+	if err := os.WriteFile(*output, data, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// runDisasm prints the bytecode of a program, compiling it first when it's source code
+func runDisasm(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: myte disasm <file>")
+		return 2
+	}
+
+	file := args[0]
+	content, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if filepath.Ext(file) == BYTECODE_EXTENSION {
+		bytecode, err := compiler.Decode(content)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", file, err)
+			return 1
+		}
+		compiler.Disassemble(os.Stdout, bytecode, "")
+		return 0
+	}
+
+	program, ok := parseFile(file, string(content))
+	if !ok {
+		return 1
+	}
+	bytecode, err := compile(program)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: error: %s\n", file, err)
+		return 1
+	}
+	compiler.Disassemble(os.Stdout, bytecode, string(content))
+	return 0
+}
+
+// runBuiltins documents the builtin functions: all of them, or only the ones named.
+// The name of a standard module stands for every function it has.
+func runBuiltins(args []string) int {
+	list := evaluator.Builtins()
+	if len(args) > 0 {
+		list = nil
+		for _, name := range args {
+			if builtin, ok := evaluator.LookupBuiltin(name); ok {
+				list = append(list, builtin)
+			} else if module, ok := evaluator.LookupStdModule(name); ok {
+				list = append(list, module.Functions...)
+			} else {
+				fmt.Fprintf(os.Stderr, "no builtin or module called: %s\n", name)
+				return 1
+			}
+		}
+	}
+
+	for _, builtin := range list {
+		fmt.Printf("%s\n\t%s\n", builtin.Signature(), builtin.Doc)
+	}
+	return 0
+}
+
+func parseFile(file, content string) (*ast.Program, bool) {
+	p := parser.New(lexer.New(content))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		for _, err := range p.Errors() {
+			fmt.Fprintf(os.Stderr, "%s: error: %s\n", file, err)
+		}
+		return nil, false
+	}
+	return program, true
+}
+
+func isFlagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/santos-404/myte/object"
+)
+
+func TestPrintTraceCollapsesRepeatedFrames(t *testing.T) {
+	trace := []object.TraceFrame{}
+	for i := 0; i < 5000; i++ {
+		trace = append(trace, object.TraceFrame{Function: "f", Line: 2, Column: 10})
+	}
+	trace = append(trace, object.TraceFrame{Function: "f", Line: 4, Column: 2})
+	err := &object.Error{Message: "division by zero", Line: 1, Column: 26, Trace: trace}
+
+	var out bytes.Buffer
+	printTrace(&out, err, "const f = fn(n) {\n\tif n == 0 { return 1 / 0; }\n\t1 + f(n - 1)\n};\nf(5000)")
+
+	expected := `       1 | if n == 0 { return 1 / 0; }
+  in f, called at line 2, column 10
+       2 | 1 + f(n - 1)
+  ... previous frame repeated 4999 more times
+  in f, called at line 4, column 2
+       4 | f(5000)
+`
+	if out.String() != expected {
+		t.Errorf("wrong trace.\nexpected=%s\ngot=%s", expected, out.String())
+	}
+}
+
+// When the calls don't repeat one after another (a calls b calls a...) only the
+// first and the last ones are shown
+func TestPrintTraceShowsTheEnds(t *testing.T) {
+	trace := []object.TraceFrame{}
+	for i := 0; i < 30; i++ {
+		trace = append(trace, object.TraceFrame{Function: []string{"a", "b"}[i%2], Line: i})
+	}
+	err := &object.Error{Message: "bad", Trace: trace}
+
+	var out bytes.Buffer
+	printTrace(&out, err, "")
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2*TRACE_EDGE+1 {
+		t.Fatalf("wrong number of lines. expected=%d, got=%d:\n%s", 2*TRACE_EDGE+1, len(lines), out.String())
+	}
+	if lines[0] != "  in a, called at line 0, column 0" || lines[len(lines)-1] != "  in b, called at line 29, column 0" {
+		t.Errorf("wrong ends. got=%q and %q", lines[0], lines[len(lines)-1])
+	}
+	if lines[TRACE_EDGE] != "  ... 10 more calls" {
+		t.Errorf("wrong hidden line. got=%q", lines[TRACE_EDGE])
+	}
+}
@@ -0,0 +1,62 @@
+package myte
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/santos-404/myte/evaluator"
+	"github.com/santos-404/myte/object"
+)
+
+// ParseError is what a source that doesn't parse gives, with every error found in it
+type ParseError struct {
+	File   string  // Empty for the ones given to Eval
+	Errors []string
+}
+
+func (e *ParseError) Error() string {
+	if e.File == "" {
+		return strings.Join(e.Errors, "\n")
+	}
+
+	lines := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		lines[i] = fmt.Sprintf("%s: error: %s", e.File, err)
+	}
+	return strings.Join(lines, "\n")
+}
+
+/*
+RuntimeError is what a program that fails gives, and also what an error value of
+the program becomes in Go. The kind is the one a catch would see, like
+"zero_division", or one of the limit ones (evaluator.STEP_LIMIT_KIND...). Lines
+start from 0, like everywhere else.
+*/
+type RuntimeError struct {
+	Message string
+	Kind    string
+	Line    int
+	Column  int
+	Trace   []object.TraceFrame  // The calls it went out of, the innermost first
+}
+
+func (e *RuntimeError) Error() string {
+	return fmt.Sprintf("%s. Line: %d, column: %d", e.Message, e.Line, e.Column)
+}
+
+func newRuntimeError(err *object.Error) *RuntimeError {
+	return fromErrorValue(evaluator.CaughtError(err))
+}
+
+func fromErrorValue(err *object.ErrorValue) *RuntimeError {
+	return &RuntimeError{Message: err.Message, Kind: err.Kind, Line: err.Line, Column: err.Column, Trace: err.Trace}
+}
+
+// ExitError is what a program that called exit() gives, with the code it gave
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
@@ -0,0 +1,196 @@
+/*
+Package myte runs Myte programs from Go. An Interpreter keeps its bindings from one
+Eval to the next, the way the REPL does, so a program can be loaded once and its
+functions called later on with Call.
+
+Every Interpreter has bindings of its own, so several of them can run at the same
+time. One Interpreter must not be used from more than one goroutine at once though;
+the functions given with RegisterFunc can call back into it, they run on the same
+goroutine. Each one has its own stdout and stdin too (the process' ones unless the
+options say otherwise), and exit() gives the host an *ExitError instead of ending
+the process.
+*/
+package myte
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/santos-404/myte/evaluator"
+	"github.com/santos-404/myte/lexer"
+	"github.com/santos-404/myte/object"
+	"github.com/santos-404/myte/parser"
+	"github.com/santos-404/myte/token"
+)
+
+type Interpreter struct {
+	env      *object.Environment
+	io       *object.IO
+	exitCode int      // What the program gave to exit() last
+	onExit   func(code int)
+
+	// The modules are kept from one run to the next, so a module runs only once even
+	// when several files import it. Each file imports from its own directory.
+	modules *evaluator.Modules
+}
+
+type Option func(*Interpreter)
+
+// WithLimits bounds every Eval and Call on its own: the steps start from zero each
+// time, but the context is the same for all of them
+func WithLimits(limits object.Limits) Option {
+	return func(i *Interpreter) {
+		i.env.SetLimits(limits)
+	}
+}
+
+// WithStdout is where print writes
+func WithStdout(w io.Writer) Option {
+	return func(i *Interpreter) {
+		i.io.Stdout = w
+	}
+}
+
+// WithStdin is where input reads from
+func WithStdin(r io.Reader) Option {
+	return func(i *Interpreter) {
+		i.io.Stdin = bufio.NewReader(r)
+	}
+}
+
+// WithExit is called with the code when the program calls exit(). The program stops
+// once it returns, and Eval (or Call) gives back an *ExitError all the same.
+func WithExit(fn func(code int)) Option {
+	return func(i *Interpreter) {
+		i.onExit = fn
+	}
+}
+
+func New(opts ...Option) *Interpreter {
+	i := &Interpreter{
+		env:     object.NewEnvironment(),
+		modules: evaluator.NewModules(""),
+	}
+	i.io = object.NewIO(os.Stdout, os.Stdin, func(code int) {
+		i.exitCode = code
+		if i.onExit != nil {
+			i.onExit(code)
+		}
+	})
+	i.env.SetIO(i.io)
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i
+}
+
+// Eval runs the source and gives back the value it ends with. Its imports are
+// looked for from the working directory New was called in.
+func (i *Interpreter) Eval(src string) (any, error) {
+	return i.eval("", src)
+}
+
+// EvalFile is Eval, but the imports are looked for next to the file
+func (i *Interpreter) EvalFile(path string) (any, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return i.eval(path, string(content))
+}
+
+func (i *Interpreter) eval(file, src string) (any, error) {
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, &ParseError{File: file, Errors: p.Errors()}
+	}
+
+	i.start()
+	i.env.SetImporter(i.modules.ProgramImporter(file))
+	return i.result(evaluator.Eval(program, i.env))
+}
+
+// Set binds the name to the value, once it's made a Myte one (see toObject)
+func (i *Interpreter) Set(name string, value any) error {
+	obj, err := toObject(value)
+	if err != nil {
+		return err
+	}
+	return i.set(name, obj)
+}
+
+func (i *Interpreter) set(name string, obj object.Object) error {
+	if evaluator.IsBuiltin(name) {
+		return fmt.Errorf("cannot redeclare builtin: %s", name)
+	}
+	i.env.Set(name, obj)
+	return nil
+}
+
+// Get gives back the value bound to the name, made a Go one (see toValue)
+func (i *Interpreter) Get(name string) (any, bool) {
+	obj, ok := i.env.Get(name)
+	if !ok {
+		return nil, false
+	}
+	return toValue(obj), true
+}
+
+// Call calls a function of the program (or a builtin) with the arguments made Myte
+// values, and gives back what it returns
+func (i *Interpreter) Call(fnName string, args ...any) (any, error) {
+	fn, ok := i.env.Get(fnName)
+	if builtin, isBuiltin := evaluator.LookupBuiltin(fnName); isBuiltin {
+		fn, ok = builtin.Object(), true
+	}
+	if !ok {
+		return nil, fmt.Errorf("function not found: %s", fnName)
+	}
+	if fn.Type() != object.FUNCTION_OBJ {
+		return nil, fmt.Errorf("not a function: %s", fnName)
+	}
+
+	objects := make([]object.Object, len(args))
+	for j, arg := range args {
+		obj, err := toObject(arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %w", j+1, err)
+		}
+		objects[j] = obj
+	}
+
+	i.start()
+	return i.result(evaluator.ApplyFunction(token.Token{}, fn, objects, i.env.Usage()))
+}
+
+// Func is a Go function a program can call. An error stops the program the way any
+// runtime error does (a try can catch it); to give the program an error value it
+// can check instead, return the error as the value.
+type Func func(args ...any) (any, error)
+
+// RegisterFunc makes fn callable from the programs as name. Builtins cannot be
+// replaced.
+func (i *Interpreter) RegisterFunc(name string, fn Func) error {
+	return i.set(name, goFunction(name, fn))
+}
+
+// The steps are counted per run. A Call made from a registered function is part of
+// the run that called it, so it doesn't start again.
+func (i *Interpreter) start() {
+	if usage := i.env.Usage(); usage != nil && usage.Depth == 0 {
+		usage.Steps = 0
+	}
+}
+
+func (i *Interpreter) result(obj object.Object) (any, error) {
+	if err, ok := obj.(*object.Error); ok {
+		if err.Kind == evaluator.EXIT_KIND {
+			return nil, &ExitError{Code: i.exitCode}
+		}
+		return nil, newRuntimeError(err)
+	}
+	return toValue(obj), nil
+}
@@ -0,0 +1,445 @@
+package myte
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/santos-404/myte/evaluator"
+	"github.com/santos-404/myte/object"
+)
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"1 + 2", int64(3)},
+		{"7 / 2", 3.5},
+		{`"my" + "te"`, "myte"},
+		{"1 < 2", true},
+		{"nil", nil},
+		{"var x = 1;", nil},
+		{"[1, 'a', [true]]", []any{int64(1), "a", []any{true}}},
+		{"{'a': 1, 2: [3]}", map[any]any{"a": int64(1), int64(2): []any{int64(3)}}},
+		{"error('bad')", &RuntimeError{Message: "bad", Kind: "error", Line: 0, Column: 6}},
+	}
+
+	for _, tt := range tests {
+		result, err := New().Eval(tt.input)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("%q: wrong result. expected=%#v, got=%#v", tt.input, tt.expected, result)
+		}
+	}
+}
+
+// The bindings stay from one Eval to the next
+func TestEvalKeepsBindings(t *testing.T) {
+	interpreter := New()
+	if _, err := interpreter.Eval("var count = 1; const double = fn(n) { n * 2 };"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	result, err := interpreter.Eval("count += 1; double(count)")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result != int64(4) {
+		t.Errorf("wrong result. expected=4, got=%#v", result)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	_, err := New().Eval("var = 1; var y 2;")
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected a *ParseError, got=%T (%v)", err, err)
+	}
+	if len(parseErr.Errors) != 2 {
+		t.Errorf("wrong number of errors. expected=2, got=%d (%v)", len(parseErr.Errors), parseErr.Errors)
+	}
+}
+
+func TestRuntimeError(t *testing.T) {
+	_, err := New().Eval("var half = fn(n) { n / 0 };\nhalf(4)")
+
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) {
+		t.Fatalf("expected a *RuntimeError, got=%T (%v)", err, err)
+	}
+	if runtimeErr.Message != "division by zero" || runtimeErr.Kind != "zero_division" {
+		t.Errorf("wrong error. got=%q (%s)", runtimeErr.Message, runtimeErr.Kind)
+	}
+	if runtimeErr.Line != 0 || len(runtimeErr.Trace) != 1 || runtimeErr.Trace[0].Function != "half" {
+		t.Errorf("wrong place. got line %d, trace %+v", runtimeErr.Line, runtimeErr.Trace)
+	}
+	if err.Error() != "division by zero. Line: 0, column: 22" {
+		t.Errorf("wrong message. got=%q", err.Error())
+	}
+}
+
+func TestSetAndGet(t *testing.T) {
+	interpreter := New()
+	values := map[string]any{
+		"i":   42,
+		"u":   uint8(7),
+		"f":   float32(1.5),
+		"s":   "text",
+		"b":   true,
+		"n":   nil,
+		"a":   []string{"x", "y"},
+		"m":   map[string]int{"b": 2, "a": 1},
+		"p":   &[]int{1},
+		"err": errors.New("it broke"),
+	}
+	for name, value := range values {
+		if err := interpreter.Set(name, value); err != nil {
+			t.Fatalf("%s: unexpected error: %s", name, err)
+		}
+	}
+
+	result, err := interpreter.Eval(`[i + u, f * 2, s, !b, n, a[1], keys(m), p[0], err.message]`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []any{int64(49), 3.0, "text", false, nil, "y", []any{"a", "b"}, int64(1), "it broke"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("wrong result. expected=%#v, got=%#v", expected, result)
+	}
+
+	if value, ok := interpreter.Get("s"); !ok || value != "text" {
+		t.Errorf("wrong value for s. got=%#v (%t)", value, ok)
+	}
+	if _, ok := interpreter.Get("missing"); ok {
+		t.Errorf("got a value for a name that was never set")
+	}
+}
+
+func TestSetErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{"len", 1, "cannot redeclare builtin: len"},
+		{"x", uint64(1 << 63), "9223372036854775808 does not fit in an int"},
+		{"x", struct{}{}, "cannot use struct {} as a myte value"},
+		{"x", map[float64]int{1.5: 1}, "cannot use float as a map key"},
+		{"x", func() {}, "cannot use func() as a myte value"},
+		{"x", selfContaining(), "cannot use a value that contains itself"},
+	}
+
+	for _, tt := range tests {
+		err := New().Set(tt.name, tt.value)
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("%s = %#v: wrong error. expected=%q, got=%v", tt.name, tt.value, tt.expected, err)
+		}
+	}
+}
+
+func selfContaining() any {
+	values := []any{1, nil}
+	values[1] = map[string]any{"back": values}
+	return values
+}
+
+// The same value twice is fine, as long as it's not inside of itself
+func TestSetSharedValue(t *testing.T) {
+	shared := []int{1, 2}
+	interpreter := New()
+	if err := interpreter.Set("pair", [][]int{shared, shared}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result, _ := interpreter.Eval("pair[0] == pair[1]"); result != true {
+		t.Errorf("wrong result. got=%#v", result)
+	}
+}
+
+func TestCall(t *testing.T) {
+	interpreter := New()
+	_, err := interpreter.Eval(`
+const add = fn(a, b) { a + b };
+const count = fn(n, acc) { if n == 0 { return acc; }; return count(n - 1, acc + 1); };
+const fail = fn() { throw 'nope' };
+const value = 1;`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		fn       string
+		args     []any
+		expected any
+		err      string
+	}{
+		{"add", []any{1, 2}, int64(3), ""},
+		{"add", []any{"a", "b"}, "ab", ""},
+		{"count", []any{10000, 0}, int64(10000), ""},
+		{"len", []any{[]int{1, 2, 3}}, int64(3), ""},
+		{"add", []any{1}, nil, "wrong number of arguments: want=2, got=1. Line: 0, column: 0"},
+		{"fail", nil, nil, "nope. Line: 3, column: 21"},
+		{"value", nil, nil, "not a function: value"},
+		{"missing", nil, nil, "function not found: missing"},
+		{"add", []any{struct{}{}, 1}, nil, "argument 1: cannot use struct {} as a myte value"},
+	}
+
+	for _, tt := range tests {
+		result, err := interpreter.Call(tt.fn, tt.args...)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%s%v: wrong error. expected=%q, got=%v", tt.fn, tt.args, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s%v: unexpected error: %s", tt.fn, tt.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("%s%v: wrong result. expected=%#v, got=%#v", tt.fn, tt.args, tt.expected, result)
+		}
+	}
+}
+
+func TestRegisterFunc(t *testing.T) {
+	interpreter := New()
+	err := interpreter.RegisterFunc("lookup", func(args ...any) (any, error) {
+		name, ok := args[0].(string)
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("lookup: the name must be a string")
+		case name == "missing":
+			return errors.New("no such user"), nil  // An error value, the program can check it
+		}
+		return map[string]any{"name": name, "age": 30}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := interpreter.RegisterFunc("len", nil); err == nil {
+		t.Errorf("a builtin could be replaced")
+	}
+
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{"lookup('ana')['age'] + 1", int64(31)},
+		{"type(lookup)", "fn"},
+		{"var found = lookup('missing'); [type(found), found.message]", []any{"error", "no such user"}},
+		{"try { lookup(1) } catch e { e.message }", "lookup: the name must be a string"},
+	}
+	for _, tt := range tests {
+		result, err := interpreter.Eval(tt.input)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("%q: wrong result. expected=%#v, got=%#v", tt.input, tt.expected, result)
+		}
+	}
+
+	// Going back into the interpreter from Go is fine too
+	interpreter.RegisterFunc("twice", func(args ...any) (any, error) {
+		once, err := interpreter.Call("lookup", args[0])
+		if err != nil {
+			return nil, err
+		}
+		return []any{once, once}, nil
+	})
+	if result, err := interpreter.Eval("len(twice('bo'))"); err != nil || result != int64(2) {
+		t.Errorf("wrong result for twice. got=%#v (%v)", result, err)
+	}
+}
+
+// The kind of an error is where it comes from, not what its message looks like
+func TestRegisteredFuncErrorKind(t *testing.T) {
+	interpreter := New()
+	interpreter.RegisterFunc("feed", func(args ...any) (any, error) {
+		return nil, errors.New("division by zero in the feed")
+	})
+
+	result, err := interpreter.Eval("try { feed() } catch e { e.kind }")
+	if err != nil || result != evaluator.RUNTIME_KIND {
+		t.Errorf("wrong kind. expected=%q, got=%#v (%v)", evaluator.RUNTIME_KIND, result, err)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	interpreter := New(WithLimits(object.Limits{MaxSteps: 1000, MaxCallDepth: 50}))
+
+	_, err := interpreter.Eval("for true { 1 }")
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) || runtimeErr.Kind != evaluator.STEP_LIMIT_KIND {
+		t.Fatalf("expected a step limit error, got=%v", err)
+	}
+
+	// Every run gets all of its steps again
+	if _, err := interpreter.Eval("const f = fn(n) { if n == 0 { return 0; }; 1 + f(n - 1) };"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for i := 0; i < 10; i++ {
+		if _, err := interpreter.Call("f", 40); err != nil {
+			t.Fatalf("run %d: unexpected error: %s", i, err)
+		}
+	}
+
+	_, err = interpreter.Call("f", 100)
+	if !errors.As(err, &runtimeErr) || runtimeErr.Kind != evaluator.DEPTH_LIMIT_KIND {
+		t.Errorf("expected a depth limit error, got=%v", err)
+	}
+}
+
+func TestEvalFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.myte":  "import \"lib/greet\";\ngreet.hello('file')",
+		"lib/greet.myte": "const hello = fn(name) { 'hello ' + name };",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := New().EvalFile(filepath.Join(dir, "main.myte"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result != "hello file" {
+		t.Errorf("wrong result. expected=%q, got=%#v", "hello file", result)
+	}
+
+	if _, err := New().EvalFile(filepath.Join(dir, "missing.myte")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the file not to exist, got=%v", err)
+	}
+}
+
+func TestIO(t *testing.T) {
+	var out bytes.Buffer
+	interpreter := New(WithStdout(&out), WithStdin(strings.NewReader("Ada\n")))
+
+	result, err := interpreter.Eval("println('hi', input('name? ')); 'done'")
+	if err != nil || result != "done" {
+		t.Fatalf("wrong result. got=%#v (%v)", result, err)
+	}
+	if out.String() != "name? hi Ada\n" {
+		t.Errorf("wrong output. got=%q", out.String())
+	}
+}
+
+// exit() is for the host to deal with, it doesn't end the process
+func TestExit(t *testing.T) {
+	code := -1
+	interpreter := New(WithExit(func(c int) { code = c }))
+
+	_, err := interpreter.Eval("try { exit(3) } catch e { 'caught' }")
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 3 {
+		t.Fatalf("expected an exit error with code 3, got=%v", err)
+	}
+	if code != 3 {
+		t.Errorf("the exit hook was not called. got=%d", code)
+	}
+
+	if _, err := New().Eval("exit()"); !errors.As(err, &exitErr) || exitErr.Code != 0 {
+		t.Errorf("expected an exit error with code 0, got=%v", err)
+	}
+	// The interpreter can still be used after it
+	if result, err := interpreter.Eval("1 + 1"); err != nil || result != int64(2) {
+		t.Errorf("wrong result after exit. got=%#v (%v)", result, err)
+	}
+}
+
+// A module runs once, and the next Eval gets the same one
+func TestModulesAreKept(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "counter.myte"), []byte("var n = 0; const inc = fn() { n += 1; n };"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	interpreter := New()
+	for expected := int64(1); expected <= 3; expected++ {
+		result, err := interpreter.Eval(`import "counter"; counter.inc()`)
+		if err != nil || result != expected {
+			t.Errorf("wrong result. expected=%d, got=%#v (%v)", expected, result, err)
+		}
+	}
+}
+
+// Two files that import the same module share it, each one finding it from its own directory
+func TestModulesAreSharedByFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"shared/counter.myte": "print('loaded'); var n = 0; const inc = fn() { n += 1; n };",
+		"a/main.myte":         "import '../shared/counter'; counter.inc()",
+		"b/main.myte":         "import '../shared/counter'; counter.inc()",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	interpreter := New(WithStdout(&out))
+	for expected, file := range []string{"a/main.myte", "b/main.myte"} {
+		result, err := interpreter.EvalFile(filepath.Join(dir, file))
+		if err != nil || result != int64(expected+1) {
+			t.Errorf("%s: wrong result. expected=%d, got=%#v (%v)", file, expected+1, result, err)
+		}
+	}
+	if out.String() != "loaded" {
+		t.Errorf("the module must run once. got output=%q", out.String())
+	}
+}
+
+// Every interpreter has its own bindings, so they can all run at once
+func TestConcurrentInterpreters(t *testing.T) {
+	var wg sync.WaitGroup
+	results := make([]any, 16)
+	errs := make([]error, 16)
+
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var out bytes.Buffer
+			interpreter := New(WithLimits(object.Limits{MaxSteps: 100000}), WithStdout(&out),
+				WithStdin(strings.NewReader(fmt.Sprintln(i))))
+			interpreter.Set("n", i)
+			interpreter.RegisterFunc("offset", func(args ...any) (any, error) { return 1000, nil })
+			results[i], errs[i] = interpreter.Eval("var total = 0; var j = 0; for j < n { total += j; ++j; }; print(input()); total + offset()")
+			if out.String() != fmt.Sprint(i) {
+				errs[i] = fmt.Errorf("wrong output: %q", out.String())
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, result := range results {
+		expected := int64(i*(i-1)/2 + 1000)
+		if errs[i] != nil || result != expected {
+			t.Errorf("interpreter %d: expected=%d, got=%#v (%v)", i, expected, result, errs[i])
+		}
+	}
+}
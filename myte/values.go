@@ -0,0 +1,170 @@
+package myte
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/santos-404/myte/evaluator"
+	"github.com/santos-404/myte/object"
+)
+
+/*
+toObject makes a Go value a Myte one. Every kind of int becomes an int (as long as
+it fits) and every kind of float a float. Slices and arrays become arrays, and maps
+become maps with their keys sorted, since Go doesn't keep them in any order. Errors
+become error values and a Func a function. Pointers are followed. The Myte values
+themselves (object.Object) are kept as they are. A value that contains itself
+cannot be made a Myte one, there would be no end to it.
+*/
+func toObject(value any) (object.Object, error) {
+	return convert(value, make(map[visit]bool))
+}
+
+// The slices, maps and pointers convert is going through right now, to find out
+// when one of them contains itself
+type visit struct {
+	pointer uintptr
+	typ     reflect.Type
+}
+
+func convert(value any, visiting map[visit]bool) (object.Object, error) {
+	switch value := value.(type) {
+	case nil:
+		return evaluator.NIL, nil
+	case object.Object:
+		return value, nil
+	case Func:
+		return goFunction("go fn", value), nil
+	case func(args ...any) (any, error):
+		return goFunction("go fn", value), nil
+	case *RuntimeError:
+		return &object.ErrorValue{Message: value.Message, Kind: value.Kind, Line: value.Line, Column: value.Column, Trace: value.Trace}, nil
+	case error:
+		return &object.ErrorValue{Message: value.Error(), Kind: evaluator.ERROR_KIND}, nil
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Pointer:
+		if v.Pointer() != 0 {
+			key := visit{v.Pointer(), v.Type()}
+			if visiting[key] {
+				return nil, fmt.Errorf("cannot use a value that contains itself")
+			}
+			visiting[key] = true
+			defer delete(visiting, key)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return evaluator.NativeBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &object.Integer{Value: v.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > math.MaxInt64 {
+			return nil, fmt.Errorf("%d does not fit in an int", v.Uint())
+		}
+		return &object.Integer{Value: int64(v.Uint())}, nil
+	case reflect.Float32, reflect.Float64:
+		return &object.Float{Value: v.Float()}, nil
+	case reflect.String:
+		return &object.String{Value: v.String()}, nil
+	case reflect.Slice, reflect.Array:
+		elements := make([]object.Object, v.Len())
+		for i := range elements {
+			element, err := convert(v.Index(i).Interface(), visiting)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = element
+		}
+		return &object.Array{Elements: elements}, nil
+	case reflect.Map:
+		return mapToObject(v, visiting)
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return evaluator.NIL, nil
+		}
+		return convert(v.Elem().Interface(), visiting)
+	}
+	return nil, fmt.Errorf("cannot use %T as a myte value", value)
+}
+
+func mapToObject(v reflect.Value, visiting map[visit]bool) (object.Object, error) {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+
+	m := object.NewMap()
+	for _, key := range keys {
+		k, err := convert(key.Interface(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		value, err := convert(v.MapIndex(key).Interface(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		if !m.Set(k, value) {
+			return nil, fmt.Errorf("cannot use %s as a map key", k.Type())
+		}
+	}
+	return m, nil
+}
+
+/*
+toValue makes a Myte value a Go one: an int64, a float64, a string, a bool or nil.
+Arrays become []any and maps map[any]any (their keys can only be strings, ints or
+bools). Error values become a *RuntimeError. Whatever has no Go counterpart, like a
+function or a module, is given back as it is, so it can be passed back in.
+*/
+func toValue(obj object.Object) any {
+	switch obj := obj.(type) {
+	case nil, *object.Nil:
+		return nil
+	case *object.Integer:
+		return obj.Value
+	case *object.Float:
+		return obj.Value
+	case *object.String:
+		return obj.Value
+	case *object.Boolean:
+		return obj.Value
+	case *object.Array:
+		values := make([]any, len(obj.Elements))
+		for i, element := range obj.Elements {
+			values[i] = toValue(element)
+		}
+		return values
+	case *object.Map:
+		values := make(map[any]any, len(obj.Keys))
+		for _, key := range obj.Keys {
+			mapKey, _ := object.KeyOf(key)
+			values[toValue(key)] = toValue(obj.Pairs[mapKey])
+		}
+		return values
+	case *object.ErrorValue:
+		return fromErrorValue(obj)
+	}
+	return obj
+}
+
+// goFunction wraps fn so the programs can call it like any builtin
+func goFunction(name string, fn Func) *object.Builtin {
+	return &object.Builtin{Name: name, Fn: func(args []object.Object) (object.Object, error) {
+		values := make([]any, len(args))
+		for i, arg := range args {
+			values[i] = toValue(arg)
+		}
+
+		result, err := fn(values...)
+		if err != nil {
+			return nil, err
+		}
+		return toObject(result)
+	}}
+}
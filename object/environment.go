@@ -0,0 +1,125 @@
+package object
+
+import "sort"
+
+type Environment struct {
+	store    map[string]Object
+	consts   map[string]bool
+	outer    *Environment
+	importer Importer  // Only set on the environment of a file, the enclosed ones ask it
+	usage    *Usage    // The enclosed ones share it. Even without limits, the depth is kept.
+}
+
+func NewEnvironment() *Environment {
+	return &Environment{
+		store:  make(map[string]Object),
+		consts: make(map[string]bool),
+		usage:  &Usage{},
+	}
+}
+
+// Every function call gets one of these, so it can see the bindings around it
+func NewEnclosedEnvironment(outer *Environment) *Environment {
+	return &Environment{
+		store:  make(map[string]Object),
+		consts: make(map[string]bool),
+		outer:  outer,
+		usage:  outer.usage,
+	}
+}
+
+// SetLimits must be called before running anything, the environments that already
+// exist don't see them
+func (e *Environment) SetLimits(limits Limits) {
+	e.usage = &Usage{Limits: limits, IO: e.usage.IO}
+}
+
+// SetIO is like SetLimits, it must be called before running anything
+func (e *Environment) SetIO(io *IO) {
+	e.usage.IO = io
+}
+
+// The modules a program imports run within the limits of the program
+func (e *Environment) ShareUsage(usage *Usage) {
+	e.usage = usage
+}
+
+func (e *Environment) Usage() *Usage {
+	return e.usage
+}
+
+func (e *Environment) SetImporter(importer Importer) {
+	e.importer = importer
+}
+
+func (e *Environment) Importer() Importer {
+	for current := e; current != nil; current = current.outer {
+		if current.importer != nil {
+			return current.importer
+		}
+	}
+	return nil
+}
+
+func (e *Environment) Get(name string) (Object, bool) {
+	obj, ok := e.store[name]
+	if !ok && e.outer != nil {
+		return e.outer.Get(name)
+	}
+	return obj, ok
+}
+
+// GetOwn only looks at this environment. That's how the members of a module are found.
+func (e *Environment) GetOwn(name string) (Object, bool) {
+	obj, ok := e.store[name]
+	return obj, ok
+}
+
+// Set declares the binding on this environment, hiding any outer one with the same name
+func (e *Environment) Set(name string, val Object) Object {
+	e.store[name] = val
+	delete(e.consts, name)
+	return val
+}
+
+func (e *Environment) SetConst(name string, val Object) Object {
+	e.store[name] = val
+	e.consts[name] = true
+	return val
+}
+
+// Assign updates an existing binding wherever it was declared. The second value tells
+// whether the binding exists and the third one whether it can be updated at all.
+func (e *Environment) Assign(name string, val Object) (Object, bool, bool) {
+	if _, ok := e.store[name]; ok {
+		if e.consts[name] {
+			return nil, true, false
+		}
+		e.store[name] = val
+		return val, true, true
+	}
+	if e.outer != nil {
+		return e.outer.Assign(name, val)
+	}
+	return nil, false, false
+}
+
+func (e *Environment) IsConst(name string) bool {
+	if _, ok := e.store[name]; ok {
+		return e.consts[name]
+	}
+	if e.outer != nil {
+		return e.outer.IsConst(name)
+	}
+	return false
+}
+
+// Names returns the bindings declared on this environment (not the outer ones), sorted
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.store))
+	for name := range e.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,22 @@
+package object
+
+import (
+	"bufio"
+	"io"
+)
+
+/*
+IO is where the builtins that talk to the outside world go: print writes to Stdout,
+input reads from Stdin and exit calls Exit. Every program can have its own, so the
+ones running at the same time don't share any. Without an Exit, exit() stops the
+program with an error instead of the whole process.
+*/
+type IO struct {
+	Stdout io.Writer
+	Stdin  *bufio.Reader  // Kept from one input() to the next, what it read ahead is still there
+	Exit   func(code int)
+}
+
+func NewIO(stdout io.Writer, stdin io.Reader, exit func(code int)) *IO {
+	return &IO{Stdout: stdout, Stdin: bufio.NewReader(stdin), Exit: exit}
+}
@@ -0,0 +1,26 @@
+package object
+
+import "context"
+
+/*
+Limits bound what a program may do, for the programs we don't trust. A zero (or a
+nil Context) is no limit at all. What counts as a step depends on the engine: the
+evaluator counts every turn of a loop and every call, which is what can keep a
+program running, and the vm counts instructions.
+*/
+type Limits struct {
+	MaxSteps          int
+	MaxCallDepth      int              // Calls going on at the same time
+	MaxCollectionSize int              // Elements of an array, pairs of a map or bytes of a string
+	Context           context.Context  // The program stops once it's done
+}
+
+// Usage is what a program keeps while it runs, shared by all of its environments and
+// modules: how much of its limits it has used so far, and where its IO goes
+type Usage struct {
+	Limits  Limits
+	Steps   int
+	Depth   int
+	Nesting int  // Evals going on inside of each other, the evaluator's own stack
+	IO      *IO  // Nil is the one of the process, see evaluator.ProcessIO
+}
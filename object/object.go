@@ -0,0 +1,359 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/code"
+)
+
+// These are the names the user sees, so they are written the way they'd be typed
+type ObjectType string
+
+const (
+	INTEGER_OBJ  = "int"
+	FLOAT_OBJ    = "float"
+	STRING_OBJ   = "string"
+	BOOLEAN_OBJ  = "bool"
+	NIL_OBJ      = "nil"
+	FUNCTION_OBJ = "fn"
+	ERROR_OBJ    = "error"
+	MODULE_OBJ   = "module"
+	ARRAY_OBJ    = "array"
+	MAP_OBJ      = "map"
+
+	// These are internal, the user never gets to see them
+	RETURN_VALUE_OBJ      = "return"
+	RUNTIME_ERROR_OBJ     = "runtime_error"
+	BREAK_OBJ             = "break"
+	CONTINUE_OBJ          = "continue"
+	COMPILED_FUNCTION_OBJ = "compiled_fn"
+)
+
+type Object interface {
+	Type() ObjectType
+	Inspect() string
+}
+
+
+type Integer struct {
+	Value int64
+}
+
+func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
+func (i *Integer) Inspect() string  { return strconv.FormatInt(i.Value, 10) }
+
+
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+func (f *Float) Inspect() string  {
+	// We always want the dot, otherwise 2.0 would be shown as if it was an int
+	s := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return s
+}
+
+
+type String struct {
+	Value string
+}
+
+func (s *String) Type() ObjectType { return STRING_OBJ }
+func (s *String) Inspect() string  { return s.Value }
+
+
+// Arrays cannot be changed once made, operations on them give back a new one
+type Array struct {
+	Elements []Object
+}
+
+func (a *Array) Type() ObjectType { return ARRAY_OBJ }
+func (a *Array) Inspect() string  {
+	var elements []string
+	for _, element := range a.Elements {
+		elements = append(elements, inspectElement(element))
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// Maps cannot be changed either. They remember the order their keys came in, so
+// printing one always gives the same text.
+type Map struct {
+	Keys  []Object
+	Pairs map[MapKey]Object
+}
+
+// MapKey is what a key is stored as: 1 and '1' are not the same key
+type MapKey struct {
+	Type  ObjectType
+	Value string
+}
+
+// KeyOf is false for the values that cannot be keys. Only strings, ints and bools
+// can, floats cannot (is 1.0 the same key as 1?).
+func KeyOf(obj Object) (MapKey, bool) {
+	switch obj := obj.(type) {
+	case *String:
+		return MapKey{STRING_OBJ, obj.Value}, true
+	case *Integer:
+		return MapKey{INTEGER_OBJ, obj.Inspect()}, true
+	case *Boolean:
+		return MapKey{BOOLEAN_OBJ, obj.Inspect()}, true
+	}
+	return MapKey{}, false
+}
+
+func NewMap() *Map {
+	return &Map{Pairs: make(map[MapKey]Object)}
+}
+
+// Set is only meant for making a new map. A key that is already there keeps its
+// place, but gets the new value. It's false when the key cannot be one.
+func (m *Map) Set(key, value Object) bool {
+	mapKey, ok := KeyOf(key)
+	if !ok {
+		return false
+	}
+	if _, ok := m.Pairs[mapKey]; !ok {
+		m.Keys = append(m.Keys, key)
+	}
+	m.Pairs[mapKey] = value
+	return true
+}
+
+func (m *Map) Get(key Object) (Object, bool) {
+	mapKey, ok := KeyOf(key)
+	if !ok {
+		return nil, false
+	}
+	value, ok := m.Pairs[mapKey]
+	return value, ok
+}
+
+func (m *Map) Type() ObjectType { return MAP_OBJ }
+func (m *Map) Inspect() string  {
+	var pairs []string
+	for _, key := range m.Keys {
+		value, _ := m.Get(key)
+		pairs = append(pairs, inspectElement(key)+": "+inspectElement(value))
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// Inside of arrays and maps strings keep their quotes. Otherwise ['a, b'] and
+// ['a', 'b'] would look the same.
+func inspectElement(obj Object) string {
+	if s, ok := obj.(*String); ok {
+		return quote(s.Value)
+	}
+	return obj.Inspect()
+}
+
+// The escapes are not put back, this is only to show the string. So we pick the quote
+// it doesn't have, which is enough to tell where it ends most of the time.
+func quote(s string) string {
+	if strings.Contains(s, "'") {
+		return `"` + s + `"`
+	}
+	return "'" + s + "'"
+}
+
+
+type Boolean struct {
+	Value bool
+}
+
+func (b *Boolean) Type() ObjectType { return BOOLEAN_OBJ }
+func (b *Boolean) Inspect() string  { return strconv.FormatBool(b.Value) }
+
+
+type Nil struct{}
+
+func (n *Nil) Type() ObjectType { return NIL_OBJ }
+func (n *Nil) Inspect() string  { return "nil" }
+
+
+// We wrap the value so the evaluator knows it must stop evaluating the current block
+type ReturnValue struct {
+	Value      Object
+	Propagated bool  // An error that ? is returning, not a return of the program
+}
+
+func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
+func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
+
+
+// break and continue travel up to the closest loop the same way a return does. They
+// remember where they were written in case there is no loop to catch them.
+type Break struct {
+	Line   int
+	Column int
+}
+
+func (b *Break) Type() ObjectType { return BREAK_OBJ }
+func (b *Break) Inspect() string  { return "break" }
+
+
+type Continue struct {
+	Line   int
+	Column int
+}
+
+func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
+func (c *Continue) Inspect() string  { return "continue" }
+
+
+// What stops the program when something goes wrong. The program never holds one,
+// the errors it can hold are ErrorValues: a try turns this into one when it catches it.
+type Error struct {
+	Message string
+	Kind    string  // What went wrong, like "zero_division", the same the catch gets
+	Line    int
+	Column  int
+	Trace   []TraceFrame  // The calls it went out of, the innermost first
+}
+
+func (e *Error) Type() ObjectType { return RUNTIME_ERROR_OBJ }
+func (e *Error) Inspect() string  {
+	return fmt.Sprintf("ERROR: %s. Line: %d, column: %d", e.Message, e.Line, e.Column)
+}
+
+
+// An error the program holds, made with error("...") or given back by a builtin that
+// failed, or one that was caught. The position is where it was made.
+type ErrorValue struct {
+	Message string
+	Kind    string  // What went wrong, like "zero_division". It's "error" for the ones the program makes
+	Line    int
+	Column  int
+	Trace   []TraceFrame  // Only the caught ones have it
+}
+
+func (ev *ErrorValue) Type() ObjectType { return ERROR_OBJ }
+func (ev *ErrorValue) Inspect() string  { return "error(" + quote(ev.Message) + ")" }
+
+
+// One of the calls that was going on when an error happened
+type TraceFrame struct {
+	Function string  // Empty when it has no name
+	FnLine   int     // Where the function was defined
+	FnColumn int
+	Line     int     // Where it was called from
+	Column   int
+}
+
+// A function without a name is told apart by where it was defined
+func (tf TraceFrame) Name() string {
+	if tf.Function != "" {
+		return tf.Function
+	}
+	return fmt.Sprintf("<anonymous fn> (line %d, column %d)", tf.FnLine, tf.FnColumn)
+}
+
+
+type Function struct {
+	Parameters []*ast.Identifier
+	Body       *ast.BlockStatement
+	Env        *Environment  // The environment where the function was defined; closures!
+	Name       string        // The one of its FunctionLiteral, and where that was. For the traces.
+	Line       int
+	Column     int
+}
+
+func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
+func (f *Function) Inspect() string  {
+	var out bytes.Buffer
+	var params []string
+
+	for _, param := range f.Parameters {
+		params = append(params, param.String())
+	}
+
+	out.WriteString("fn(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") ")
+	out.WriteString(f.Body.String())
+
+	return out.String()
+}
+
+
+// This is what the compiler makes out of a function literal. It only lives on the
+// constant pool; the vm wraps it on a Closure before anyone can call it.
+type CompiledFunction struct {
+	Instructions  code.Instructions
+	Lines         code.LineTable
+	NumLocals     int
+	NumParameters int
+	Names         []string  // The names of the locals, by index. Only used on errors.
+	Name          string    // Same as the Function ones
+	Line          int
+	Column        int
+}
+
+func (cf *CompiledFunction) Type() ObjectType { return COMPILED_FUNCTION_OBJ }
+func (cf *CompiledFunction) Inspect() string  {
+	return fmt.Sprintf("CompiledFunction[%p]", cf)
+}
+
+
+// The locals of one call to a compiled function. A nil slot was not declared yet.
+type Locals struct {
+	Fn     *CompiledFunction
+	Values []Object
+}
+
+
+/*
+The vm's functions. Outer holds the locals of every call this one was created in,
+the closest first. They are shared, not copied, so an assignment made from inside
+is seen from outside too (just like the evaluator's environments).
+*/
+type Closure struct {
+	Fn    *CompiledFunction
+	Outer []*Locals
+}
+
+func (c *Closure) Type() ObjectType { return FUNCTION_OBJ }
+func (c *Closure) Inspect() string  {
+	return fmt.Sprintf("fn[%d params]", c.Fn.NumParameters)
+}
+
+
+// A function written in Go. The arguments it gets were already checked against its
+// signature, so it only fails when their values are wrong.
+type Builtin struct {
+	Name string
+	Fn   func(args []Object) (Object, error)
+	Size func(args []Object) int  // How big the result would be, for the ones that can make a big one
+	IO   func(streams *IO, args []Object) (Object, error)  // Fn on the IO of the program, for the ones that use it
+}
+
+func (b *Builtin) Type() ObjectType { return FUNCTION_OBJ }
+func (b *Builtin) Inspect() string  { return "builtin " + b.Name }
+
+
+// What an import gives back: the top level bindings of a file, seen from outside
+type Module struct {
+	Name string
+	Path string  // The file it comes from
+	Env  *Environment
+}
+
+func (m *Module) Type() ObjectType { return MODULE_OBJ }
+func (m *Module) Inspect() string  { return "module " + m.Name }
+
+// Importer finds a module and runs it. Relative paths depend on who imports, so every
+// file gets its own importer. The usage is the one of the program that imports (nil
+// when it keeps none, like the vm without limits); the module runs within it too.
+type Importer interface {
+	Import(path string, usage *Usage) (*Module, error)
+}
@@ -0,0 +1,78 @@
+package optimize
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/evaluator"
+	"github.com/santos-404/myte/object"
+	"github.com/santos-404/myte/token"
+)
+
+// literalValue gives back the value of the literals, the only expressions we can fold
+func literalValue(exp ast.Expression) (object.Object, bool) {
+	switch exp := exp.(type) {
+	case *ast.IntegerLiteral:
+		return &object.Integer{Value: exp.Value}, true
+	case *ast.FloatLiteral:
+		return &object.Float{Value: exp.Value}, true
+	case *ast.StringLiteral:
+		return &object.String{Value: evaluator.DecodeString(exp.Value)}, true
+	case *ast.BooleanLiteral:
+		return evaluator.NativeBool(exp.Value), true
+	case *ast.NilLiteral:
+		return evaluator.NIL, true
+	}
+	return nil, false
+}
+
+// toLiteral is the other way round. The token keeps the position of the expression.
+func toLiteral(obj object.Object, tok token.Token) (ast.Expression, bool) {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return &ast.IntegerLiteral{
+			Token: withLiteral(tok, token.INT, strconv.FormatInt(obj.Value, 10)),
+			Value: obj.Value,
+		}, true
+	case *object.Float:
+		return &ast.FloatLiteral{Token: withLiteral(tok, token.FLOAT, obj.Inspect()), Value: obj.Value}, true
+	case *object.String:
+		raw := `"` + escaper.Replace(obj.Value) + `"`
+		return &ast.StringLiteral{Token: withLiteral(tok, token.STRING, raw), Value: raw}, true
+	case *object.Boolean:
+		tokenType := token.FALSE
+		if obj.Value {
+			tokenType = token.TRUE
+		}
+		return &ast.BooleanLiteral{Token: withLiteral(tok, tokenType, obj.Inspect()), Value: obj.Value}, true
+	case *object.Nil:
+		return &ast.NilLiteral{Token: withLiteral(tok, token.NIL, "nil")}, true
+	}
+	return nil, false
+}
+
+// The literal is decoded again when it runs, so what the decoding reads is escaped
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+
+func withLiteral(tok token.Token, tokenType token.TokenType, literal string) token.Token {
+	tok.Type = tokenType
+	tok.Literal = literal
+	return tok
+}
+
+var comparisons = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+// isBoolean tells whether the expression always gives back a bool
+func isBoolean(exp ast.Expression) bool {
+	switch exp := exp.(type) {
+	case *ast.BooleanLiteral:
+		return true
+	case *ast.PrefixExpression:
+		return exp.Operator == "!"
+	case *ast.InfixExpression:
+		// Comparing can fail ('a' < 1), but when it doesn't, it gives back a bool
+		return comparisons[exp.Operator]
+	}
+	return false
+}
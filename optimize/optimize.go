@@ -0,0 +1,267 @@
+package optimize
+
+import (
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/checker"
+	"github.com/santos-404/myte/evaluator"
+	"github.com/santos-404/myte/object"
+	"github.com/santos-404/myte/token"
+)
+
+/*
+Program gives back an optimized copy of the program; the original one is never
+touched, so whoever still needs the tree as it was written (the language server)
+can keep using it.
+
+For now the only optimization is folding: an operation whose operands are literals
+is done here once, instead of every time it runs. The operations are the
+evaluator's own, so the result can't be different from what it would be at runtime.
+When that result is an error (like 1 / 0) the expression is left as it was, so it
+still fails when (and if) it runs, and we give it back as a finding.
+*/
+func Program(program *ast.Program) (*ast.Program, []checker.Finding) {
+	o := &optimizer{}
+	optimized := &ast.Program{Statements: o.statements(program.Statements)}
+
+	checker.SortFindings(o.findings)
+	return optimized, o.findings
+}
+
+type optimizer struct {
+	findings []checker.Finding
+}
+
+func (o *optimizer) statements(statements []ast.Statement) []ast.Statement {
+	var result []ast.Statement
+	for i, stmt := range statements {
+		stmt = o.statement(stmt)
+
+		// A known branch that is not the last statement can take the place of its
+		// if: blocks don't open a scope and nobody uses its value
+		if i < len(statements)-1 {
+			if block, ok := knownBranch(stmt); ok {
+				result = append(result, block...)
+				continue
+			}
+		}
+		result = append(result, stmt)
+	}
+	return result
+}
+
+func (o *optimizer) statement(stmt ast.Statement) ast.Statement {
+	switch stmt := stmt.(type) {
+	case *ast.ExpressionStatement:
+		optimized := *stmt
+		optimized.Expression = o.expression(stmt.Expression)
+		return &optimized
+	case *ast.VarStatement:
+		if stmt == nil {
+			return stmt
+		}
+		optimized := *stmt
+		optimized.Value = o.expression(stmt.Value)
+		return &optimized
+	case *ast.ConstStatement:
+		if stmt == nil {
+			return stmt
+		}
+		optimized := *stmt
+		optimized.Value = o.expression(stmt.Value)
+		return &optimized
+	case *ast.ReturnStatement:
+		optimized := *stmt
+		optimized.ReturnValue = o.expression(stmt.ReturnValue)
+		return &optimized
+	case *ast.ThrowStatement:
+		optimized := *stmt
+		optimized.Value = o.expression(stmt.Value)
+		return &optimized
+	case *ast.BlockStatement:
+		return o.block(stmt)
+	}
+	return stmt
+}
+
+func (o *optimizer) block(block *ast.BlockStatement) *ast.BlockStatement {
+	if block == nil {
+		return nil
+	}
+	optimized := *block
+	optimized.Statements = o.statements(block.Statements)
+	return &optimized
+}
+
+func (o *optimizer) expression(exp ast.Expression) ast.Expression {
+	switch exp := exp.(type) {
+	case *ast.PrefixExpression:
+		return o.prefixExpression(exp)
+	case *ast.InfixExpression:
+		return o.infixExpression(exp)
+	case *ast.AssignExpression:
+		optimized := *exp
+		optimized.Value = o.expression(exp.Value)
+		return &optimized
+	case *ast.IfExpression:
+		return o.ifExpression(exp)
+	case *ast.ForExpression:
+		optimized := *exp
+		optimized.Condition = o.expression(exp.Condition)
+		optimized.Body = o.block(exp.Body)
+
+		// A loop that never runs is just its value
+		if value, ok := literalValue(optimized.Condition); ok && !evaluator.IsTruthy(value) {
+			return &ast.NilLiteral{Token: withLiteral(exp.Token, token.NIL, "nil")}
+		}
+		return &optimized
+	case *ast.FunctionLiteral:
+		optimized := *exp
+		optimized.Body = o.block(exp.Body)
+		return &optimized
+	case *ast.CallExpression:
+		optimized := *exp
+		optimized.Function = o.expression(exp.Function)
+		optimized.Arguments = nil
+		for _, arg := range exp.Arguments {
+			optimized.Arguments = append(optimized.Arguments, o.expression(arg))
+		}
+		return &optimized
+	case *ast.MemberExpression:
+		optimized := *exp
+		optimized.Object = o.expression(exp.Object)
+		return &optimized
+	case *ast.ArrayLiteral:
+		optimized := *exp
+		optimized.Elements = nil
+		for _, element := range exp.Elements {
+			optimized.Elements = append(optimized.Elements, o.expression(element))
+		}
+		return &optimized
+	case *ast.MapLiteral:
+		optimized := *exp
+		optimized.Keys, optimized.Values = nil, nil
+		for i, key := range exp.Keys {
+			optimized.Keys = append(optimized.Keys, o.expression(key))
+			optimized.Values = append(optimized.Values, o.expression(exp.Values[i]))
+		}
+		return &optimized
+	case *ast.IndexExpression:
+		optimized := *exp
+		optimized.Left = o.expression(exp.Left)
+		optimized.Index = o.expression(exp.Index)
+		return &optimized
+	case *ast.PropagateExpression:
+		optimized := *exp
+		optimized.Value = o.expression(exp.Value)
+		return &optimized
+	case *ast.TryExpression:
+		optimized := *exp
+		optimized.Body = o.block(exp.Body)
+		optimized.Catch = o.block(exp.Catch)
+		optimized.Finally = o.block(exp.Finally)
+		return &optimized
+	}
+	return exp
+}
+
+func (o *optimizer) prefixExpression(exp *ast.PrefixExpression) ast.Expression {
+	optimized := *exp
+	optimized.Right = o.expression(exp.Right)
+
+	// ++ and -- are left alone, they are assignments
+	if exp.Operator != "!" && exp.Operator != "-" {
+		return &optimized
+	}
+
+	if right, ok := literalValue(optimized.Right); ok {
+		return o.fold(&optimized, exp.Token, evaluator.PrefixOperation(exp.Token, exp.Operator, right))
+	}
+
+	// !!x is x when x is a bool already. Otherwise it's how you turn x into one.
+	if inner, ok := optimized.Right.(*ast.PrefixExpression); ok && exp.Operator == "!" &&
+		inner.Operator == "!" && isBoolean(inner.Right) {
+		return inner.Right
+	}
+	return &optimized
+}
+
+func (o *optimizer) infixExpression(exp *ast.InfixExpression) ast.Expression {
+	optimized := *exp
+	optimized.Left = o.expression(exp.Left)
+	optimized.Right = o.expression(exp.Right)
+
+	left, ok := literalValue(optimized.Left)
+	if !ok {
+		return &optimized
+	}
+	right, ok := literalValue(optimized.Right)
+	if !ok {
+		return &optimized
+	}
+
+	return o.fold(&optimized, exp.Token, evaluator.InfixOperation(exp.Token, exp.Operator, left, right))
+}
+
+// fold swaps the expression by a literal with its result, at the same position
+func (o *optimizer) fold(exp ast.Expression, tok token.Token, result object.Object) ast.Expression {
+	if err, ok := result.(*object.Error); ok {
+		o.findings = append(o.findings, checker.Finding{
+			Severity: checker.Warning,
+			Message:  "this always fails: " + err.Message,
+			Line:     err.Line,
+			Column:   err.Column,
+		})
+		return exp
+	}
+
+	if literal, ok := toLiteral(result, tok); ok {
+		return literal
+	}
+	return exp
+}
+
+/*
+An if with a literal condition only keeps the branch that runs: if true { a } else
+{ b } is if true { a }, and if false { a } else { b } turns into if true { b }. We
+keep the if around the branch because its value is the value of the whole thing.
+*/
+func (o *optimizer) ifExpression(exp *ast.IfExpression) ast.Expression {
+	optimized := *exp
+	optimized.Condition = o.expression(exp.Condition)
+	optimized.Consequence = o.block(exp.Consequence)
+	optimized.Alternative = o.block(exp.Alternative)
+
+	condition, ok := literalValue(optimized.Condition)
+	if !ok {
+		return &optimized
+	}
+
+	alwaysTrue := &ast.BooleanLiteral{Token: withLiteral(exp.Token, token.TRUE, "true"), Value: true}
+	if evaluator.IsTruthy(condition) {
+		optimized.Condition = alwaysTrue
+		optimized.Alternative = nil
+		return &optimized
+	}
+	if optimized.Alternative == nil {
+		return &ast.NilLiteral{Token: withLiteral(exp.Token, token.NIL, "nil")}
+	}
+	optimized.Condition = alwaysTrue
+	optimized.Consequence, optimized.Alternative = optimized.Alternative, nil
+	return &optimized
+}
+
+// knownBranch gives back the statements of a statement like "if true { ... }"
+func knownBranch(stmt ast.Statement) ([]ast.Statement, bool) {
+	exp, ok := stmt.(*ast.ExpressionStatement)
+	if !ok {
+		return nil, false
+	}
+	ifExp, ok := exp.Expression.(*ast.IfExpression)
+	if !ok || ifExp.Alternative != nil || ifExp.Consequence == nil {
+		return nil, false
+	}
+	if condition, ok := ifExp.Condition.(*ast.BooleanLiteral); !ok || !condition.Value {
+		return nil, false
+	}
+	return ifExp.Consequence.Statements, true
+}
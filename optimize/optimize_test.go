@@ -0,0 +1,122 @@
+package optimize
+
+import (
+	"testing"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/lexer"
+	"github.com/santos-404/myte/parser"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has errors for %q: %v", input, p.Errors())
+	}
+	return program
+}
+
+func TestFolding(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"60 * 60 * 24", "86400"},
+		{"-(3)", "-3"},
+		{"1 + 2 * x", "(1 + (2 * x))"},
+		{"2 * 3 + x", "(6 + x)"},
+		{"7 / 2", "3.5"},
+		{"2 ** -1", "0.5"},
+		{"'my' + 'te'", `"myte"`},
+		{`'it\'s' + "\n\"a\"\\"`, `"it's\n\"a\"\\"`},
+		{"1 < 2", "true"},
+		{"1 == 1.0", "true"},
+		{"'a' != nil", "true"},
+		{"!true == false", "true"},
+		{"!0", "false"},
+		{"!!(x < y)", "(x < y)"},
+		{"!!x", "(!(!x))"},
+		{"var x = 10 // 3;", "var x = 3;"},
+		{"fn(a) { return a * (2 + 2); }", "fn(a){return (a * 4);}"},
+		{"f(1 + 1, 'a' + 'b')", `f(2, "ab")`},
+		{"x = 2 * 21", "x = 42"},
+		{"++(1 + 1)", "(++2)"},
+	}
+
+	for _, tt := range tests {
+		optimized, findings := Program(parse(t, tt.input))
+		if len(findings) != 0 {
+			t.Errorf("%q - unexpected findings: %q", tt.input, findings)
+		}
+		if optimized.String() != tt.expected {
+			t.Errorf("%q - expected=%q, got=%q", tt.input, tt.expected, optimized.String())
+		}
+	}
+}
+
+func TestBranches(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"if 1 < 2 { a } else { b }", "if true {a}"},
+		{"if false { a } else { b }", "if true {b}"},
+		{"if nil { a }", "nil"},
+		{"if x { a } else { b }", "if x {a}else{b}"},
+		{"for false { a }", "nil"},
+		// Not the last statement, so the branch takes the place of the if
+		{"if true { var a = 1; a }\na", "var a = 1;aa"},
+	}
+
+	for _, tt := range tests {
+		optimized, _ := Program(parse(t, tt.input))
+		if optimized.String() != tt.expected {
+			t.Errorf("%q - expected=%q, got=%q", tt.input, tt.expected, optimized.String())
+		}
+	}
+}
+
+// What would fail at runtime must still fail there, at the same place
+func TestErrorsAreNotFolded(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		finding  string
+	}{
+		{"1 / 0", "(1 / 0)", "warning: this always fails: division by zero. Line: 0, column: 3"},
+		{"var x = 2 * (5 % 0);", "var x = (2 * (5 % 0));",
+			"warning: this always fails: division by zero. Line: 0, column: 16"},
+		{"'a' - 1", "('a' - 1)",
+			"warning: this always fails: unsupported operand types: string - int. Line: 0, column: 5"},
+		{"-'a'", "(-'a')", "warning: this always fails: unknown operator: -string. Line: 0, column: 1"},
+	}
+
+	for _, tt := range tests {
+		optimized, findings := Program(parse(t, tt.input))
+		if optimized.String() != tt.expected {
+			t.Errorf("%q - expected=%q, got=%q", tt.input, tt.expected, optimized.String())
+		}
+		if len(findings) != 1 || findings[0].String() != tt.finding {
+			t.Errorf("%q - findings wrong. expected=%q, got=%q", tt.input, tt.finding, findings)
+		}
+	}
+}
+
+func TestPositionsAreKept(t *testing.T) {
+	program := parse(t, "var x = 1;\nvar y = 60 * 60;")
+	optimized, _ := Program(program)
+
+	literal, ok := optimized.Statements[1].(*ast.VarStatement).Value.(*ast.IntegerLiteral)
+	if !ok {
+		t.Fatalf("the value was not folded. got=%s", optimized.Statements[1])
+	}
+	if literal.Token.Line != 1 || literal.Token.Column != 12 {
+		t.Errorf("position wrong. expected=1:12, got=%d:%d", literal.Token.Line, literal.Token.Column)
+	}
+
+	// And the original tree is left as it was
+	if program.Statements[1].String() != "var y = (60 * 60);" {
+		t.Errorf("the original program changed. got=%s", program.Statements[1])
+	}
+}
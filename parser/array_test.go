@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/lexer"
+)
+
+func TestArrayAndIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[]", "[]"},
+		{"[1, 'a', x + 1]", "[1, 'a', (x + 1)]"},
+		{"[[1], [2, 3]]", "[[1], [2, 3]]"},
+		{"a[0]", "(a[0])"},
+		{"a[i + 1] * 2", "((a[(i + 1)]) * 2)"},
+		{"-a[0]", "(-(a[0]))"},
+		{"m.list[0]", "(m.list[0])"},
+		{"f(x)[1][2]", "((f(x)[1])[2])"},
+		{"[1, 2][0]", "([1, 2][0])"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("%q - program.Statements does not contain 1 statement. got=%d",
+				tt.input, len(program.Statements))
+		}
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("%q - stmt is not *ast.ExpressionStatement. got=%T", tt.input, program.Statements[0])
+		}
+		if stmt.Expression.String() != tt.expected {
+			t.Errorf("%q - expected=%q, got=%q", tt.input, tt.expected, stmt.Expression.String())
+		}
+	}
+}
+
+func TestArrayErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2", "expected next token to be: ], got: EOF instead"},
+		{"a[1", "expected next token to be: ], got: EOF instead"},
+		{"a[0] = 1", "cannot assign to (a[0])"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.ParserErrors()
+		if len(errors) == 0 {
+			t.Fatalf("%q - expected an error", tt.input)
+		}
+		if errors[0].Message != tt.expected {
+			t.Errorf("%q - error wrong. expected=%q, got=%q", tt.input, tt.expected, errors[0].Message)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/lexer"
+)
+
+func TestAssignExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x = 5;", "x = 5"},
+		{"x += 1 + 2;", "x += (1 + 2)"},
+		{"x -= y * 2", "x -= (y * 2)"},
+		{"x *= 2", "x *= 2"},
+		{"x /= 2", "x /= 2"},
+		{"a = b = 1", "a = b = 1"},
+		{"x = y == 1", "x = (y == 1)"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("%q - program.Statements does not contain 1 statement. got=%d",
+				tt.input, len(program.Statements))
+		}
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		if _, ok := stmt.Expression.(*ast.AssignExpression); !ok {
+			t.Fatalf("%q - stmt.Expression is not *ast.AssignExpression. got=%T", tt.input, stmt.Expression)
+		}
+		if program.String() != tt.expected {
+			t.Errorf("%q - expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestAssignToNonIdentifier(t *testing.T) {
+	p := New(lexer.New("1 = 2;"))
+	p.ParseProgram()
+
+	errors := p.ParserErrors()
+	if len(errors) == 0 || errors[0].Message != "cannot assign to 1" {
+		t.Errorf("expected 'cannot assign to 1' error. got=%v", p.Errors())
+	}
+}
+
+func TestBreakAndContinueStatements(t *testing.T) {
+	input := "for true { break; continue }"
+
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	loop := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.ForExpression)
+	if len(loop.Body.Statements) != 2 {
+		t.Fatalf("loop body does not contain 2 statements. got=%d", len(loop.Body.Statements))
+	}
+	if _, ok := loop.Body.Statements[0].(*ast.BreakStatement); !ok {
+		t.Errorf("Statements[0] is not *ast.BreakStatement. got=%T", loop.Body.Statements[0])
+	}
+	if _, ok := loop.Body.Statements[1].(*ast.ContinueStatement); !ok {
+		t.Errorf("Statements[1] is not *ast.ContinueStatement. got=%T", loop.Body.Statements[1])
+	}
+}
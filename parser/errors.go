@@ -6,21 +6,50 @@ import (
 	"github.com/santos-404/myte/token"
 )
 
+// Tools like the language server need the position on its own, not inside the message
+type ParserError struct {
+	Message string
+	Line    int
+	Column  int
+}
+
+func (pe ParserError) Error() string {
+	return fmt.Sprintf("%s. Line: %d, column: %d", pe.Message, pe.Line, pe.Column)
+}
+
+func (p *Parser) addError(tok token.Token, format string, a ...interface{}) {
+	p.errors = append(p.errors, ParserError{
+		Message: fmt.Sprintf(format, a...),
+		Line:    tok.Line,
+		Column:  tok.Column,
+	})
+}
 
 func (p *Parser) peekError(expectedType token.TokenType) {
-	msg := fmt.Sprintf("expected next token to be: %s, got: %s instead. Line: %d, column: %d",
-		expectedType, p.peekToken.Type, p.peekToken.Line, p.peekToken.Column)
-	p.errors = append(p.errors, msg)
+	p.addError(p.peekToken, "expected next token to be: %s, got: %s instead",
+		expectedType, p.peekToken.Type)
 }
 
 func (p *Parser) noPrefixParseFunctionError() {
-	msg := fmt.Sprintf("no prefix parse function for %s found. Line: %d, column: %d", 
-		p.currentToken.Type, p.currentToken.Line, p.currentToken.Column)
-	p.errors = append(p.errors, msg)
+	if p.currentToken.Type == token.ILLEGAL {
+		p.illegalTokenError()
+		return
+	}
+	p.addError(p.currentToken, "no prefix parse function for %s found", p.currentToken.Type)
 }
 
 func (p *Parser) parsingLiteralError(parseTo string) {
-	msg := fmt.Sprintf("could not parse %q as %s. Line: %d, column: %d", 
-		p.currentToken.Literal, parseTo, p.currentToken.Line, p.currentToken.Column)
-	p.errors = append(p.errors, msg)
+	p.addError(p.currentToken, "could not parse %q as %s", p.currentToken.Literal, parseTo)
+}
+
+// The lexer gives back ILLEGAL for unknown chars, unclosed strings and unclosed block comments
+func (p *Parser) illegalTokenError() {
+	switch lit := p.currentToken.Literal; {
+	case lit == "#-":
+		p.addError(p.currentToken, "the block comment is not closed")
+	case len(lit) > 0 && (lit[0] == '"' || lit[0] == '\''):
+		p.addError(p.currentToken, "the string is not closed")
+	default:
+		p.addError(p.currentToken, "illegal character %q", lit)
+	}
 }
@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/santos-404/myte/lexer"
+)
+
+// Incomplete input must end up as errors, never as a parser that doesn't return
+func TestIncompleteInputErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{"var s = 'open", "the string is not closed", 0, 9},
+		{"var x = 1;\n#- open comment", "the block comment is not closed", 1, 1},
+		{"foo(1, 2", "expected next token to be: ), got: EOF instead", 0, 9},
+		{"fn(a, b", "expected a parameter name, got: EOF instead", 0, 8},
+		{"var x = @;", "illegal character \"@\"", 0, 9},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.ParserErrors()
+		if len(errors) == 0 {
+			t.Errorf("%q - expected errors, got none", tt.input)
+			continue
+		}
+
+		err := errors[0]
+		if err.Message != tt.expectedMessage {
+			t.Errorf("%q - wrong message. expected=%q, got=%q", tt.input, tt.expectedMessage, err.Message)
+		}
+		if err.Line != tt.expectedLine || err.Column != tt.expectedColumn {
+			t.Errorf("%q - wrong position. expected=%d:%d, got=%d:%d",
+				tt.input, tt.expectedLine, tt.expectedColumn, err.Line, err.Column)
+		}
+		if p.Errors()[0] != err.Error() {
+			t.Errorf("%q - Errors() and ParserErrors() disagree. %q != %q", tt.input, p.Errors()[0], err.Error())
+		}
+	}
+}
@@ -19,6 +19,13 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 
 
 	for p.peekToken.Type != token.SEMICOLON && precedence < p.peekPrecedence() {
+		// A postfix has nothing on its right, so there is nothing else to parse
+		if postfixParseFunction := p.postfixParseFns[p.peekToken.Type]; postfixParseFunction != nil {
+			p.nextToken()
+			leftExp = postfixParseFunction(leftExp)
+			continue
+		}
+
 		infixParseFunction := p.infixParseFns[p.peekToken.Type]
 		if infixParseFunction == nil {
 			return leftExp
@@ -106,6 +113,39 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	return exp
 }
 
+// The right side is parsed with a lower precedence, so a = b = 1 is a = (b = 1)
+func (p *Parser) parseAssignExpression(left ast.Expression) ast.Expression {
+	name, ok := left.(*ast.Identifier)
+	if !ok {
+		p.addError(p.currentToken, "cannot assign to %s", left)
+		return nil
+	}
+
+	exp := &ast.AssignExpression{
+		Token: p.currentToken,
+		Name: name,
+		Operator: p.currentToken.Literal,
+	}
+	p.nextToken()
+	exp.Value = p.parseExpression(ASSIGNMENT - 1)
+
+	return exp
+}
+
+func (p *Parser) parseMemberExpression(object ast.Expression) ast.Expression {
+	exp := &ast.MemberExpression{Token: p.currentToken, Object: object}
+
+	if !p.peekCompareThenAdvance(token.IDENT) {
+		return nil
+	}
+	exp.Member = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+	return exp
+}
+
+func (p *Parser) parsePropagateExpression(value ast.Expression) ast.Expression {
+	return &ast.PropagateExpression{Token: p.currentToken, Value: value}
+}
+
 // THIS IS FUCKING MAGICAL
 func (p *Parser) parseGroupedExpression() ast.Expression {
 	p.nextToken()
@@ -143,6 +183,43 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	return exp 
 }
 
+// try { } catch e { } finally { }, where the name of the error and any one of the
+// two last parts can be left out
+func (p *Parser) parseTryExpression() ast.Expression {
+	exp := &ast.TryExpression{Token: p.currentToken}
+
+	if !p.peekCompareThenAdvance(token.LBRACE) {
+		return nil
+	}
+	exp.Body = p.parseBlockStatement()
+
+	if p.peekToken.Type == token.CATCH {
+		p.nextToken()
+		if p.peekToken.Type == token.IDENT {
+			p.nextToken()
+			exp.Param = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+		}
+		if !p.peekCompareThenAdvance(token.LBRACE) {
+			return nil
+		}
+		exp.Catch = p.parseBlockStatement()
+	}
+
+	if p.peekToken.Type == token.FINALLY {
+		p.nextToken()
+		if !p.peekCompareThenAdvance(token.LBRACE) {
+			return nil
+		}
+		exp.Finally = p.parseBlockStatement()
+	}
+
+	if exp.Catch == nil && exp.Finally == nil {
+		p.addError(exp.Token, "try needs a catch or a finally")
+		return nil
+	}
+	return exp
+}
+
 
 func (p *Parser) parseFnLiteral() ast.Expression {
 	exp := &ast.FunctionLiteral{Token: p.currentToken}
@@ -151,29 +228,43 @@ func (p *Parser) parseFnLiteral() ast.Expression {
 		return nil	
 	}
 
-	exp.Parameters = p.parseParameters()
+	exp.Parameters, exp.ParameterTypes = p.parseParameters()
+	exp.ReturnType = p.parseOptionalAnnotation()
 
+	if p.currentToken.Type != token.LBRACE {
+		p.addError(p.currentToken, "expected next token to be: %s, got: %s instead",
+			token.LBRACE, p.currentToken.Type)
+		return nil
+	}
 	exp.Body = p.parseBlockStatement()
 	
 	return exp
 }
 
-func (p *Parser) parseParameters() []*ast.Identifier {
+// Every parameter gets its annotation on the second slice, or nil if it doesn't have one
+func (p *Parser) parseParameters() ([]*ast.Identifier, []*ast.TypeAnnotation) {
 	var params []*ast.Identifier
+	var types []*ast.TypeAnnotation
 	p.nextToken()  // We start at '('
 
 	for p.currentToken.Type != token.RPAREN {
+		if p.currentToken.Type != token.IDENT {
+			p.addError(p.currentToken, "expected a parameter name, got: %s instead",
+				p.currentToken.Type)
+			return nil, nil
+		}
 		literal := &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
 		params = append(params, literal)
 
 		p.nextToken()
+		types = append(types, p.parseOptionalAnnotation())
 		if p.currentToken.Literal == "," {
 			p.nextToken()
 		}
 	}
 
 	p.nextToken()  // We are at ')'
-	return params
+	return params, types
 }
 
 
@@ -195,16 +286,22 @@ func (p *Parser) parseForExpression() ast.Expression {
 
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	exp := &ast.CallExpression{Token: p.currentToken, Function: function}
-	exp.Arguments = p.parseCallArguments()
+	exp.Arguments = p.parseExpressionList(token.RPAREN)
 	return exp
 }
 
-func (p *Parser) parseCallArguments() []ast.Expression {
-	var args []ast.Expression
+// The arguments of a call or the elements of an array, up to the end token
+func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
+	var list []ast.Expression
 	p.nextToken()
 
-	for p.currentToken.Type != token.RPAREN {
-		args = append(args, p.parseExpression(LOWEST))	
+	for p.currentToken.Type != end {
+		if p.currentToken.Type == token.EOF {
+			p.addError(p.currentToken, "expected next token to be: %s, got: %s instead",
+				end, token.EOF)
+			return list
+		}
+		list = append(list, p.parseExpression(LOWEST))	
 
 		p.nextToken()
 		if p.currentToken.Type == token.COMMA {
@@ -212,7 +309,50 @@ func (p *Parser) parseCallArguments() []ast.Expression {
 		}
 	}
 
-	return args
+	return list
+}
+
+
+func (p *Parser) parseArrayLiteral() ast.Expression {
+	exp := &ast.ArrayLiteral{Token: p.currentToken}
+	exp.Elements = p.parseExpressionList(token.RBRACKET)
+	return exp
+}
+
+// {key: value, ...}. A comma after the last pair is fine, it's easier to add more later.
+func (p *Parser) parseMapLiteral() ast.Expression {
+	exp := &ast.MapLiteral{Token: p.currentToken}
+
+	for p.peekToken.Type != token.RBRACE {
+		p.nextToken()
+		key := p.parseExpression(LOWEST)
+		if !p.peekCompareThenAdvance(token.COLON) {
+			return nil
+		}
+
+		p.nextToken()
+		exp.Keys = append(exp.Keys, key)
+		exp.Values = append(exp.Values, p.parseExpression(LOWEST))
+
+		if p.peekToken.Type != token.RBRACE && !p.peekCompareThenAdvance(token.COMMA) {
+			return nil
+		}
+	}
+
+	p.nextToken()
+	return exp
+}
+
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	exp := &ast.IndexExpression{Token: p.currentToken, Left: left}
+
+	p.nextToken()
+	exp.Index = p.parseExpression(LOWEST)
+
+	if !p.peekCompareThenAdvance(token.RBRACKET) {
+		return nil
+	}
+	return exp
 }
 
 
@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/lexer"
+)
+
+func TestImportStatements(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedPath string
+		expectedName string
+	}{
+		{`import "math";`, "math", "math"},
+		{`import "./lib/text"`, "./lib/text", "text"},
+		{`import "../shared/util.myte";`, "../shared/util.myte", "util"},
+		{`import "lib/my-strings" as strs;`, "lib/my-strings", "strs"},
+		{`import "x" as as`, "x", "as"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("%q - program.Statements does not contain 1 statement. got=%d",
+				tt.input, len(program.Statements))
+		}
+		stmt, ok := program.Statements[0].(*ast.ImportStatement)
+		if !ok {
+			t.Fatalf("%q - stmt is not *ast.ImportStatement. got=%T", tt.input, program.Statements[0])
+		}
+		if stmt.Path != tt.expectedPath {
+			t.Errorf("%q - path wrong. expected=%q, got=%q", tt.input, tt.expectedPath, stmt.Path)
+		}
+		if stmt.Name.Value != tt.expectedName {
+			t.Errorf("%q - name wrong. expected=%q, got=%q", tt.input, tt.expectedName, stmt.Name.Value)
+		}
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "";`, "the path of an import cannot be empty"},
+		{`import "lib/my-strings";`,
+			`"my-strings" cannot be the name of the module, give it one with: import "lib/my-strings" as <name>`},
+		{`import math;`, "expected next token to be: STRING, got: IDENT instead"},
+		{`import "math" as 1;`, "expected next token to be: IDENT, got: INT instead"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.ParserErrors()
+		if len(errors) == 0 {
+			t.Errorf("%q - expected an error", tt.input)
+			continue
+		}
+		if errors[0].Message != tt.expected {
+			t.Errorf("%q - error wrong. expected=%q, got=%q", tt.input, tt.expected, errors[0].Message)
+		}
+	}
+}
+
+func TestMemberExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"math.pi", "math.pi"},
+		{"math.max(1, 2)", "math.max(1, 2)"},
+		{"-math.pi", "(-math.pi)"},
+		{"a.b + c.d * 2", "(a.b + (c.d * 2))"},
+		{"f(x).y", "f(x).y"},
+		{"x = m.value", "x = m.value"},
+		{".5 + 1", "(.5 + 1)"},  // Still a float, not a member
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("%q - expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestAssignToMember(t *testing.T) {
+	p := New(lexer.New("m.x = 1;"))
+	p.ParseProgram()
+
+	errors := p.ParserErrors()
+	if len(errors) == 0 || errors[0].Message != "cannot assign to m.x" {
+		t.Errorf("expected 'cannot assign to m.x' error. got=%v", p.Errors())
+	}
+}
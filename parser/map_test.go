@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/lexer"
+)
+
+func TestMapLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		pairs    int
+	}{
+		{"{}", "{}", 0},
+		{"{'a': 1}", "{'a': 1}", 1},
+		{"{'a': 1, 2: x + 1, true: [1],}", "{'a': 1, 2: (x + 1), true: [1]}", 3},
+		{"{\n  'a': {'b': 2},\n  k: f(1)\n}", "{'a': {'b': 2}, k: f(1)}", 2},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("%q - stmt is not *ast.ExpressionStatement. got=%T", tt.input, program.Statements[0])
+		}
+		literal, ok := stmt.Expression.(*ast.MapLiteral)
+		if !ok {
+			t.Fatalf("%q - exp is not *ast.MapLiteral. got=%T", tt.input, stmt.Expression)
+		}
+		if len(literal.Keys) != tt.pairs || len(literal.Values) != tt.pairs {
+			t.Errorf("%q - wrong number of pairs. expected=%d, got=%d", tt.input, tt.pairs, len(literal.Keys))
+		}
+		if literal.String() != tt.expected {
+			t.Errorf("%q - expected=%q, got=%q", tt.input, tt.expected, literal.String())
+		}
+	}
+}
+
+func TestMapErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"{'a' 1}", "expected next token to be: :, got: INT instead"},
+		{"{'a': 1 'b': 2}", "expected next token to be: ,, got: STRING instead"},
+		{"{'a': 1", "expected next token to be: ,, got: EOF instead"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.ParserErrors()
+		if len(errors) == 0 {
+			t.Fatalf("%q - expected an error", tt.input)
+		}
+		if errors[0].Message != tt.expected {
+			t.Errorf("%q - error wrong. expected=%q, got=%q", tt.input, tt.expected, errors[0].Message)
+		}
+	}
+}
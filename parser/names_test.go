@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/lexer"
+)
+
+func TestFunctionNames(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var f = fn() { 1 };", "f"},
+		{"const g = fn(a) { a };", "g"},
+		{"var h: fn(): int = fn(): int { 1 };", "h"},
+		{"fn() { 1 };", ""},
+		{"var f = [fn() { 1 }][0];", ""},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		var fn *ast.FunctionLiteral
+		switch stmt := program.Statements[0].(type) {
+		case *ast.VarStatement:
+			fn, _ = stmt.Value.(*ast.FunctionLiteral)
+		case *ast.ConstStatement:
+			fn, _ = stmt.Value.(*ast.FunctionLiteral)
+		case *ast.ExpressionStatement:
+			fn, _ = stmt.Expression.(*ast.FunctionLiteral)
+		}
+
+		name := ""
+		if fn != nil {
+			name = fn.Name
+		}
+		if name != tt.expected {
+			t.Errorf("%q - name wrong. expected=%q, got=%q", tt.input, tt.expected, name)
+		}
+	}
+}
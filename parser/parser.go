@@ -12,6 +12,7 @@ const (
 	// but the order is the important thing here
 	_ int = iota
 	LOWEST				// This is our equivalent to -infinite on numbers
+	ASSIGNMENT			// = | += | -= | *= | /=
 	EQUALS  			// ==
 	LESSGREATER 		// < | >
 	SUMSUBSTRACT		// + | -
@@ -19,10 +20,15 @@ const (
 	MOD 				// % (I ain't that sure if this is the correct order here)
 	POWER 				// **
 	PREFIX 				// -X | !X
-	CALL 				// someFunction(X)
+	CALL 				// someFunction(X) | module.member | array[X] | X?
 )
 
 var precedences = map[token.TokenType]int {
+	token.ASSIGN: 		ASSIGNMENT,
+	token.PLUSEQUAL: 	ASSIGNMENT,
+	token.MINUSEQUAL: 	ASSIGNMENT,
+	token.STAREQUAL: 	ASSIGNMENT,
+	token.SLASHEQUAL: 	ASSIGNMENT,
 	token.EQ: 			EQUALS,
 	token.NOTEQ: 		EQUALS,
 	token.GT: 			LESSGREATER,
@@ -37,6 +43,9 @@ var precedences = map[token.TokenType]int {
 	token.PERCENT: 		MOD,
 	token.DOUBLESTAR: 	POWER,
 	token.LPAREN: 		CALL,
+	token.DOT: 			CALL,
+	token.LBRACKET: 	CALL,
+	token.QUESTION: 	CALL,
 }
 
 type (
@@ -47,7 +56,7 @@ type (
 
 type Parser struct {
 	l *lexer.Lexer
-	errors []string
+	errors []ParserError
 	
 	currentToken token.Token
 	peekToken token.Token
@@ -61,7 +70,7 @@ type Parser struct {
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
 		l: l,
-		errors: []string{},
+		errors: []ParserError{},
 	}
 
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
@@ -77,7 +86,10 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.DOUBLEMINUS, p.parsePrefixExpression)
 	p.registerPrefix(token.DOUBLEPLUS, p.parsePrefixExpression)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
+	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
+	p.registerPrefix(token.LBRACE, p.parseMapLiteral)
 	p.registerPrefix(token.IF, p.parseIfExpression)
+	p.registerPrefix(token.TRY, p.parseTryExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFnLiteral)
 	p.registerPrefix(token.FOR, p.parseForExpression)
 	p.registerPrefix(token.COMMENT, p.parseCommentExpression)
@@ -97,6 +109,16 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.PERCENT, p.parseInfixExpression)
 	p.registerInfix(token.DOUBLESTAR, p.parseInfixExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
+	p.registerInfix(token.DOT, p.parseMemberExpression)
+	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.PLUSEQUAL, p.parseAssignExpression)
+	p.registerInfix(token.MINUSEQUAL, p.parseAssignExpression)
+	p.registerInfix(token.STAREQUAL, p.parseAssignExpression)
+	p.registerInfix(token.SLASHEQUAL, p.parseAssignExpression)
+
+	p.postfixParseFns = make(map[token.TokenType]postfixParseFn)
+	p.registerPostfix(token.QUESTION, p.parsePropagateExpression)
 
 	// This way we set both current and peek tokens
 	p.nextToken()
@@ -107,6 +129,15 @@ func New(l *lexer.Lexer) *Parser {
 
 
 func (p *Parser) Errors () []string {
+	errors := make([]string, len(p.errors))
+	for i, err := range p.errors {
+		errors[i] = err.Error()
+	}
+	return errors
+}
+
+// The same errors as Errors(), but keeping their position apart from the message
+func (p *Parser) ParserErrors() []ParserError {
 	return p.errors
 }
 
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/lexer"
+)
+
+func TestPropagateExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"f()?", "(f()?)"},
+		{"x?", "(x?)"},
+		{"-f()?", "(-(f()?))"},
+		{"f()? + 1", "((f()?) + 1)"},
+		{"a[0]?", "((a[0])?)"},
+		{"f()?.message", "(f()?).message"},
+		{"f(g()?)?", "(f((g()?))?)"},
+		{"var x = f()?", "var x = (f()?);"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("%q - program.Statements does not contain 1 statement. got=%d",
+				tt.input, len(program.Statements))
+		}
+		if program.Statements[0].String() != tt.expected {
+			t.Errorf("%q - expected=%q, got=%q", tt.input, tt.expected, program.Statements[0].String())
+		}
+	}
+
+	p := New(lexer.New("f()?"))
+	stmt := p.ParseProgram().Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.PropagateExpression)
+	if !ok {
+		t.Fatalf("exp is not *ast.PropagateExpression. got=%T", stmt.Expression)
+	}
+	if exp.Token.Column != 4 {
+		t.Errorf("the token is not the '?'. got column %d", exp.Token.Column)
+	}
+}
+
+func TestPropagateErrors(t *testing.T) {
+	p := New(lexer.New("?x"))
+	p.ParseProgram()
+
+	errors := p.ParserErrors()
+	if len(errors) == 0 {
+		t.Fatalf("expected an error")
+	}
+	if errors[0].Message != "no prefix parse function for ? found" {
+		t.Errorf("error wrong. got=%q", errors[0].Message)
+	}
+}
@@ -1,6 +1,9 @@
 package parser
 
 import (
+	"path"
+	"strings"
+
 	"github.com/santos-404/myte/ast"
 	"github.com/santos-404/myte/token"
 )
@@ -13,6 +16,14 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseConstStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.BREAK:
+		return p.parseBreakStatement()
+	case token.CONTINUE:
+		return p.parseContinueStatement()
+	case token.IMPORT:
+		return p.parseImportStatement()
+	case token.THROW:
+		return p.parseThrowStatement()
 	default:
 		return p.parseExpressionStatement()	
 	}
@@ -39,10 +50,12 @@ func (p *Parser) parseVarStatement() *ast.VarStatement {
 
 	stmt.Name = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
 	p.nextToken()	
+	stmt.Type = p.parseOptionalAnnotation()
 
 	if p.currentToken.Type == token.ASSIGN {
 		p.nextToken()	
 		stmt.Value = p.parseExpression(LOWEST)
+		nameFunction(stmt.Name, stmt.Value)
 	} else {
 		stmt.Value = &ast.NilLiteral{Token: token.Token{Type: token.NIL}}
 	}
@@ -61,10 +74,12 @@ func (p *Parser) parseConstStatement() *ast.ConstStatement {
 
 	stmt.Name = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
 	p.nextToken()
+	stmt.Type = p.parseOptionalAnnotation()
 	
 	if p.currentToken.Type == token.ASSIGN {
 		p.nextToken()	
 		stmt.Value = p.parseExpression(LOWEST)
+		nameFunction(stmt.Name, stmt.Value)
 	} else {
 		stmt.Value = &ast.NilLiteral{Token: token.Token{Type: token.NIL}}
 	}
@@ -73,6 +88,13 @@ func (p *Parser) parseConstStatement() *ast.ConstStatement {
 	return stmt 
 }
 
+// A function gets the name of the binding it is declared with, so an error can say where it was
+func nameFunction(name *ast.Identifier, value ast.Expression) {
+	if fn, ok := value.(*ast.FunctionLiteral); ok {
+		fn.Name = name.Value
+	}
+}
+
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.currentToken}
 
@@ -80,7 +102,39 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	
 	stmt.ReturnValue = p.parseExpression(LOWEST)
 
+	if p.peekToken.Type == token.SEMICOLON {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseThrowStatement() *ast.ThrowStatement {
+	stmt := &ast.ThrowStatement{Token: p.currentToken}
+
 	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekToken.Type == token.SEMICOLON {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseBreakStatement() *ast.BreakStatement {
+	stmt := &ast.BreakStatement{Token: p.currentToken}
+
+	if p.peekToken.Type == token.SEMICOLON {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
+	stmt := &ast.ContinueStatement{Token: p.currentToken}
+
+	if p.peekToken.Type == token.SEMICOLON {
+		p.nextToken()
+	}
 	return stmt
 }
 
@@ -101,3 +155,53 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	return block
 }
 
+
+// import "lib/strings" | import "./util" as u
+func (p *Parser) parseImportStatement() ast.Statement {
+	stmt := &ast.ImportStatement{Token: p.currentToken}
+
+	if !p.peekCompareThenAdvance(token.STRING) {
+		return nil
+	}
+	pathToken := p.currentToken
+	stmt.Path = pathToken.Literal[1 : len(pathToken.Literal)-1]
+	if stmt.Path == "" {
+		p.addError(pathToken, "the path of an import cannot be empty")
+		return nil
+	}
+
+	// `as` is not a keyword, anyone can still have a binding called like that
+	if p.peekToken.Type == token.IDENT && p.peekToken.Literal == "as" {
+		p.nextToken()
+		if !p.peekCompareThenAdvance(token.IDENT) {
+			return nil
+		}
+		stmt.Name = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+	} else {
+		name := strings.TrimSuffix(path.Base(stmt.Path), ".myte")
+		if !isIdentifier(name) {
+			p.addError(pathToken, "%q cannot be the name of the module, give it one with: import %s as <name>",
+				name, pathToken.Literal)
+			return nil
+		}
+		stmt.Name = &ast.Identifier{Token: pathToken, Value: name}
+	}
+
+	if p.peekToken.Type == token.SEMICOLON {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func isIdentifier(name string) bool {
+	if name == "" || ('0' <= name[0] && name[0] <= '9') {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		char := name[i]
+		if !('a' <= char && char <= 'z' || 'A' <= char && char <= 'Z' || char == '_' || '0' <= char && char <= '9') {
+			return false
+		}
+	}
+	return token.LookupIdent(name) == token.IDENT
+}
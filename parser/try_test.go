@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/lexer"
+)
+
+func TestTryExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"try { x } catch e { y }", "try {x}catch e {y}"},
+		{"try { x } catch { y }", "try {x}catch {y}"},
+		{"try { x } finally { z }", "try {x}finally {z}"},
+		{"try { x } catch e { y } finally { z }", "try {x}catch e {y}finally {z}"},
+		{"var v = try { f() } catch e { 0 };", "var v = try {f()}catch e {0};"},
+		{"throw e;", "throw e;"},
+		{"throw error('bad')", "throw error('bad');"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("%q - program.Statements does not contain 1 statement. got=%d",
+				tt.input, len(program.Statements))
+		}
+		if program.Statements[0].String() != tt.expected {
+			t.Errorf("%q - expected=%q, got=%q", tt.input, tt.expected, program.Statements[0].String())
+		}
+	}
+
+	p := New(lexer.New("try { 1 } catch err { 2 }"))
+	stmt := p.ParseProgram().Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.TryExpression)
+	if !ok {
+		t.Fatalf("exp is not *ast.TryExpression. got=%T", stmt.Expression)
+	}
+	if exp.Param == nil || exp.Param.Value != "err" {
+		t.Errorf("the catch has the wrong name. got=%v", exp.Param)
+	}
+	if exp.Finally != nil {
+		t.Errorf("there is a finally that nobody wrote")
+	}
+}
+
+func TestTryErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"try { 1 }", "try needs a catch or a finally"},
+		{"try 1", "expected next token to be: {, got: INT instead"},
+		{"try { 1 } catch e 2", "expected next token to be: {, got: INT instead"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.ParserErrors()
+		if len(errors) == 0 {
+			t.Fatalf("%q - expected an error", tt.input)
+		}
+		if errors[0].Message != tt.expected {
+			t.Errorf("%q - error wrong. got=%q", tt.input, errors[0].Message)
+		}
+	}
+}
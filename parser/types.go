@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/token"
+)
+
+/*
+Types are either a name (int, string, nil...) or a function type like
+fn(int, string): bool. We don't check the names here, that's the type checker's job.
+It starts on the first token of the type and ends on its last one.
+*/
+func (p *Parser) parseTypeAnnotation() *ast.TypeAnnotation {
+	switch p.currentToken.Type {
+	case token.IDENT, token.NIL:
+		return &ast.TypeAnnotation{Token: p.currentToken, Name: p.currentToken.Literal}
+	case token.FUNCTION:
+		return p.parseFunctionType()
+	}
+
+	p.addError(p.currentToken, "expected a type, got: %s instead", p.currentToken.Type)
+	return nil
+}
+
+func (p *Parser) parseFunctionType() *ast.TypeAnnotation {
+	annotation := &ast.TypeAnnotation{Token: p.currentToken, Name: "fn"}
+
+	if !p.peekCompareThenAdvance(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+
+	for p.currentToken.Type != token.RPAREN {
+		param := p.parseTypeAnnotation()
+		if param == nil {
+			return nil
+		}
+		annotation.Parameters = append(annotation.Parameters, param)
+
+		p.nextToken()
+		if p.currentToken.Type == token.COMMA {
+			p.nextToken()
+		} else if p.currentToken.Type != token.RPAREN {
+			p.addError(p.currentToken, "expected next token to be: %s, got: %s instead",
+				token.RPAREN, p.currentToken.Type)
+			return nil
+		}
+	}
+
+	if p.peekToken.Type == token.COLON {
+		p.nextToken()
+		p.nextToken()
+		annotation.ReturnType = p.parseTypeAnnotation()
+		if annotation.ReturnType == nil {
+			return nil
+		}
+	}
+
+	return annotation
+}
+
+// This is the optional ": type" after a name. It ends on the token after the type.
+func (p *Parser) parseOptionalAnnotation() *ast.TypeAnnotation {
+	if p.currentToken.Type != token.COLON {
+		return nil
+	}
+
+	p.nextToken()
+	annotation := p.parseTypeAnnotation()
+	p.nextToken()
+	return annotation
+}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/lexer"
+)
+
+func TestTypeAnnotations(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var x: int = 5;", "var x: int = 5;"},
+		{"const name: string = 'myte';", "const name: string = 'myte';"},
+		{"var nothing: nil;", "var nothing: nil = ;"},
+		{"var f: fn(int, string): bool = g;", "var f: fn(int, string): bool = g;"},
+		{"var cb: fn(): nil = g;", "var cb: fn(): nil = g;"},
+		{"var h: fn(fn(int): int) = g;", "var h: fn(fn(int): int) = g;"},
+		{"fn(a: string, b: int): bool { a }", "fn(a: string,b: int): bool{a}"},
+		{"fn(a, b: int) { a }", "fn(a,b: int){a}"},
+		{"fn(): int { 1 }", "fn(): int{1}"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("%q - expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestParameterTypes(t *testing.T) {
+	p := New(lexer.New("fn(a, b: int, c: fn(): bool) {}"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	fn := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if len(fn.ParameterTypes) != 3 {
+		t.Fatalf("fn.ParameterTypes does not contain 3 entries. got=%d", len(fn.ParameterTypes))
+	}
+	if fn.ParameterTypes[0] != nil {
+		t.Errorf("a should not have an annotation. got=%s", fn.ParameterTypes[0])
+	}
+	if fn.ParameterTypes[1].Name != "int" {
+		t.Errorf("b should be int. got=%s", fn.ParameterTypes[1])
+	}
+	if fn.ParameterTypes[2].String() != "fn(): bool" {
+		t.Errorf("c should be fn(): bool. got=%s", fn.ParameterTypes[2])
+	}
+}
+
+func TestTypeAnnotationErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"var x: = 5;", "expected a type, got: = instead"},
+		{"var x: 5 = 5;", "expected a type, got: INT instead"},
+		{"fn(a): { a }", "expected a type, got: { instead"},
+		{"fn(a) int { a }", "expected next token to be: {, got: IDENT instead"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.ParserErrors()
+		if len(errors) == 0 || errors[0].Message != tt.expectedMessage {
+			t.Errorf("%q - expected error %q. got=%v", tt.input, tt.expectedMessage, p.Errors())
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package repl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/compiler"
+	"github.com/santos-404/myte/evaluator"
+	"github.com/santos-404/myte/lexer"
+	"github.com/santos-404/myte/optimize"
+	"github.com/santos-404/myte/token"
+	"github.com/santos-404/myte/typecheck"
+)
+
+type metaCommand struct {
+	name        string
+	usage       string
+	description string
+	run         func(s *session, arg string)
+}
+
+// This is filled on init() because :help needs to walk the list itself
+var metaCommands []metaCommand
+
+func init() {
+	metaCommands = []metaCommand{
+		{":ast", ":ast <code>", "show the parsed tree", (*session).commandAST},
+		{":tokens", ":tokens <code>", "show the lexer output", (*session).commandTokens},
+		{":disasm", ":disasm <code>", "show the bytecode the vm would run", (*session).commandDisasm},
+		{":env", ":env", "list the current bindings", (*session).commandEnv},
+		{":reset", ":reset", "forget every binding", (*session).commandReset},
+		{":load", ":load <file>", "run a file in this session", (*session).commandLoad},
+		{":type", ":type <expr>", "show the type of an expression", (*session).commandType},
+		{":builtins", ":builtins [name]", "list the builtins, or what one of them or a module has", (*session).commandBuiltins},
+		{":help", ":help", "show this help", (*session).commandHelp},
+		{CANCEL, CANCEL, "abort a half-typed entry", nil},
+	}
+}
+
+func (s *session) runCommand(line string) {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	for _, command := range metaCommands {
+		if command.name == name && command.run != nil {
+			command.run(s, arg)
+			return
+		}
+	}
+	io.WriteString(s.out, "\tunknown command: "+name+". Type :help to see the list\n")
+}
+
+func (s *session) commandAST(arg string) {
+	program, ok := s.parse(arg)
+	if !ok {
+		return
+	}
+
+	var out bytes.Buffer
+	dumpNode(&out, program, 0)
+	io.WriteString(s.out, out.String())
+}
+
+func (s *session) commandTokens(arg string) {
+	if isIncomplete(arg) {
+		io.WriteString(s.out, "\tincomplete input\n")
+		return
+	}
+
+	l := lexer.New(arg + "\n")
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		fmt.Fprintf(s.out, "%-10s %-12q Line: %d, column: %d\n",
+			tok.Type, tok.Literal, tok.Line, tok.Column)
+	}
+}
+
+// The code is compiled on its own, the bindings of the session are not there
+func (s *session) commandDisasm(arg string) {
+	program, ok := s.parse(arg)
+	if !ok {
+		return
+	}
+
+	optimized, _ := optimize.Program(program)
+	c := compiler.New()
+	if err := c.Compile(optimized); err != nil {
+		io.WriteString(s.out, "\t"+err.Error()+"\n")
+		return
+	}
+	compiler.Disassemble(s.out, c.Bytecode(), arg)
+}
+
+func (s *session) commandEnv(arg string) {
+	for _, name := range s.env.Names() {
+		val, _ := s.env.Get(name)
+
+		keyword := "var"
+		if s.env.IsConst(name) {
+			keyword = "const"
+		}
+		fmt.Fprintf(s.out, "%s %s = %s\n", keyword, name, val.Inspect())
+	}
+}
+
+func (s *session) commandReset(arg string) {
+	s.env = s.newEnvironment()
+	s.types = typecheck.New()
+	io.WriteString(s.out, "\tenvironment reset\n")
+}
+
+func (s *session) commandLoad(arg string) {
+	if arg == "" {
+		io.WriteString(s.out, "\tusage: :load <file>\n")
+		return
+	}
+
+	content, err := os.ReadFile(arg)
+	if err != nil {
+		io.WriteString(s.out, "\t"+err.Error()+"\n")
+		return
+	}
+	s.eval(string(content))
+}
+
+// The type is the inferred one, so the expression is never run
+func (s *session) commandType(arg string) {
+	program, ok := s.parse(arg)
+	if !ok {
+		return
+	}
+
+	var statement *ast.ExpressionStatement
+	if len(program.Statements) == 1 {
+		statement, _ = program.Statements[0].(*ast.ExpressionStatement)
+	}
+	if statement == nil {
+		io.WriteString(s.out, "\tnot an expression\n")
+		return
+	}
+
+	t, findings := s.types.TypeOf(statement.Expression)
+	for _, finding := range findings {
+		io.WriteString(s.out, "\t"+finding.String()+"\n")
+	}
+	io.WriteString(s.out, t.String()+"\n")
+}
+
+// Every builtin with its signature, what one of them does when it's named, or what a
+// standard module has
+func (s *session) commandBuiltins(arg string) {
+	if arg == "" {
+		for _, builtin := range evaluator.Builtins() {
+			io.WriteString(s.out, builtin.Signature()+"\n")
+		}
+		for _, module := range evaluator.StdModules() {
+			io.WriteString(s.out, "import \""+module.Name+"\"  # "+module.Doc+"\n")
+		}
+		return
+	}
+
+	if builtin, ok := evaluator.LookupBuiltin(arg); ok {
+		io.WriteString(s.out, builtin.Signature()+"\n\t"+builtin.Doc+"\n")
+		return
+	}
+	module, ok := evaluator.LookupStdModule(arg)
+	if !ok {
+		io.WriteString(s.out, "\tno builtin or module called: "+arg+"\n")
+		return
+	}
+	for _, fn := range module.Functions {
+		io.WriteString(s.out, fn.Signature()+"\n\t"+fn.Doc+"\n")
+	}
+}
+
+func (s *session) commandHelp(arg string) {
+	for _, command := range metaCommands {
+		fmt.Fprintf(s.out, "%-16s %s\n", command.usage, command.description)
+	}
+}
+
+
+// This prints one node per line, and its children indented below it
+func dumpNode(out *bytes.Buffer, node ast.Node, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	switch node := node.(type) {
+	case *ast.Program:
+		out.WriteString(indent + "Program\n")
+		for _, stmt := range node.Statements {
+			dumpNode(out, stmt, depth+1)
+		}
+	case *ast.ExpressionStatement:
+		out.WriteString(indent + "ExpressionStatement\n")
+		dumpNode(out, node.Expression, depth+1)
+	case *ast.VarStatement:
+		out.WriteString(indent + "VarStatement " + node.Name.Value + annotation(node.Type) + "\n")
+		dumpNode(out, node.Value, depth+1)
+	case *ast.ConstStatement:
+		out.WriteString(indent + "ConstStatement " + node.Name.Value + annotation(node.Type) + "\n")
+		dumpNode(out, node.Value, depth+1)
+	case *ast.ReturnStatement:
+		out.WriteString(indent + "ReturnStatement\n")
+		dumpNode(out, node.ReturnValue, depth+1)
+	case *ast.ThrowStatement:
+		out.WriteString(indent + "ThrowStatement\n")
+		dumpNode(out, node.Value, depth+1)
+	case *ast.BlockStatement:
+		out.WriteString(indent + "BlockStatement\n")
+		for _, stmt := range node.Statements {
+			dumpNode(out, stmt, depth+1)
+		}
+	case *ast.PrefixExpression:
+		out.WriteString(indent + "PrefixExpression " + node.Operator + "\n")
+		dumpNode(out, node.Right, depth+1)
+	case *ast.InfixExpression:
+		out.WriteString(indent + "InfixExpression " + node.Operator + "\n")
+		dumpNode(out, node.Left, depth+1)
+		dumpNode(out, node.Right, depth+1)
+	case *ast.AssignExpression:
+		out.WriteString(indent + "AssignExpression " + node.Name.Value + " " + node.Operator + "\n")
+		dumpNode(out, node.Value, depth+1)
+	case *ast.IfExpression:
+		out.WriteString(indent + "IfExpression\n")
+		dumpNode(out, node.Condition, depth+1)
+		dumpNode(out, node.Consequence, depth+1)
+		if node.Alternative != nil {
+			dumpNode(out, node.Alternative, depth+1)
+		}
+	case *ast.ForExpression:
+		out.WriteString(indent + "ForExpression\n")
+		dumpNode(out, node.Condition, depth+1)
+		dumpNode(out, node.Body, depth+1)
+	case *ast.FunctionLiteral:
+		var params []string
+		for i, param := range node.Parameters {
+			if i < len(node.ParameterTypes) {
+				params = append(params, param.Value+annotation(node.ParameterTypes[i]))
+			} else {
+				params = append(params, param.Value)
+			}
+		}
+		out.WriteString(indent + "FunctionLiteral (" + strings.Join(params, ", ") + ")" +
+			annotation(node.ReturnType) + "\n")
+		dumpNode(out, node.Body, depth+1)
+	case *ast.CallExpression:
+		out.WriteString(indent + "CallExpression\n")
+		dumpNode(out, node.Function, depth+1)
+		for _, arg := range node.Arguments {
+			dumpNode(out, arg, depth+1)
+		}
+	case *ast.MemberExpression:
+		out.WriteString(indent + "MemberExpression ." + node.Member.Value + "\n")
+		dumpNode(out, node.Object, depth+1)
+	case *ast.ArrayLiteral:
+		out.WriteString(indent + "ArrayLiteral\n")
+		for _, element := range node.Elements {
+			dumpNode(out, element, depth+1)
+		}
+	case *ast.MapLiteral:
+		out.WriteString(indent + "MapLiteral\n")
+		for i, key := range node.Keys {
+			dumpNode(out, key, depth+1)
+			dumpNode(out, node.Values[i], depth+2)
+		}
+	case *ast.IndexExpression:
+		out.WriteString(indent + "IndexExpression\n")
+		dumpNode(out, node.Left, depth+1)
+		dumpNode(out, node.Index, depth+1)
+	case *ast.PropagateExpression:
+		out.WriteString(indent + "PropagateExpression ?\n")
+		dumpNode(out, node.Value, depth+1)
+	case *ast.TryExpression:
+		if node.Param != nil {
+			out.WriteString(indent + "TryExpression " + node.Param.Value + "\n")
+		} else {
+			out.WriteString(indent + "TryExpression\n")
+		}
+		dumpNode(out, node.Body, depth+1)
+		if node.Catch != nil {
+			dumpNode(out, node.Catch, depth+1)
+		}
+		if node.Finally != nil {
+			dumpNode(out, node.Finally, depth+1)
+		}
+	case nil:
+		out.WriteString(indent + "<nil>\n")
+	default:
+		// Leaves (literals, identifiers, comments) are shown with their own text
+		name := strings.TrimPrefix(fmt.Sprintf("%T", node), "*ast.")
+		out.WriteString(indent + name + " " + node.String() + "\n")
+	}
+}
+
+func annotation(t *ast.TypeAnnotation) string {
+	if t == nil {
+		return ""
+	}
+	return ": " + t.String()
+}
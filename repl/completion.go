@@ -0,0 +1,72 @@
+package repl
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/santos-404/myte/evaluator"
+	"github.com/santos-404/myte/token"
+)
+
+/*
+complete looks at the word right before the cursor and gives back where that word
+starts plus every name that could replace it. The names come from the keywords,
+the builtins, the bindings of the session and, when the line starts with ':', the meta-commands.
+*/
+func complete(buf []rune, pos int, bindings []string) (int, []string) {
+	start := pos
+	for start > 0 && buf[start-1] < 128 && isValidIdentChar(byte(buf[start-1])) {
+		start--
+	}
+	if start > 0 && buf[start-1] == ':' {
+		start--
+	}
+
+	prefix := string(buf[start:pos])
+	before := string(buf[:start])
+
+	var names []string
+	if strings.HasPrefix(prefix, ":") {
+		// Meta-commands only make sense at the beginning of the line
+		if strings.TrimSpace(before) != "" {
+			return start, nil
+		}
+		for _, command := range metaCommands {
+			names = append(names, command.name)
+		}
+	} else {
+		// An empty word would match everything, and that's not helpful at all
+		if prefix == "" || inStringOrComment(before) {
+			return start, nil
+		}
+		names = append(names, token.Keywords()...)
+		for _, builtin := range evaluator.Builtins() {
+			names = append(names, builtin.Name)
+		}
+		names = append(names, bindings...)
+	}
+
+	seen := make(map[string]bool)
+	var candidates []string
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) && !seen[name] {
+			seen[name] = true
+			candidates = append(candidates, name)
+		}
+	}
+	sort.Strings(candidates)
+
+	return start, candidates
+}
+
+// Same rule the lexer follows for identifiers
+func isValidIdentChar(char byte) bool {
+	return 'a' <= char && char <= 'z' || 'A' <= char && char <= 'Z' ||
+		char == '_' || '0' <= char && char <= '9'
+}
+
+// We don't want to complete the words the user is writing inside of a string
+func inStringOrComment(input string) bool {
+	state := scanInput(input)
+	return state.quote != 0 || state.inBlockComment || state.inLineComment
+}
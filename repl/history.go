@@ -0,0 +1,78 @@
+package repl
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const HISTORY_FILE = ".myte_history"
+const MAX_HISTORY = 1000
+
+type history struct {
+	entries []string
+	path    string  // Empty when the history only lives in memory
+}
+
+func historyPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, HISTORY_FILE)
+}
+
+// A missing or unreadable file is not an error, we just start with an empty history
+func loadHistory(path string) *history {
+	h := &history{path: path}
+	if path == "" {
+		return h
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return h
+	}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		h.entries = append(h.entries, scanner.Text())
+	}
+	file.Close()
+
+	if len(h.entries) > MAX_HISTORY {
+		h.trim()
+	}
+	return h
+}
+
+func (h *history) add(line string) {
+	if line == "" || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == line) {
+		return
+	}
+	h.entries = append(h.entries, line)
+
+	if h.path == "" {
+		return
+	}
+	if len(h.entries) > MAX_HISTORY {
+		h.trim()
+		return
+	}
+	file, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return  // Losing the history is not worth breaking the session for
+	}
+	defer file.Close()
+	file.WriteString(line + "\n")
+}
+
+// trim drops the oldest entries, from the file too, so it doesn't grow forever
+func (h *history) trim() {
+	h.entries = h.entries[len(h.entries)-MAX_HISTORY:]
+	if h.path == "" {
+		return
+	}
+	os.WriteFile(h.path, []byte(strings.Join(h.entries, "\n")+"\n"), 0o600)
+}
@@ -0,0 +1,67 @@
+package repl
+
+// This is where the scan ended: how deep inside of brackets, strings or comments
+type scanState struct {
+	depth          int
+	quote          byte  // Zero when we are not inside a string
+	inBlockComment bool
+	inLineComment  bool
+}
+
+/*
+This is a tiny scanner that only cares about one thing: whether the input the user
+typed so far is still "open". We cannot ask the lexer for this because it
+expects complete input (it never returns on an unclosed string and it aborts the
+program on an unclosed block comment), so we walk the raw text ourselves.
+*/
+func scanInput(input string) scanState {
+	var state scanState
+
+	for i := 0; i < len(input); i++ {
+		char := input[i]
+
+		switch {
+		case state.inLineComment:
+			if char == '\n' {
+				state.inLineComment = false
+			}
+		case state.inBlockComment:
+			if char == '-' && i+1 < len(input) && input[i+1] == '#' {
+				state.inBlockComment = false
+				i++
+			}
+		case state.quote != 0:
+			if char == '\\' {
+				i++  // An escaped quote doesn't close it, same as in the lexer
+			} else if char == state.quote {
+				state.quote = 0
+			}
+		default:
+			switch char {
+			case '"', '\'':
+				state.quote = char
+			case '#':
+				if i+1 < len(input) && input[i+1] == '-' {
+					state.inBlockComment = true
+					i++
+				} else {
+					state.inLineComment = true
+				}
+			case '{', '(', '[':
+				state.depth++
+			case '}', ')', ']':
+				state.depth--
+			}
+		}
+	}
+
+	return state
+}
+
+func isIncomplete(input string) bool {
+	state := scanInput(input)
+
+	// A negative depth means there are more closing than opening brackets. That's
+	// not something more lines can fix, so we let the parser complain about it.
+	return state.depth > 0 || state.quote != 0 || state.inBlockComment
+}
@@ -0,0 +1,390 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// The user pressed Ctrl-C. The REPL drops whatever entry was half-typed.
+var errInterrupted = errors.New("interrupted")
+
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+// This is what we use when the input is not a terminal (pipes, files, tests...)
+type scannerReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (r *scannerReader) ReadLine(prompt string) (string, error) {
+	io.WriteString(r.out, prompt)
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
+
+func newLineReader(in io.Reader, out io.Writer) lineReader {
+	inFile, ok := in.(*os.File)
+	if ok && isTerminal(inFile.Fd()) {
+		if outFile, ok := out.(*os.File); ok && isTerminal(outFile.Fd()) {
+			editor := newLineEditor(in, out, loadHistory(historyPath()))
+			editor.fd = inFile.Fd()
+			editor.raw = true
+			return editor
+		}
+	}
+	return &scannerReader{scanner: bufio.NewScanner(in), out: out}
+}
+
+
+type lineEditor struct {
+	in      *bufio.Reader
+	out     io.Writer
+	history *history
+
+	fd  uintptr
+	raw bool  // When false (tests) we don't touch the terminal at all
+
+	prompt       string
+	buf          []rune
+	pos          int  // The cursor, as an index on buf
+	historyIndex int
+	saved        []rune  // What was being typed before walking through the history
+
+	// Called on Tab. It gets the line and the cursor and gives back where the word
+	// being completed starts, plus the candidates. Without it, Tab is just a tab.
+	completer func(buf []rune, pos int) (int, []string)
+}
+
+func newLineEditor(in io.Reader, out io.Writer, h *history) *lineEditor {
+	return &lineEditor{in: bufio.NewReader(in), out: out, history: h}
+}
+
+func (e *lineEditor) ReadLine(prompt string) (string, error) {
+	if e.raw {
+		state, err := makeRaw(e.fd)
+		if err != nil {
+			return "", err
+		}
+		defer restoreTerminal(e.fd, state)
+	}
+
+	e.prompt = prompt
+	e.buf = e.buf[:0]
+	e.pos = 0
+	e.historyIndex = len(e.history.entries)
+	e.saved = nil
+	e.refresh()
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			if err == io.EOF && len(e.buf) > 0 {
+				return e.finish(), nil
+			}
+			return "", err
+		}
+
+		switch r {
+		case '\r', '\n':
+			return e.finish(), nil
+		case 3:  // Ctrl-C
+			io.WriteString(e.out, "^C\n")
+			return "", errInterrupted
+		case 4:  // Ctrl-D
+			if len(e.buf) == 0 {
+				io.WriteString(e.out, "\n")
+				return "", io.EOF
+			}
+			e.deleteForward()
+		case 1:  // Ctrl-A
+			e.pos = 0
+		case 5:  // Ctrl-E
+			e.pos = len(e.buf)
+		case 2:  // Ctrl-B
+			e.moveLeft()
+		case 6:  // Ctrl-F
+			e.moveRight()
+		case 8, 127:  // Backspace
+			e.deleteBackward()
+		case 23:  // Ctrl-W
+			e.deleteWordBackward()
+		case 21:  // Ctrl-U
+			e.buf = e.buf[:copy(e.buf, e.buf[e.pos:])]
+			e.pos = 0
+		case 11:  // Ctrl-K
+			e.buf = e.buf[:e.pos]
+		case 12:  // Ctrl-L
+			io.WriteString(e.out, "\x1b[H\x1b[2J")
+		case 16:  // Ctrl-P
+			e.historyPrevious()
+		case 14:  // Ctrl-N
+			e.historyNext()
+		case '\t':
+			if e.completer == nil {
+				e.insert(r)
+			} else {
+				e.completeWord()
+			}
+		case 27:  // ESC, the start of the arrows and friends
+			e.readEscape()
+		default:
+			if unicode.IsPrint(r) {
+				e.insert(r)
+			}
+		}
+		e.refresh()
+	}
+}
+
+func (e *lineEditor) finish() string {
+	io.WriteString(e.out, "\n")
+	line := string(e.buf)
+	if strings.TrimSpace(line) != "" {
+		e.history.add(line)
+	}
+	return line
+}
+
+/*
+The keys that don't fit in a byte arrive as escape sequences. The common ones are
+"ESC [ A" for the arrows, "ESC [ 3 ~" for delete and "ESC b" for Alt-b. We read the
+whole sequence even when we don't know it, so it doesn't end up on the line.
+*/
+func (e *lineEditor) readEscape() {
+	r, _, err := e.in.ReadRune()
+	if err != nil {
+		return
+	}
+
+	switch r {
+	case '[':
+		var params strings.Builder
+		for {
+			c, _, err := e.in.ReadRune()
+			if err != nil {
+				return
+			}
+			if c >= 0x40 && c <= 0x7e {
+				e.handleCSI(params.String(), c)
+				return
+			}
+			params.WriteRune(c)
+		}
+	case 'O':
+		c, _, err := e.in.ReadRune()
+		if err != nil {
+			return
+		}
+		e.handleCSI("", c)
+	case 'b':
+		e.moveWordLeft()
+	case 'f':
+		e.moveWordRight()
+	case 'd':
+		e.deleteWordForward()
+	case 127, 8:
+		e.deleteWordBackward()
+	}
+}
+
+func (e *lineEditor) handleCSI(params string, final rune) {
+	// Ctrl and Alt with an arrow send "1;5" or "1;3" as parameters
+	word := strings.HasSuffix(params, ";5") || strings.HasSuffix(params, ";3")
+
+	switch final {
+	case 'A':
+		e.historyPrevious()
+	case 'B':
+		e.historyNext()
+	case 'C':
+		if word {
+			e.moveWordRight()
+		} else {
+			e.moveRight()
+		}
+	case 'D':
+		if word {
+			e.moveWordLeft()
+		} else {
+			e.moveLeft()
+		}
+	case 'H':
+		e.pos = 0
+	case 'F':
+		e.pos = len(e.buf)
+	case '~':
+		switch params {
+		case "1", "7":
+			e.pos = 0
+		case "4", "8":
+			e.pos = len(e.buf)
+		case "3":
+			e.deleteForward()
+		}
+	}
+}
+
+/*
+With one candidate we just write it. With more, we write as much as all of them
+share and, if that doesn't move the cursor at all, we list them under the line.
+*/
+func (e *lineEditor) completeWord() {
+	start, candidates := e.completer(e.buf, e.pos)
+	if len(candidates) == 0 {
+		return
+	}
+
+	common := []rune(candidates[0])
+	for _, candidate := range candidates[1:] {
+		common = commonPrefix(common, []rune(candidate))
+	}
+
+	if len(common) > e.pos-start {
+		rest := append(common, e.buf[e.pos:]...)
+		e.buf = append(e.buf[:start], rest...)
+		e.pos = start + len(common)
+		return
+	}
+
+	if len(candidates) > 1 {
+		io.WriteString(e.out, "\n"+strings.Join(candidates, "  ")+"\n")
+	}
+}
+
+func commonPrefix(a, b []rune) []rune {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return a[:i]
+}
+
+func (e *lineEditor) insert(r rune) {
+	e.buf = append(e.buf, 0)
+	copy(e.buf[e.pos+1:], e.buf[e.pos:])
+	e.buf[e.pos] = r
+	e.pos++
+}
+
+func (e *lineEditor) moveLeft() {
+	if e.pos > 0 {
+		e.pos--
+	}
+}
+
+func (e *lineEditor) moveRight() {
+	if e.pos < len(e.buf) {
+		e.pos++
+	}
+}
+
+func (e *lineEditor) deleteBackward() {
+	if e.pos == 0 {
+		return
+	}
+	e.buf = append(e.buf[:e.pos-1], e.buf[e.pos:]...)
+	e.pos--
+}
+
+func (e *lineEditor) deleteForward() {
+	if e.pos < len(e.buf) {
+		e.buf = append(e.buf[:e.pos], e.buf[e.pos+1:]...)
+	}
+}
+
+// A word here is anything between spaces, which is what most shells do too
+func (e *lineEditor) wordStart() int {
+	i := e.pos
+	for i > 0 && unicode.IsSpace(e.buf[i-1]) {
+		i--
+	}
+	for i > 0 && !unicode.IsSpace(e.buf[i-1]) {
+		i--
+	}
+	return i
+}
+
+func (e *lineEditor) wordEnd() int {
+	i := e.pos
+	for i < len(e.buf) && unicode.IsSpace(e.buf[i]) {
+		i++
+	}
+	for i < len(e.buf) && !unicode.IsSpace(e.buf[i]) {
+		i++
+	}
+	return i
+}
+
+func (e *lineEditor) moveWordLeft() {
+	e.pos = e.wordStart()
+}
+
+func (e *lineEditor) moveWordRight() {
+	e.pos = e.wordEnd()
+}
+
+func (e *lineEditor) deleteWordBackward() {
+	start := e.wordStart()
+	e.buf = append(e.buf[:start], e.buf[e.pos:]...)
+	e.pos = start
+}
+
+func (e *lineEditor) deleteWordForward() {
+	end := e.wordEnd()
+	e.buf = append(e.buf[:e.pos], e.buf[end:]...)
+}
+
+func (e *lineEditor) historyPrevious() {
+	if e.historyIndex == 0 {
+		return
+	}
+	if e.historyIndex == len(e.history.entries) {
+		e.saved = append([]rune(nil), e.buf...)
+	}
+	e.historyIndex--
+	e.setBuffer([]rune(e.history.entries[e.historyIndex]))
+}
+
+func (e *lineEditor) historyNext() {
+	if e.historyIndex >= len(e.history.entries) {
+		return
+	}
+	e.historyIndex++
+	if e.historyIndex == len(e.history.entries) {
+		e.setBuffer(e.saved)
+		return
+	}
+	e.setBuffer([]rune(e.history.entries[e.historyIndex]))
+}
+
+func (e *lineEditor) setBuffer(content []rune) {
+	e.buf = append(e.buf[:0], content...)
+	e.pos = len(e.buf)
+}
+
+// We redraw the whole line every time. It's simple and lines are short anyway.
+func (e *lineEditor) refresh() {
+	var out strings.Builder
+
+	out.WriteString("\r")
+	out.WriteString(e.prompt)
+	out.WriteString(string(e.buf))
+	out.WriteString("\x1b[K")  // Clear whatever was left on the right
+	if back := len(e.buf) - e.pos; back > 0 {
+		fmt.Fprintf(&out, "\x1b[%dD", back)
+	}
+
+	io.WriteString(e.out, out.String())
+}
@@ -1,39 +1,136 @@
 package repl
 
 import (
-	"bufio"
-	"fmt"
 	"io"
+	"strings"
 
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/evaluator"
 	"github.com/santos-404/myte/lexer"
+	"github.com/santos-404/myte/object"
 	"github.com/santos-404/myte/parser"
+	"github.com/santos-404/myte/typecheck"
 )
 
 
 const PROMPT = ">>"
+const CONTINUATION_PROMPT = ".."
+
+// Typing this on a continuation line throws away the half-typed entry
+const CANCEL = ":cancel"
+
+// Everything the REPL must remember between one line and the next lives here
+type session struct {
+	out   io.Writer
+	env   *object.Environment
+	types *typecheck.Checker  // Knows the inferred type of every binding in env
+}
 
 func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
+	s := &session{out: out, types: typecheck.New()}
+	s.env = s.newEnvironment()
+	reader := newLineReader(in, out)
+	if editor, ok := reader.(*lineEditor); ok {
+		editor.completer = func(buf []rune, pos int) (int, []string) {
+			return complete(buf, pos, s.env.Names())
+		}
+	}
+
+	var pending []string  // The lines of an entry that is not complete yet
+	blankLines := 0
 
 	for {  // This is a common while true loop
-		fmt.Printf(PROMPT)
-		scanned := scanner.Scan()
-		if !scanned {
+		prompt := PROMPT
+		if len(pending) > 0 {
+			prompt = CONTINUATION_PROMPT
+		}
+
+		line, err := reader.ReadLine(prompt)
+		if err == errInterrupted {
+			pending, blankLines = nil, 0
+			continue
+		}
+		if err != nil {
 			return
 		}
 
-		line := scanner.Text()
-		l := lexer.New(line)
-		p := parser.New(l)
+		if len(pending) > 0 {
+			if strings.TrimSpace(line) == CANCEL {
+				pending, blankLines = nil, 0
+				io.WriteString(out, "\tinput cancelled\n")
+				continue
+			}
+
+			// Two blank lines in a row are the other way out of a continuation
+			if strings.TrimSpace(line) == "" {
+				blankLines++
+				if blankLines == 2 {
+					pending, blankLines = nil, 0
+					io.WriteString(out, "\tinput cancelled\n")
+					continue
+				}
+			} else {
+				blankLines = 0
+			}
+		} else if strings.HasPrefix(strings.TrimSpace(line), ":") {
+			s.runCommand(strings.TrimSpace(line))
+			continue
+		}
 
-		program := p.ParseProgram()
-		if len(p.Errors()) != 0 {
-			printParserErrors(out, p.Errors())
+		pending = append(pending, line)
+		input := strings.Join(pending, "\n")
+		if isIncomplete(input) {
+			continue
 		}
-		
-		io.WriteString(out, program.String())
-		io.WriteString(out, "\n")
+		pending, blankLines = nil, 0
+
+		s.eval(input)
+	}
+}
+
+// print and println write where the results go. input() reads from the process,
+// the REPL only reads the lines it's given.
+func (s *session) newEnvironment() *object.Environment {
+	env := object.NewEnvironment()
+	env.SetIO(&object.IO{Stdout: s.out, Stdin: evaluator.ProcessIO.Stdin, Exit: evaluator.ProcessIO.Exit})
+	return env
+}
+
+func (s *session) eval(input string) {
+	program, ok := s.parse(input)
+	if !ok {
+		return
+	}
+
+	// Type errors are not shown here; the evaluator will complain if they are real
+	s.types.Check(program)
+	evaluated := evaluator.Eval(program, s.env)
+	s.printResult(evaluated)
+}
+
+// The boolean is false when there were parser errors. They are already printed then.
+func (s *session) parse(input string) (*ast.Program, bool) {
+	l := lexer.New(input + "\n")  // Line comments need a '\n' to finish
+	p := parser.New(l)
+
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParserErrors(s.out, p.Errors())
+		return nil, false
+	}
+	return program, true
+}
+
+func (s *session) printResult(evaluated object.Object) {
+	// print and the like give back nil, nobody wants to see it below what they printed
+	if evaluated == nil || evaluated.Type() == object.NIL_OBJ {
+		return
+	}
+	if evaluated.Type() == object.RUNTIME_ERROR_OBJ {
+		io.WriteString(s.out, "\t"+evaluated.Inspect()+"\n")
+		return
 	}
+	io.WriteString(s.out, evaluated.Inspect()+"\n")
 }
 
 func printParserErrors(out io.Writer, errors []string) {
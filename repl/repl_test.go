@@ -0,0 +1,285 @@
+package repl
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsIncomplete(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"var x = 5;", false},
+		{"fn(a) {", true},
+		{"fn(a) {\n\treturn a;\n}", false},
+		{"foo(1,", true},
+		{"[1, 2", true},
+		{"var s = 'not closed", true},
+		{"var s = \"a { inside\";", false},
+		{"var s = 'it\\'s", true},
+		{"var s = 'it\\'s';", false},
+		{"#- open block comment", true},
+		{"#- closed -# var x = 1;", false},
+		{"# a line comment with {\nvar x = 1;", false},
+		{"}", false},
+	}
+
+	for i, tt := range tests {
+		if got := isIncomplete(tt.input); got != tt.expected {
+			t.Errorf("tests[%d] - isIncomplete(%q) wrong. expected=%t, got=%t",
+				i, tt.input, tt.expected, got)
+		}
+	}
+}
+
+// The prompts are removed so the tests only care about what the REPL answers
+func runREPL(input string) string {
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+
+	output := strings.ReplaceAll(out.String(), PROMPT, "")
+	return strings.ReplaceAll(output, CONTINUATION_PROMPT, "")
+}
+
+func TestStartMultiLine(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"var f = fn(a) {\nreturn a;\n}\nf(3)\n", "3\n"},
+		{"fn(a) {\n\n\n2 + 2\n", "\tinput cancelled\n4\n"},
+		{"foo(1,\n:cancel\n1\n", "\tinput cancelled\n1\n"},
+	}
+
+	for i, tt := range tests {
+		if output := runREPL(tt.input); output != tt.expected {
+			t.Errorf("tests[%d] - output wrong. expected=%q, got=%q", i, tt.expected, output)
+		}
+	}
+}
+
+func TestStartWritesPrompts(t *testing.T) {
+	var out bytes.Buffer
+	Start(strings.NewReader("fn(a) {\n}\n"), &out)
+
+	expected := PROMPT + CONTINUATION_PROMPT
+	if !strings.HasPrefix(out.String(), expected) {
+		t.Errorf("prompts not written to out. expected prefix=%q, got=%q", expected, out.String())
+	}
+}
+
+func TestSessionKeepsBindings(t *testing.T) {
+	output := runREPL("var x = 1;\nconst y = x + 1;\ny * 10\n:env\n:reset\n:env\nx\n")
+
+	expected := "20\nvar x = 1\nconst y = 2\n\tenvironment reset\n" +
+		"\tERROR: identifier not found: x. Line: 0, column: 1\n"
+	if output != expected {
+		t.Errorf("output wrong. expected=%q, got=%q", expected, output)
+	}
+}
+
+func TestMetaCommands(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{":type 1 + 1.5\n", "float\n"},
+		{":type 'a'\n", "string\n"},
+		{"const add = fn(a: int, b: int) { a + b };\n:type add\n", "fn(int, int): int\n"},
+		{"var s = 'a';\n:type s - 1\n", "\terror: unsupported operand types: string - int. Line: 0, column: 3\nany\n"},
+		{":type var x = 1;\n", "\tnot an expression\n"},
+		{"var s = 'a';\n:reset\n:type s\n", "\tenvironment reset\nany\n"},
+		{":ast -a\n", "Program\n  ExpressionStatement\n    PrefixExpression -\n      Identifier a\n"},
+		{":tokens x = 1\n", "IDENT      \"x\"          Line: 0, column: 1\n" +
+			"=          \"=\"          Line: 0, column: 3\n" +
+			"INT        \"1\"          Line: 0, column: 5\n"},
+		{":nope\n", "\tunknown command: :nope. Type :help to see the list\n"},
+		{":disasm 1 + x\n", "== main ==\n" +
+			"     ; 0 | 1 + x\n" +
+			"0000 OpConstant 0           1                        0:1\n" +
+			"0003 OpGetGlobal 0          x                        0:5\n" +
+			"0006 OpAdd                                           0:3\n" +
+			"0007 OpReturnValue                                   0:3\n"},
+		{":disasm var len = 1;\n", "\tcannot redeclare builtin: len. Line: 0, column: 5\n"},
+		{":builtins len\n", "len(value: string | array | map): int\n\tGives back the number of characters of a string, elements of an array or keys of a map\n"},
+		{":builtins nope\n", "\tno builtin or module called: nope\n"},
+		{"println('hi', 1 + 1)\n", "hi 2\n"},
+		{"print('a'); print('b'); len('abc')\n", "ab3\n"},
+	}
+
+	for i, tt := range tests {
+		if output := runREPL(tt.input); output != tt.expected {
+			t.Errorf("tests[%d] - output wrong. expected=%q, got=%q", i, tt.expected, output)
+		}
+	}
+}
+
+func TestLoadCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lib.myte")
+	if err := os.WriteFile(path, []byte("const double = fn(x) { x * 2 };\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := runREPL(":load " + path + "\ndouble(21)\n")
+	if output != "42\n" {
+		t.Errorf("output wrong. expected=%q, got=%q", "42\n", output)
+	}
+}
+
+func TestLineEditor(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"abc\r", []string{"abc"}},
+		{"abc\x1b[D\x1b[DX\r", []string{"aXbc"}},
+		{"abc\x01X\x05Y\r", []string{"XabcY"}},
+		{"abcd\x7f\x7f\r", []string{"ab"}},
+		{"foo bar\x17baz\r", []string{"foo baz"}},
+		{"foo bar\x1bb\x1bd\r", []string{"foo "}},
+		{"one two\x1b[1;5D\x0b\r", []string{"one "}},
+		{"abc\x1b[H\x1b[3~\r", []string{"bc"}},
+		{"one\rtwo\r\x1b[A\x1b[A\r", []string{"one", "two", "one"}},
+		{"one\rtwo\r\x1b[A\x1b[A\x1b[B\x1b[Bnew\r", []string{"one", "two", "new"}},
+		{"half\x03next\r", []string{"next"}},
+	}
+
+	for i, tt := range tests {
+		var out bytes.Buffer
+		editor := newLineEditor(strings.NewReader(tt.input), &out, &history{})
+
+		var lines []string
+		for {
+			line, err := editor.ReadLine(PROMPT)
+			if err == errInterrupted {
+				continue
+			}
+			if err != nil {
+				break
+			}
+			lines = append(lines, line)
+		}
+
+		if strings.Join(lines, "|") != strings.Join(tt.expected, "|") {
+			t.Errorf("tests[%d] - lines wrong. expected=%q, got=%q", i, tt.expected, lines)
+		}
+	}
+}
+
+func TestHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), HISTORY_FILE)
+
+	h := loadHistory(path)
+	h.add("var x = 1;")
+	h.add("var x = 1;")  // Repeated entries are only stored once
+	h.add("x")
+
+	reloaded := loadHistory(path)
+	if strings.Join(reloaded.entries, "|") != "var x = 1;|x" {
+		t.Errorf("history entries wrong. got=%q", reloaded.entries)
+	}
+}
+
+func TestHistoryFileIsTrimmed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), HISTORY_FILE)
+
+	var lines []string
+	for i := 0; i < MAX_HISTORY+10; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600)
+
+	h := loadHistory(path)
+	h.add("last")
+
+	content, _ := os.ReadFile(path)
+	stored := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if len(stored) != MAX_HISTORY {
+		t.Fatalf("the file has the wrong number of entries. expected=%d, got=%d", MAX_HISTORY, len(stored))
+	}
+	if stored[0] != "line 11" || stored[len(stored)-1] != "last" {
+		t.Errorf("the file kept the wrong entries. first=%q, last=%q", stored[0], stored[len(stored)-1])
+	}
+}
+
+func TestComplete(t *testing.T) {
+	bindings := []string{"result", "retries", "fooBar"}
+
+	tests := []struct {
+		line          string
+		pos           int
+		expectedStart int
+		expected      []string
+	}{
+		{"re", 2, 0, []string{"result", "retries", "return"}},
+		{"var x = fo", 10, 8, []string{"fooBar", "for"}},
+		{"con", 3, 0, []string{"const", "continue"}},
+		{"fn(a) { ret", 11, 8, []string{"retries", "return"}},
+		{"res + 1", 3, 0, []string{"result"}},
+		{":t", 2, 0, []string{":tokens", ":type"}},
+		{"  :re", 5, 2, []string{":reset"}},
+		{"x :re", 5, 2, nil},
+		{"'re", 3, 1, nil},
+		{"x + ", 4, 4, nil},
+		{"zzz", 3, 0, nil},
+		{"pr", 2, 0, []string{"print", "println"}},
+		{"var n = le", 10, 8, []string{"len"}},
+		{":b", 2, 0, []string{":builtins"}},
+	}
+
+	for i, tt := range tests {
+		start, candidates := complete([]rune(tt.line), tt.pos, bindings)
+		if start != tt.expectedStart {
+			t.Errorf("tests[%d] - start wrong. expected=%d, got=%d", i, tt.expectedStart, start)
+		}
+		if strings.Join(candidates, "|") != strings.Join(tt.expected, "|") {
+			t.Errorf("tests[%d] - candidates wrong. expected=%q, got=%q", i, tt.expected, candidates)
+		}
+	}
+}
+
+func TestLineEditorTab(t *testing.T) {
+	bindings := []string{"counter", "count"}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"ret\t\r", "return"},
+		{"cou\t\r", "count"},
+		{"cou\t\tx\r", "countx"},
+		{":lo\t x\r", ":load x"},
+		{"zz\t\r", "zz"},
+	}
+
+	for i, tt := range tests {
+		var out bytes.Buffer
+		editor := newLineEditor(strings.NewReader(tt.input), &out, &history{})
+		editor.completer = func(buf []rune, pos int) (int, []string) {
+			return complete(buf, pos, bindings)
+		}
+
+		line, err := editor.ReadLine(PROMPT)
+		if err != nil {
+			t.Fatalf("tests[%d] - unexpected error: %s", i, err)
+		}
+		if line != tt.expected {
+			t.Errorf("tests[%d] - line wrong. expected=%q, got=%q", i, tt.expected, line)
+		}
+	}
+
+	var out bytes.Buffer
+	editor := newLineEditor(strings.NewReader("count\t\r"), &out, &history{})
+	editor.completer = func(buf []rune, pos int) (int, []string) {
+		return complete(buf, pos, bindings)
+	}
+	editor.ReadLine(PROMPT)
+	if !strings.Contains(out.String(), "\ncount  counter\n") {
+		t.Errorf("candidates not listed. got=%q", out.String())
+	}
+}
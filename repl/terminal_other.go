@@ -0,0 +1,21 @@
+//go:build !linux
+
+package repl
+
+import "errors"
+
+// The line editor only knows how to drive Linux terminals. Anywhere else the REPL
+// falls back to reading plain lines.
+type terminalState struct{}
+
+func isTerminal(fd uintptr) bool {
+	return false
+}
+
+func makeRaw(fd uintptr) (*terminalState, error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
+
+func restoreTerminal(fd uintptr, state *terminalState) error {
+	return nil
+}
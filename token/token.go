@@ -1,5 +1,7 @@
 package token
 
+import "sort"
+
 // Update this to an int or a byte might be a good option for the future
 type TokenType byte 
 
@@ -39,6 +41,8 @@ const (
 	COMMA
 	SEMICOLON
 	COLON
+	DOT
+	QUESTION
 
 	LPAREN
 	RPAREN
@@ -61,7 +65,11 @@ const (
 	BREAK
 	CONTINUE
 	NIL
-	IMPORT  // Not sure if I will use this.
+	IMPORT
+	TRY
+	CATCH
+	FINALLY
+	THROW
 )
 
 
@@ -89,6 +97,20 @@ var keywords = map[string]TokenType {
 	"continue": CONTINUE,
 	"nil": NIL,
 	"import": IMPORT,
+	"try": TRY,
+	"catch": CATCH,
+	"finally": FINALLY,
+	"throw": THROW,
+}
+
+// Keywords gives back every keyword of the language, sorted. The REPL uses them to autocomplete.
+func Keywords() []string {
+	names := make([]string, 0, len(keywords))
+	for name := range keywords {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func LookupIdent(ident string) TokenType {
@@ -132,6 +154,8 @@ var tokenTypeStrings = [...]string{
 	",",
 	";",
 	":",
+	".",
+	"?",
 	"(",
 	")",
 	"{",
@@ -153,6 +177,10 @@ var tokenTypeStrings = [...]string{
 	"CONTINUE",
 	"NIL",
 	"IMPORT",
+	"TRY",
+	"CATCH",
+	"FINALLY",
+	"THROW",
 }
 
 func (tt TokenType) String() string {
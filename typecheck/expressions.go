@@ -0,0 +1,311 @@
+package typecheck
+
+import (
+	"github.com/santos-404/myte/ast"
+)
+
+// typeOf gives back the type of an expression, reporting whatever is wrong inside of it
+func (c *Checker) typeOf(exp ast.Expression) Type {
+	t := c.expressionType(exp)
+	if exp != nil {
+		c.result.Types[exp] = t
+	}
+	return t
+}
+
+func (c *Checker) expressionType(exp ast.Expression) Type {
+	switch exp := exp.(type) {
+	case *ast.IntegerLiteral:
+		return Int
+	case *ast.FloatLiteral:
+		return Float
+	case *ast.StringLiteral:
+		return String
+	case *ast.BooleanLiteral:
+		return Bool
+	case *ast.NilLiteral:
+		return Nil
+	case *ast.Identifier:
+		if t, ok := builtinType(exp.Value); ok {
+			return t
+		}
+		if b, ok := c.scope.lookup(exp.Value); ok {
+			return b.t
+		}
+		return Any
+	case *ast.PrefixExpression:
+		return c.prefixType(exp)
+	case *ast.InfixExpression:
+		return c.infixType(exp)
+	case *ast.AssignExpression:
+		return c.assignType(exp)
+	case *ast.IfExpression:
+		c.typeOf(exp.Condition)
+		consequence := c.checkBlock(exp.Consequence)
+		if exp.Alternative == nil {
+			return join(consequence, Nil)
+		}
+		return join(consequence, c.checkBlock(exp.Alternative))
+	case *ast.ForExpression:
+		c.typeOf(exp.Condition)
+		c.checkBlock(exp.Body)
+		return Nil
+	case *ast.FunctionLiteral:
+		return c.functionType(exp)
+	case *ast.CallExpression:
+		return c.callType(exp)
+	case *ast.MemberExpression:
+		return c.memberType(exp)
+	case *ast.ArrayLiteral:
+		for _, element := range exp.Elements {
+			c.typeOf(element)
+		}
+		return Array
+	case *ast.MapLiteral:
+		for i, key := range exp.Keys {
+			if t := c.typeOf(key); t != String && t != Int && t != Bool && !isDynamic(t) {
+				c.addError(exp.Token, "unusable as map key: %s", t)
+			}
+			c.typeOf(exp.Values[i])
+		}
+		return Map
+	case *ast.IndexExpression:
+		return c.indexType(exp)
+	case *ast.PropagateExpression:
+		return c.propagateType(exp)
+	case *ast.TryExpression:
+		return c.tryType(exp)
+	}
+	return Any
+}
+
+func (c *Checker) prefixType(exp *ast.PrefixExpression) Type {
+	right := c.typeOf(exp.Right)
+
+	switch exp.Operator {
+	case "!":
+		return Bool
+	case "-", "++", "--":
+		if isDynamic(right) || isNumeric(right) {
+			return right
+		}
+	}
+
+	c.addError(exp.Token, "unsupported operand type: %s%s", exp.Operator, right)
+	return Any
+}
+
+func (c *Checker) infixType(exp *ast.InfixExpression) Type {
+	left := c.typeOf(exp.Left)
+	right := c.typeOf(exp.Right)
+
+	result, ok := operationType(exp.Operator, left, right)
+	if !ok {
+		c.addError(exp.Token, "unsupported operand types: %s %s %s", left, exp.Operator, right)
+		return Any
+	}
+	return result
+}
+
+// operationType follows what the evaluator does with each operator. The boolean is
+// false when the evaluator would fail with those operands.
+func operationType(operator string, left, right Type) (Type, bool) {
+	if left == Never {
+		left = Any
+	}
+	if right == Never {
+		right = Any
+	}
+
+	switch operator {
+	case "==", "!=":
+		return Bool, true
+	case "<", "<=", ">", ">=":
+		if left == Any || right == Any ||
+			isNumeric(left) && isNumeric(right) || left == String && right == String {
+			return Bool, true
+		}
+		return nil, false
+	case "+":
+		if left == String && right == String {
+			return String, true
+		}
+		if left == Array && right == Array || left == Map && right == Map {
+			return left, true
+		}
+		if left == Any && (right == String || right == Any) || right == Any && left == String {
+			return Any, true
+		}
+	}
+
+	// From here on, it's only arithmetic
+	if (left != Any && !isNumeric(left)) || (right != Any && !isNumeric(right)) {
+		return nil, false
+	}
+	if left == Any || right == Any {
+		return Any, true
+	}
+	if operator == "/" || left == Float || right == Float {
+		return Float, true
+	}
+	if operator == "**" {
+		return Any, true  // An int, unless the power is negative or too big for one
+	}
+	return Int, true
+}
+
+// What is inside of arrays and maps is not tracked, so only strings give back a known type
+// Modules are not typed, so only the members of an error are known
+func (c *Checker) memberType(exp *ast.MemberExpression) Type {
+	if c.typeOf(exp.Object) != Error {
+		return Any
+	}
+	switch exp.Member.Value {
+	case "message", "kind":
+		return String
+	case "line", "column":
+		return Int
+	case "trace":
+		return Array
+	}
+	c.addError(exp.Token, "error has no member: %s", exp.Member.Value)
+	return Any
+}
+
+// An error is returned right there, so ? on one never gives a value
+func (c *Checker) propagateType(exp *ast.PropagateExpression) Type {
+	t := c.typeOf(exp.Value)
+	if t == Error {
+		c.returnType(exp.Token, Error)
+		return Never
+	}
+	return t
+}
+
+// The body may stop at any point, so its value is only one of the two a try can give
+func (c *Checker) tryType(exp *ast.TryExpression) Type {
+	t := c.checkBlock(exp.Body)
+	if exp.Catch != nil {
+		c.scope = newScope(c.scope)
+		if exp.Param != nil {
+			c.declare(exp.Param, Error, false)
+		}
+		t = join(t, c.checkBlock(exp.Catch))
+		c.scope = c.scope.outer
+	}
+	if exp.Finally != nil {
+		c.checkBlock(exp.Finally)
+	}
+	return t
+}
+
+func (c *Checker) indexType(exp *ast.IndexExpression) Type {
+	left := c.typeOf(exp.Left)
+	index := c.typeOf(exp.Index)
+
+	if left == Map {
+		if index != String && index != Int && index != Bool && !isDynamic(index) {
+			c.addError(exp.Token, "unusable as map key: %s", index)
+		}
+		return Any
+	}
+	if !isDynamic(index) && index != Int {
+		c.addError(exp.Token, "index must be int, got %s", index)
+	}
+	switch {
+	case left == String:
+		return String
+	case left == Array || isDynamic(left):
+		return Any
+	}
+	c.addError(exp.Token, "cannot index %s", left)
+	return Any
+}
+
+func (c *Checker) assignType(exp *ast.AssignExpression) Type {
+	valueType := c.typeOf(exp.Value)
+
+	b, ok := c.scope.lookup(exp.Name.Value)
+	if !ok {
+		return valueType
+	}
+	target := b.t
+
+	if exp.Operator != "=" {
+		result, ok := operationType(exp.Operator[:1], target, valueType)
+		if !ok {
+			c.addError(exp.Token, "unsupported operand types: %s %s %s", target, exp.Operator, valueType)
+			return Any
+		}
+		valueType = result
+	}
+
+	if b.inferred {
+		// Nobody said what this one holds, so now it holds both
+		if !c.probing {
+			b.t = join(target, valueType)
+		}
+	} else if !assignable(valueType, target) {
+		c.addError(exp.Token, "cannot assign %s to %s (%s)", valueType, exp.Name.Value, target)
+	}
+	return valueType
+}
+
+func (c *Checker) functionType(fn *ast.FunctionLiteral) Type {
+	t := &Function{Return: c.fromAnnotation(fn.ReturnType)}
+
+	c.scope = newScope(c.scope)
+	for i, param := range fn.Parameters {
+		var annotation *ast.TypeAnnotation
+		if i < len(fn.ParameterTypes) {
+			annotation = fn.ParameterTypes[i]
+		}
+		paramType := c.fromAnnotation(annotation)
+		t.Params = append(t.Params, paramType)
+		c.declare(param, paramType, false)
+	}
+
+	frame := &returnFrame{declared: t.Return, annotated: fn.ReturnType != nil}
+	c.returns = append(c.returns, frame)
+	last := c.checkBlock(fn.Body)
+	c.returns = c.returns[:len(c.returns)-1]
+	c.scope = c.scope.outer
+
+	if !frame.annotated {
+		// Falling off the end of the body gives back its last value
+		t.Return = join(frame.inferred, last)
+		if t.Return == Never {
+			t.Return = Nil
+		}
+	}
+	return t
+}
+
+func (c *Checker) callType(call *ast.CallExpression) Type {
+	callee := c.typeOf(call.Function)
+
+	var args []Type
+	for _, arg := range call.Arguments {
+		args = append(args, c.typeOf(arg))
+	}
+
+	if isDynamic(callee) {
+		return Any
+	}
+	fn, ok := callee.(*Function)
+	if !ok {
+		c.addError(call.Token, "not a function: %s", callee)
+		return Any
+	}
+
+	if len(args) != len(fn.Params) {
+		c.addError(call.Token, "wrong number of arguments: want=%d, got=%d", len(fn.Params), len(args))
+		return fn.Return
+	}
+	for i, arg := range args {
+		if !assignable(arg, fn.Params[i]) {
+			c.addError(call.Token, "cannot use %s as %s in argument %d of the call", arg, fn.Params[i], i+1)
+		}
+	}
+	return fn.Return
+}
@@ -0,0 +1,217 @@
+package typecheck
+
+import (
+	"fmt"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/checker"
+	"github.com/santos-404/myte/token"
+)
+
+type Result struct {
+	Findings []checker.Finding
+	Types    map[ast.Expression]Type   // The type of every expression we went through
+	Bindings map[*ast.Identifier]Type  // The type each declared name (or parameter) got
+}
+
+type binding struct {
+	t        Type
+	inferred bool  // It has no annotation, so assigning something else just makes it dynamic
+}
+
+type scope struct {
+	names map[string]*binding
+	outer *scope
+}
+
+func newScope(outer *scope) *scope {
+	return &scope{names: make(map[string]*binding), outer: outer}
+}
+
+func (s *scope) lookup(name string) (*binding, bool) {
+	for current := s; current != nil; current = current.outer {
+		if b, ok := current.names[name]; ok {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// Every function we are in gets one of these, the last one is the closest
+type returnFrame struct {
+	declared  Type
+	annotated bool
+	inferred  Type  // The join of every return we saw, when it's not annotated
+}
+
+/*
+Checker verifies the annotated parts of the program and infers the types of the rest
+from the values that are used: literals, operators, initializers and what functions
+return. Parameters without annotation (and anything that depends on them) have the
+type any, and any is compatible with anything, so dynamic code goes through
+untouched. Names that cannot be found are also any: reporting them is the job of the
+checker package.
+
+The top level scope lives as long as the Checker, so it can check one program after
+another as if they were a single one. That's what the REPL does.
+*/
+type Checker struct {
+	result  *Result
+	globals *scope
+	scope   *scope
+	returns []*returnFrame
+	probing bool  // Set by TypeOf, so assignments don't widen the bindings we keep
+}
+
+func New() *Checker {
+	globals := newScope(nil)
+	return &Checker{globals: globals, scope: globals}
+}
+
+func Check(program *ast.Program) *Result {
+	return New().Check(program)
+}
+
+// Check gives back the findings of this program only, but keeps its top level bindings
+func (c *Checker) Check(program *ast.Program) *Result {
+	c.reset()
+	c.checkStatements(program.Statements)
+
+	checker.SortFindings(c.result.Findings)
+	return c.result
+}
+
+// TypeOf gives back the type of an expression, and whatever is wrong inside of it,
+// without keeping anything it declares
+func (c *Checker) TypeOf(exp ast.Expression) (Type, []checker.Finding) {
+	c.reset()
+	c.scope = newScope(c.globals)
+	c.probing = true
+	defer func() { c.scope, c.probing = c.globals, false }()
+
+	t := c.typeOf(exp)
+	checker.SortFindings(c.result.Findings)
+	return t, c.result.Findings
+}
+
+func (c *Checker) reset() {
+	c.result = &Result{
+		Types:    make(map[ast.Expression]Type),
+		Bindings: make(map[*ast.Identifier]Type),
+	}
+	c.scope = c.globals
+	c.returns = nil
+}
+
+func (c *Checker) addError(tok token.Token, format string, a ...interface{}) {
+	c.result.Findings = append(c.result.Findings, checker.Finding{
+		Severity: checker.Error,
+		Message:  fmt.Sprintf(format, a...),
+		Line:     tok.Line,
+		Column:   tok.Column,
+	})
+}
+
+func (c *Checker) declare(name *ast.Identifier, t Type, inferred bool) {
+	c.scope.names[name.Value] = &binding{t: t, inferred: inferred}
+	c.result.Bindings[name] = t
+}
+
+func (c *Checker) checkStatements(statements []ast.Statement) {
+	for _, stmt := range statements {
+		c.checkStatement(stmt)
+	}
+}
+
+// checkBlock also gives back the value the block ends with, which is what an if
+// expression (or a function without return) evaluates to
+func (c *Checker) checkBlock(block *ast.BlockStatement) Type {
+	if block == nil || len(block.Statements) == 0 {
+		return Nil
+	}
+
+	c.checkStatements(block.Statements[:len(block.Statements)-1])
+
+	switch last := block.Statements[len(block.Statements)-1].(type) {
+	case *ast.ExpressionStatement:
+		if _, ok := last.Expression.(*ast.CommentExpression); ok {
+			return Nil
+		}
+		return c.typeOf(last.Expression)
+	case *ast.ReturnStatement, *ast.ThrowStatement, *ast.BreakStatement, *ast.ContinueStatement:
+		c.checkStatement(last)
+		return Never  // The end of the block is never reached
+	default:
+		c.checkStatement(last)
+		return Nil
+	}
+}
+
+func (c *Checker) checkStatement(stmt ast.Statement) {
+	switch stmt := stmt.(type) {
+	case *ast.ExpressionStatement:
+		c.typeOf(stmt.Expression)
+	case *ast.VarStatement:
+		if stmt != nil {
+			c.checkDeclaration(stmt.Name, stmt.Type, stmt.Value)
+		}
+	case *ast.ConstStatement:
+		if stmt != nil {
+			c.checkDeclaration(stmt.Name, stmt.Type, stmt.Value)
+		}
+	case *ast.ReturnStatement:
+		c.checkReturn(stmt)
+	case *ast.ThrowStatement:
+		if t := c.typeOf(stmt.Value); t != Error && t != String && !isDynamic(t) {
+			c.addError(stmt.Token, "cannot throw %s", t)
+		}
+	case *ast.BlockStatement:
+		c.checkBlock(stmt)
+	case *ast.ImportStatement:
+		c.declare(stmt.Name, Any, false)  // Modules are not typed, so neither is what they have
+	}
+}
+
+func (c *Checker) checkReturn(stmt *ast.ReturnStatement) {
+	c.returnType(stmt.Token, c.typeOf(stmt.ReturnValue))
+}
+
+// returnType takes note of a value the current function gives back, from a return or a ?
+func (c *Checker) returnType(tok token.Token, valueType Type) {
+	if len(c.returns) == 0 {
+		return
+	}
+
+	frame := c.returns[len(c.returns)-1]
+	if !frame.annotated {
+		frame.inferred = join(frame.inferred, valueType)
+		return
+	}
+	if !assignable(valueType, frame.declared) {
+		c.addError(tok, "cannot return %s from a function that returns %s", valueType, frame.declared)
+	}
+}
+
+func (c *Checker) checkDeclaration(name *ast.Identifier, annotation *ast.TypeAnnotation, value ast.Expression) {
+	// The declared type goes in first, so a function can call itself with the right type
+	declared := c.fromAnnotation(annotation)
+	if annotation != nil {
+		c.declare(name, declared, false)
+	}
+
+	valueType := c.typeOf(value)
+
+	// "var x: int;" gets a nil made up by the parser; we don't blame the user for that one
+	if nilLiteral, ok := value.(*ast.NilLiteral); ok && nilLiteral.Token.Literal == "" && annotation != nil {
+		valueType = declared
+	}
+
+	if annotation == nil {
+		c.declare(name, valueType, true)
+		return
+	}
+	if !assignable(valueType, declared) {
+		c.addError(name.Token, "cannot use %s as %s in the declaration of %s", valueType, declared, name.Value)
+	}
+	c.declare(name, declared, false)
+}
@@ -0,0 +1,203 @@
+package typecheck
+
+import (
+	"testing"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/lexer"
+	"github.com/santos-404/myte/parser"
+)
+
+func testCheck(t *testing.T, input string) *Result {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has errors for %q: %v", input, p.Errors())
+	}
+	return Check(program)
+}
+
+func TestAnnotatedCode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"var x: int = 5; var y: float = x; var z: float = 1.5;", nil},
+		{"var x: int = 'five';", []string{"cannot use string as int in the declaration of x"}},
+		{"var x: int = 1.5;", []string{"cannot use float as int in the declaration of x"}},
+		{"var x: int;", nil},
+		{"var x: int = nil;", []string{"cannot use nil as int in the declaration of x"}},
+		{"var x: number = 1;", []string{"unknown type: number"}},
+		{"var x: int = 1; x = 'a';", []string{"cannot assign string to x (int)"}},
+		{"var s: string = 'a'; s += 1;", []string{"unsupported operand types: string += int"}},
+		{"var a: int = 1; var b: string = 'b'; a + b;", []string{"unsupported operand types: int + string"}},
+		{"var a: bool = true; -a;", []string{"unsupported operand type: -bool"}},
+		{"var a: string = 'a'; a < 'b'; a + 'b';", nil},
+		{"const f = fn(a: int, b: int): int { return a + b; }; f(1);",
+			[]string{"wrong number of arguments: want=2, got=1"}},
+		{"const f = fn(a: int): int { return a; }; f('1');",
+			[]string{"cannot use string as int in argument 1 of the call"}},
+		{"const f = fn(a: float): float { return a; }; f(1);", nil},
+		{"fn(): bool { return 1; };", []string{"cannot return int from a function that returns bool"}},
+		{"fn(): int { return 1 / 2; };", []string{"cannot return float from a function that returns int"}},
+		{"var n: int = 1; n();", []string{"not a function: int"}},
+		{"var cb: fn(int): int = fn(a: int): int { return a; };", nil},
+		{"var cb: fn(int): int = fn(a: string): int { return 1; };",
+			[]string{"cannot use fn(string): int as fn(int): int in the declaration of cb"}},
+	}
+
+	for _, tt := range tests {
+		result := testCheck(t, tt.input)
+
+		if len(result.Findings) != len(tt.expected) {
+			t.Errorf("%q - wrong number of findings. expected=%q, got=%q", tt.input, tt.expected, result.Findings)
+			continue
+		}
+		for i, finding := range result.Findings {
+			if finding.Message != tt.expected[i] {
+				t.Errorf("%q - findings[%d] wrong. expected=%q, got=%q", tt.input, i, tt.expected[i], finding.Message)
+			}
+		}
+	}
+}
+
+// Code without annotations must go through untouched, whatever it does at runtime
+func TestUnannotatedCodeStaysDynamic(t *testing.T) {
+	input := `
+var x = 1;
+x = "now a string";
+var f = fn(a, b) { a + b };
+f(1, "2");
+f = fn(a) { a };
+f(1, 2, 3);
+var g = fn(n) { return n * 2; };
+g("a") - 1;
+`
+	result := testCheck(t, input)
+	if len(result.Findings) != 0 {
+		t.Errorf("unexpected findings: %q", result.Findings)
+	}
+}
+
+func TestInference(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"var s = 'a'; s - 1;", []string{"unsupported operand types: string - int"}},
+		{"'a' - 1;", []string{"unsupported operand types: string - int"}},
+		{"var n = 5; n();", []string{"not a function: int"}},
+		{"const half = 1 / 2; var x: int = half;", []string{"cannot use float as int in the declaration of x"}},
+		{"var x = if true { 1 } else { 2.5 }; var y: int = x;",
+			[]string{"cannot use float as int in the declaration of y"}},
+		{"var x = if true { 'a' }; x + 'b';", nil},
+		{"const f = fn() { return 'a'; }; f() * 2;", []string{"unsupported operand types: string * int"}},
+		{"const f = fn(a) { a }; f(1) * 2;", nil},
+		{"const f = fn() { 1 }; var x: string = f();", []string{"cannot use int as string in the declaration of x"}},
+		{"const f = fn(n) { if n { return 1; } return 2.5; }; var x: int = f(true);",
+			[]string{"cannot use float as int in the declaration of x"}},
+		{"const f = fn(a, b) { a }; f(1);", []string{"wrong number of arguments: want=2, got=1"}},
+		{"var x = 1; x = 'a'; x - 1;", nil},
+		{"var x = 1; x += 0.5; var y: int = x;", []string{"cannot use float as int in the declaration of y"}},
+		{"var a = [1, 2]; a + [3]; a[0] + 1;", nil},
+		{"var a: array = [1]; a - 1;", []string{"unsupported operand types: array - int"}},
+		{"var s = 'abc'; s[0] - 1;", []string{"unsupported operand types: string - int"}},
+		{"[1]['a'];", []string{"index must be int, got string"}},
+		{"var n = 5; n[0];", []string{"cannot index int"}},
+		{"var m = {'a': 1}; m + {'b': 2}; m['a'] + 1; m[1];", nil},
+		{"var m: map = {}; m + [1];", []string{"unsupported operand types: map + array"}},
+		{"{1.5: 1};", []string{"unusable as map key: float"}},
+		{"var m = {}; m[[1]];", []string{"unusable as map key: array"}},
+		// Builtins with a fixed signature are typed, the rest are any
+		{"var n: string = len('abc');", []string{"cannot use int as string in the declaration of n"}},
+		{"type(1, 2);", []string{"wrong number of arguments: want=1, got=2"}},
+		{"str(1) - 1;", []string{"unsupported operand types: string - int"}},
+		{"var x: int = int('5') + int(2.5);", nil},
+		{"println(1, 'a'); input() + 1; assert(true);", nil},
+		{"var e: error = error('bad'); var m: string = e.message; e.line + e.column;", nil},
+		{"error('bad').message - 1;", []string{"unsupported operand types: string - int"}},
+		{"error('bad').code;", []string{"error has no member: code"}},
+		{"var n: int = 1; n.code;", nil},
+		{"fn(): int { error('bad')?; 1 };", []string{"cannot return error from a function that returns int"}},
+		{"try { 1 } catch e { var m: string = e.message; var k: string = e.kind; };", nil},
+		{"var x: int = try { 1 } catch e { 2 };", nil},
+		{"try { 1 } catch e { e.code };", []string{"error has no member: code"}},
+		{"try { 1 } catch e { e.trace + [] };", nil},
+		{"throw 'bad'; throw error('bad');", nil},
+		{"throw 1;", []string{"cannot throw int"}},
+		{"fn(s: string): int { int(s)? };", nil},
+	}
+
+	for _, tt := range tests {
+		result := testCheck(t, tt.input)
+
+		if len(result.Findings) != len(tt.expected) {
+			t.Errorf("%q - wrong number of findings. expected=%q, got=%q", tt.input, tt.expected, result.Findings)
+			continue
+		}
+		for i, finding := range result.Findings {
+			if finding.Message != tt.expected[i] {
+				t.Errorf("%q - findings[%d] wrong. expected=%q, got=%q", tt.input, i, tt.expected[i], finding.Message)
+			}
+		}
+	}
+}
+
+func TestInferredTypes(t *testing.T) {
+	tests := []struct {
+		input    string
+		name     string
+		expected string
+	}{
+		{"var total = 1 + 2;", "total", "int"},
+		{"const ratio = 1 / 2;", "ratio", "float"},
+		{"const big = 2 ** 64;", "big", "any"},
+		{"var greeting = 'hi' + '!';", "greeting", "string"},
+		{"var flag = 1 < 2;", "flag", "bool"},
+		{"var x = if true { 1 } else { 2.5 };", "x", "float"},
+		{"var x = if true { 1 };", "x", "any"},
+		{"const add = fn(a: int, b: int) { return a + b; };", "add", "fn(int, int): int"},
+		{"const id = fn(a) { a };", "id", "fn(any): any"},
+		{"const noop = fn() {};", "noop", "fn(): nil"},
+		{"const sign = fn(n: int) { if n < 0 { return -1; } 1 };", "sign", "fn(int): int"},
+		{"const fail = fn() { error('always')? };", "fail", "fn(): error"},
+		{"const half = fn(n) { int(n)? / 2 };", "half", "fn(any): any"},
+	}
+
+	for _, tt := range tests {
+		result := testCheck(t, tt.input)
+
+		found := false
+		for ident, typ := range result.Bindings {
+			if ident.Value != tt.name {
+				continue
+			}
+			found = true
+			if typ.String() != tt.expected {
+				t.Errorf("%q - type of %s wrong. expected=%q, got=%q", tt.input, tt.name, tt.expected, typ)
+			}
+		}
+		if !found {
+			t.Errorf("%q - no binding for %s", tt.input, tt.name)
+		}
+	}
+}
+
+// The REPL checks one entry after another, and what's declared must be remembered
+func TestCheckerKeepsBindings(t *testing.T) {
+	c := New()
+	for _, input := range []string{"var s = 'a';", "const f = fn() { 2 };"} {
+		c.Check(parser.New(lexer.New(input)).ParseProgram())
+	}
+
+	result := c.Check(parser.New(lexer.New("s - f();")).ParseProgram())
+	if len(result.Findings) != 1 || result.Findings[0].Message != "unsupported operand types: string - int" {
+		t.Errorf("unexpected findings: %q", result.Findings)
+	}
+
+	program := parser.New(lexer.New("f() + 1.5")).ParseProgram()
+	statement := program.Statements[0].(*ast.ExpressionStatement)
+	if typ, findings := c.TypeOf(statement.Expression); typ != Float || len(findings) != 0 {
+		t.Errorf("TypeOf wrong. expected=float, got=%s", typ)
+	}
+}
@@ -0,0 +1,168 @@
+package typecheck
+
+import (
+	"strings"
+
+	"github.com/santos-404/myte/ast"
+	"github.com/santos-404/myte/evaluator"
+)
+
+type Type interface {
+	String() string
+}
+
+type Basic struct {
+	name string
+}
+
+func (b *Basic) String() string { return b.name }
+
+/*
+Any is what dynamic code gets: the checker trusts it and says nothing about it.
+Never is the type of something that doesn't produce a value at all, like a block
+that always returns. It fits anywhere and it disappears when joined with anything.
+*/
+var (
+	Int    = &Basic{"int"}
+	Float  = &Basic{"float"}
+	String = &Basic{"string"}
+	Bool   = &Basic{"bool"}
+	Nil    = &Basic{"nil"}
+	Array  = &Basic{"array"}
+	Map    = &Basic{"map"}
+	Error  = &Basic{"error"}
+	Any    = &Basic{"any"}
+	Never  = &Basic{"never"}
+)
+
+var basicTypes = map[string]Type{
+	"int":    Int,
+	"float":  Float,
+	"string": String,
+	"bool":   Bool,
+	"nil":    Nil,
+	"array":  Array,
+	"map":    Map,
+	"error":  Error,
+	"any":    Any,
+}
+
+type Function struct {
+	Params []Type
+	Return Type
+}
+
+func (f *Function) String() string {
+	var params []string
+	for _, param := range f.Params {
+		params = append(params, param.String())
+	}
+	return "fn(" + strings.Join(params, ", ") + "): " + f.Return.String()
+}
+
+/*
+builtinType is what we know about a builtin. Only those with a fixed number of
+parameters get a function type; the others (and the parameters taking more than one
+type) are any, since there is no way to write them down.
+*/
+func builtinType(name string) (Type, bool) {
+	builtin, ok := evaluator.LookupBuiltin(name)
+	if !ok {
+		return nil, false
+	}
+	if builtin.Variadic {
+		return Any, true
+	}
+
+	fn := &Function{Return: Any}
+	for _, param := range builtin.Parameters {
+		if param.Optional {
+			return Any, true
+		}
+		var t Type = Any
+		if len(param.Types) == 1 {
+			if basic, ok := basicTypes[string(param.Types[0])]; ok {
+				t = basic
+			}
+		}
+		fn.Params = append(fn.Params, t)
+	}
+	if t, ok := basicTypes[builtin.Returns]; ok {
+		fn.Return = t
+	}
+	return fn, true
+}
+
+func isNumeric(t Type) bool {
+	return t == Int || t == Float
+}
+
+// isDynamic tells whether we cannot say anything about a value of this type
+func isDynamic(t Type) bool {
+	return t == Any || t == Never
+}
+
+// assignable tells whether a value of the first type can be stored where the second
+// one is expected. An int fits where a float is expected, but not the other way round.
+func assignable(value, target Type) bool {
+	if isDynamic(value) || target == Any || value == target {
+		return true
+	}
+	if value == Int && target == Float {
+		return true
+	}
+
+	valueFn, ok := value.(*Function)
+	targetFn, ok2 := target.(*Function)
+	if !ok || !ok2 || len(valueFn.Params) != len(targetFn.Params) {
+		return false
+	}
+	for i := range valueFn.Params {
+		// The parameters go the other way: the target will call us with its own types
+		if !assignable(targetFn.Params[i], valueFn.Params[i]) {
+			return false
+		}
+	}
+	return assignable(valueFn.Return, targetFn.Return)
+}
+
+// join is the type that fits both: the same type, float for mixed numbers or any
+func join(a, b Type) Type {
+	if a == nil || a == Never {
+		return b
+	}
+	if b == nil || b == Never {
+		return a
+	}
+	if a == b {
+		return a
+	}
+	if isNumeric(a) && isNumeric(b) {
+		return Float
+	}
+	if assignable(a, b) && assignable(b, a) && a != Any && b != Any {
+		return a  // Two function types with the same signature
+	}
+	return Any
+}
+
+// A missing annotation (nil) means the value is dynamically typed
+func (c *Checker) fromAnnotation(annotation *ast.TypeAnnotation) Type {
+	if annotation == nil {
+		return Any
+	}
+
+	if annotation.Name == "fn" {
+		fn := &Function{Return: c.fromAnnotation(annotation.ReturnType)}
+		for _, param := range annotation.Parameters {
+			fn.Params = append(fn.Params, c.fromAnnotation(param))
+		}
+		return fn
+	}
+
+	if t, ok := basicTypes[annotation.Name]; ok {
+		return t
+	}
+	c.addError(annotation.Token, "unknown type: %s", annotation.Name)
+	return Any
+}
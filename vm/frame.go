@@ -0,0 +1,31 @@
+package vm
+
+import (
+	"github.com/santos-404/myte/code"
+	"github.com/santos-404/myte/object"
+)
+
+type Frame struct {
+	cl          *object.Closure
+	ip          int
+	basePointer int  // Where the callee was on the stack; it's all dropped on return
+	locals      *object.Locals
+}
+
+func NewFrame(cl *object.Closure, basePointer int) *Frame {
+	size := cl.Fn.NumLocals
+	if cl.Fn.NumParameters > size {
+		size = cl.Fn.NumParameters  // fn(a, a) has two parameters but one slot
+	}
+
+	return &Frame{
+		cl:          cl,
+		ip:          -1,
+		basePointer: basePointer,
+		locals:      &object.Locals{Fn: cl.Fn, Values: make([]object.Object, size)},
+	}
+}
+
+func (f *Frame) Instructions() code.Instructions {
+	return f.cl.Fn.Instructions
+}
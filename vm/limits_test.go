@@ -0,0 +1,89 @@
+package vm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/santos-404/myte/evaluator"
+	"github.com/santos-404/myte/lexer"
+	"github.com/santos-404/myte/object"
+	"github.com/santos-404/myte/parser"
+)
+
+/*
+A step is not the same thing on both engines (a node there, an instruction here),
+so they may stop at different places. They must stop for the same reason though.
+*/
+func TestLimits(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		input    string
+		limits   object.Limits
+		kind     string
+		expected string
+	}{
+		{"for true { 1 }", object.Limits{MaxSteps: 100}, evaluator.STEP_LIMIT_KIND, "step limit exceeded: 100"},
+		{"var f = fn(n) { 1 + f(n + 1) }; f(0)", object.Limits{MaxCallDepth: 20}, evaluator.DEPTH_LIMIT_KIND, "call depth limit exceeded: 20"},
+		{`var s = "ab"; for true { s = s + s; }`, object.Limits{MaxCollectionSize: 100}, evaluator.SIZE_LIMIT_KIND, "collection size limit exceeded: 128, the limit is 100"},
+		{"str([1, 2, 3, 4, 5])", object.Limits{MaxCollectionSize: 10}, evaluator.SIZE_LIMIT_KIND, "collection size limit exceeded: 15, the limit is 10"},
+		{`import "strings"; strings.repeat("ab", 1099511627776)`, object.Limits{MaxCollectionSize: 100}, evaluator.SIZE_LIMIT_KIND, "collection size limit exceeded: 2199023255552, the limit is 100"},
+		{"for true { 1 }", object.Limits{Context: cancelled}, evaluator.CANCELLED_KIND, "execution cancelled: context canceled"},
+		{"for true { try { 1 } catch e { 2 }; }", object.Limits{MaxSteps: 100}, evaluator.STEP_LIMIT_KIND, "step limit exceeded: 100"},
+		{"var f = fn() { try { f() } catch e { 1 } }; f()", object.Limits{MaxCallDepth: 20}, evaluator.DEPTH_LIMIT_KIND, "call depth limit exceeded: 20"},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		env := object.NewEnvironment()
+		env.SetLimits(tt.limits)
+
+		machine := New(compile(t, tt.input))
+		machine.SetLimits(tt.limits)
+
+		results := map[string]object.Object{"eval": evaluator.Eval(program, env), "vm": machine.Run()}
+		for engine, result := range results {
+			err, ok := result.(*object.Error)
+			if !ok {
+				t.Errorf("%q - %s gave no error", tt.input, engine)
+				continue
+			}
+			if err.Message != tt.expected || err.Kind != tt.kind {
+				t.Errorf("%q - %s gave the wrong error. expected=%q (%s), got=%q (%s)", tt.input, engine, tt.expected, tt.kind, err.Message, err.Kind)
+			}
+		}
+	}
+}
+
+func TestWithinLimits(t *testing.T) {
+	machine := New(compile(t, "var f = fn(n) { if n == 0 { return 0; }; 1 + f(n - 1) }; f(19) + f(19)"))
+	machine.SetLimits(object.Limits{MaxSteps: 10000, MaxCallDepth: 20, Context: context.Background()})
+
+	if result := inspect(machine.Run()); result != "38" {
+		t.Errorf("result wrong. expected=38, got=%q", result)
+	}
+}
+
+// Without limits the calls can still not go on forever, on either engine. Running
+// out of stack is an error like any other, the program can catch it.
+func TestStackOverflowOnBothEngines(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"const f = fn(n) { if n == 0 { return 0; }; 1 + f(n - 1) }; f(99998)", "99998"},
+		{"const f = fn(n) { if n == 0 { return 0; }; 1 + f(n - 1) }; f(99999)", "ERROR: stack overflow. Line: 0, column: 49"},
+		{"const f = fn(n) { 1 + f(n + 1) }; try { f(0) } catch e { [e.kind, e.message] }", "['stack_overflow', 'stack overflow']"},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		evaluated := inspect(evaluator.Eval(program, object.NewEnvironment()))
+		compiled := inspect(New(compile(t, tt.input)).Run())
+
+		if evaluated != tt.expected || compiled != tt.expected {
+			t.Errorf("%q - result wrong. expected=%q, got eval=%q and vm=%q", tt.input, tt.expected, evaluated, compiled)
+		}
+	}
+}
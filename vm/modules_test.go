@@ -0,0 +1,74 @@
+package vm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/santos-404/myte/evaluator"
+	"github.com/santos-404/myte/lexer"
+	"github.com/santos-404/myte/object"
+	"github.com/santos-404/myte/parser"
+)
+
+// The modules run on the evaluator either way, but the program that imports them
+// must get the same from both engines, callbacks and errors included
+func TestImportsFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"lib/counter.myte": `var count = 0;
+const next = fn() { count += 1; count };
+const apply = fn(f, x) { f(x) };
+const fail = fn() { 1 / 0 };`,
+		"lib/uses.myte": `import "./counter"; counter.next();`,
+		"lib/loop.myte": `for true { 1 }`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)  // So the paths on the errors are short
+	main := filepath.Join(dir, "main.myte")
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "./lib/counter"; counter.next(); counter.next()`, "2"},
+		// Every module runs once, whoever imports it
+		{`import "./lib/counter"; import "./lib/uses"; counter.next()`, "2"},
+		{`import "./lib/counter"; counter.apply(fn(x) { x * 2 }, 21)`, "42"},
+		{`import "./lib/counter"; const twice = fn(x) { counter.apply(fn(y) { y * 2 }, x) }; counter.apply(twice, 4)`, "8"},
+		{`import "./lib/counter"; counter.apply(fn(x) { try { x / 0 } catch e { e.kind } }, 1)`, "zero_division"},
+		{`import "./lib/counter"; try { counter.apply(fn(x) { x / 0 }, 1) } catch e { [e.kind, len(e.trace)] }`, "['zero_division', 2]"},
+		{`import "./lib/counter"; try { counter.fail() } catch e { e.message }`, "division by zero"},
+		{`import "./lib/counter"; counter.apply(fn(x) { x / 0 }, 1)`, "ERROR: division by zero. Line: 0, column: 49"},
+		{`import "./lib/missing"`, "ERROR: module not found: ./lib/missing. Line: 0, column: 1"},
+		{`try { import "./lib/counter"; import "./lib/loop" } catch e { e.kind }`,
+			"ERROR: step limit exceeded: 1000 (lib/loop.myte, line 0, column 1). Line: 0, column: 31"},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		env := object.NewEnvironment()
+		env.SetImporter(evaluator.NewModules(main).Importer())
+		env.SetLimits(object.Limits{MaxSteps: 1000})
+		evaluated := inspect(evaluator.Eval(program, env))
+
+		machine := New(compile(t, tt.input))
+		machine.SetImporter(evaluator.NewModules(main).Importer())
+		machine.SetLimits(object.Limits{MaxSteps: 1000})
+		compiled := inspect(machine.Run())
+
+		if evaluated != compiled {
+			t.Errorf("%q - the engines disagree. eval=%q, vm=%q", tt.input, evaluated, compiled)
+			continue
+		}
+		if compiled != tt.expected {
+			t.Errorf("%q - result wrong. expected=%q, got=%q", tt.input, tt.expected, compiled)
+		}
+	}
+}
@@ -0,0 +1,613 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/santos-404/myte/code"
+	"github.com/santos-404/myte/compiler"
+	"github.com/santos-404/myte/evaluator"
+	"github.com/santos-404/myte/object"
+	"github.com/santos-404/myte/token"
+)
+
+const (
+	STACK_SIZE   = 2048  // It grows when needed, this is only where it starts
+	GLOBALS_SIZE = 65536
+	MAX_FRAMES   = evaluator.MAX_CALL_DEPTH
+)
+
+// The vm gives back the same singletons as the evaluator, so truthiness agrees
+var (
+	NIL   = evaluator.NIL
+	TRUE  = evaluator.TRUE
+	FALSE = evaluator.FALSE
+)
+
+var infixOperators = map[code.Opcode]string{
+	code.OpAdd:          "+",
+	code.OpSub:          "-",
+	code.OpMul:          "*",
+	code.OpDiv:          "/",
+	code.OpFloorDiv:     "//",
+	code.OpMod:          "%",
+	code.OpPow:          "**",
+	code.OpEqual:        "==",
+	code.OpNotEqual:     "!=",
+	code.OpLess:         "<",
+	code.OpLessEqual:    "<=",
+	code.OpGreater:      ">",
+	code.OpGreaterEqual: ">=",
+}
+
+type VM struct {
+	constants    []object.Object
+	globals      []object.Object  // A nil slot is a global that was not declared (yet)
+	globalConsts []bool
+	globalNames  []string
+
+	stack []object.Object
+	sp    int  // Always points to the next free slot. The top of the stack is stack[sp-1]
+
+	frames   []*Frame
+	handlers []handler  // The try blocks we are in, the innermost is the last one
+
+	usage    *object.Usage    // Nil when it runs without limits
+	importer object.Importer  // The modules are run by the evaluator, see OpImport
+}
+
+// handler is where a try goes on with its catch, and how the vm was when it started
+type handler struct {
+	frame int
+	sp    int
+	ip    int
+}
+
+func New(bytecode *compiler.Bytecode) *VM {
+	main := &object.CompiledFunction{Instructions: bytecode.Instructions, Lines: bytecode.Lines}
+	mainFrame := NewFrame(&object.Closure{Fn: main}, 0)
+
+	return &VM{
+		constants:    bytecode.Constants,
+		globals:      make([]object.Object, GLOBALS_SIZE),
+		globalConsts: make([]bool, GLOBALS_SIZE),
+		globalNames:  bytecode.Globals,
+		stack:        make([]object.Object, STACK_SIZE),
+		frames:       []*Frame{mainFrame},
+	}
+}
+
+// SetLimits bounds what the program can do; every instruction is a step
+func (vm *VM) SetLimits(limits object.Limits) {
+	vm.usage = &object.Usage{Limits: limits}
+}
+
+// SetImporter says where the imports are looked for. Without one, they are looked
+// for from the working directory.
+func (vm *VM) SetImporter(importer object.Importer) {
+	vm.importer = importer
+}
+
+func (vm *VM) currentFrame() *Frame {
+	return vm.frames[len(vm.frames)-1]
+}
+
+/*
+Run gives back what the evaluator would: the value of the last statement, a Go nil
+when it has no value, or an *object.Error when something went wrong.
+*/
+func (vm *VM) Run() object.Object {
+	return vm.run(0)
+}
+
+/*
+run goes on until the frame at index stop returns, and gives back what it returns.
+Only the evaluator makes it stop anywhere but at the end (see callClosure); stop is 0
+then, and the frames and the tries below it belong to whoever called. The top level
+frame is never a call, so it's not on the traces either way.
+*/
+func (vm *VM) run(stop int) object.Object {
+	floor := max(stop, 1)
+	for {
+		frame := vm.currentFrame()
+		frame.ip++
+		ins := frame.Instructions()
+		if frame.ip >= len(ins) {
+			return nil
+		}
+
+		op := code.Opcode(ins[frame.ip])
+		var err *object.Error
+
+		// Nothing can catch these, so there is no need to go on to the handlers
+		if vm.usage != nil {
+			if err = vm.step(); err != nil {
+				vm.trace(err, floor)
+				return err
+			}
+		}
+
+		switch op {
+		case code.OpConstant:
+			index := code.ReadUint16(ins[frame.ip+1:])
+			frame.ip += 2
+			vm.push(vm.constants[index])
+		case code.OpPop:
+			vm.pop()
+		case code.OpTrue:
+			vm.push(TRUE)
+		case code.OpFalse:
+			vm.push(FALSE)
+		case code.OpNil:
+			vm.push(NIL)
+
+		case code.OpAdd, code.OpSub, code.OpMul, code.OpDiv, code.OpFloorDiv, code.OpMod,
+			code.OpPow, code.OpEqual, code.OpNotEqual, code.OpLess, code.OpLessEqual,
+			code.OpGreater, code.OpGreaterEqual:
+			right := vm.pop()
+			left := vm.pop()
+			tok := vm.token()
+			err = vm.pushResult(evaluator.InfixOperationWithin(vm.usage, tok, infixOperators[op], left, right))
+
+		case code.OpMinus:
+			err = vm.pushResult(evaluator.PrefixOperation(vm.token(), "-", vm.pop()))
+		case code.OpBang:
+			vm.push(evaluator.NativeBool(!evaluator.IsTruthy(vm.pop())))
+		case code.OpIncrement:
+			err = vm.pushResult(evaluator.IncrementOperation(vm.token(), "++", vm.pop()))
+		case code.OpDecrement:
+			err = vm.pushResult(evaluator.IncrementOperation(vm.token(), "--", vm.pop()))
+
+		case code.OpJump:
+			position := int(code.ReadUint16(ins[frame.ip+1:]))
+			frame.ip = position - 1
+		case code.OpJumpNotTruthy:
+			position := int(code.ReadUint16(ins[frame.ip+1:]))
+			frame.ip += 2
+			if !evaluator.IsTruthy(vm.pop()) {
+				frame.ip = position - 1
+			}
+
+		case code.OpDefineGlobal:
+			index := code.ReadUint16(ins[frame.ip+1:])
+			isConst := code.ReadUint8(ins[frame.ip+3:]) == 1
+			frame.ip += 3
+			vm.globals[index] = vm.pop()
+			vm.globalConsts[index] = isConst
+		case code.OpGetGlobal:
+			index := code.ReadUint16(ins[frame.ip+1:])
+			frame.ip += 2
+			if vm.globals[index] == nil {
+				err = vm.newKindError(evaluator.NAME_KIND, "identifier not found: %s", vm.globalNames[index])
+				break
+			}
+			vm.push(vm.globals[index])
+		case code.OpSetGlobal:
+			index := code.ReadUint16(ins[frame.ip+1:])
+			frame.ip += 2
+			switch {
+			case vm.globals[index] == nil:
+				err = vm.newKindError(evaluator.NAME_KIND, "identifier not found: %s", vm.globalNames[index])
+			case vm.globalConsts[index]:
+				err = vm.newKindError(evaluator.ASSIGNMENT_KIND, "cannot assign to constant: %s", vm.globalNames[index])
+			default:
+				vm.globals[index] = vm.stack[vm.sp-1]
+			}
+
+		case code.OpDefineLocal:
+			index := code.ReadUint8(ins[frame.ip+1:])
+			frame.ip += 1
+			frame.locals.Values[index] = vm.pop()
+		case code.OpGetLocal:
+			index := code.ReadUint8(ins[frame.ip+1:])
+			frame.ip += 1
+			err = vm.getLocal(frame.locals, int(index))
+		case code.OpSetLocal:
+			index := code.ReadUint8(ins[frame.ip+1:])
+			frame.ip += 1
+			err = vm.setLocal(frame.locals, int(index))
+		case code.OpGetOuter:
+			depth := int(code.ReadUint8(ins[frame.ip+1:]))
+			index := int(code.ReadUint8(ins[frame.ip+2:]))
+			frame.ip += 2
+			if err = vm.checkOuter(frame, depth, index); err == nil {
+				err = vm.getLocal(frame.cl.Outer[depth-1], index)
+			}
+		case code.OpSetOuter:
+			depth := int(code.ReadUint8(ins[frame.ip+1:]))
+			index := int(code.ReadUint8(ins[frame.ip+2:]))
+			frame.ip += 2
+			if err = vm.checkOuter(frame, depth, index); err == nil {
+				err = vm.setLocal(frame.cl.Outer[depth-1], index)
+			}
+		case code.OpAssignConst:
+			name := code.ReadUint16(ins[frame.ip+1:])
+			frame.ip += 2
+			err = vm.newKindError(evaluator.ASSIGNMENT_KIND, "cannot assign to constant: %s", vm.constants[name].Inspect())
+		case code.OpGetBuiltin:
+			index := code.ReadUint8(ins[frame.ip+1:])
+			frame.ip += 1
+			vm.push(evaluator.Builtins()[index].Object())
+
+		case code.OpArray:
+			count := int(code.ReadUint16(ins[frame.ip+1:]))
+			frame.ip += 2
+			elements := make([]object.Object, count)
+			copy(elements, vm.stack[vm.sp-count:vm.sp])
+			vm.sp -= count
+			vm.push(&object.Array{Elements: elements})
+		case code.OpMap:
+			count := 2 * int(code.ReadUint16(ins[frame.ip+1:]))
+			frame.ip += 2
+			pairs := make([]object.Object, count)
+			copy(pairs, vm.stack[vm.sp-count:vm.sp])
+			vm.sp -= count
+			err = vm.pushResult(evaluator.MapOperation(vm.token(), pairs))
+		case code.OpIndex:
+			index := vm.pop()
+			left := vm.pop()
+			err = vm.pushResult(evaluator.IndexOperation(vm.token(), left, index))
+		case code.OpGetMember:
+			name := code.ReadUint16(ins[frame.ip+1:])
+			frame.ip += 2
+			err = vm.pushResult(evaluator.MemberOperation(vm.token(), vm.pop(), vm.constants[name].(*object.String).Value))
+
+		case code.OpImport:
+			path := code.ReadUint16(ins[frame.ip+1:])
+			frame.ip += 2
+			err = vm.importModule(vm.constants[path].(*object.String).Value)
+
+		case code.OpClosure:
+			index := code.ReadUint16(ins[frame.ip+1:])
+			frame.ip += 2
+			vm.pushClosure(int(index))
+		case code.OpCall:
+			numArgs := code.ReadUint8(ins[frame.ip+1:])
+			frame.ip += 1
+			err = vm.callFunction(int(numArgs))
+		case code.OpTailCall:
+			numArgs := code.ReadUint8(ins[frame.ip+1:])
+			frame.ip += 1
+			err = vm.tailCall(int(numArgs))
+		case code.OpPropagate:
+			errValue, ok := vm.stack[vm.sp-1].(*object.ErrorValue)
+			if !ok {
+				break  // Anything but an error stays there, it's the value of the expression
+			}
+			vm.pop()
+			if len(vm.frames) == 1 {
+				return evaluator.UncaughtError(errValue)  // Nobody to give it to, see evalProgram
+			}
+			vm.popFrame()
+			vm.push(errValue)
+		case code.OpTry:
+			position := int(code.ReadUint16(ins[frame.ip+1:]))
+			frame.ip += 2
+			vm.handlers = append(vm.handlers, handler{frame: len(vm.frames) - 1, sp: vm.sp, ip: position})
+		case code.OpEndTry:
+			vm.handlers = vm.handlers[:len(vm.handlers)-1]
+		case code.OpThrow:
+			err = evaluator.ThrowValue(vm.token(), vm.pop())
+		case code.OpOutsideLoop:
+			statement := code.ReadUint8(ins[frame.ip+1:])
+			frame.ip += 1
+			err = evaluator.OutsideLoopError(vm.token(), []string{"break", "continue"}[statement])
+		case code.OpJumpNotError:
+			position := int(code.ReadUint16(ins[frame.ip+1:]))
+			frame.ip += 2
+			if _, ok := vm.stack[vm.sp-1].(*object.ErrorValue); !ok {
+				frame.ip = position - 1
+			}
+
+		case code.OpReturnValue:
+			returnValue := vm.pop()
+			if len(vm.frames) == 1 {
+				return returnValue
+			}
+			vm.popFrame()
+			if len(vm.frames) == stop {
+				return returnValue
+			}
+			vm.push(returnValue)
+		case code.OpReturn:
+			if len(vm.frames) == 1 {
+				return nil
+			}
+			vm.popFrame()
+			if len(vm.frames) == stop {
+				return NIL
+			}
+			vm.push(NIL)
+
+		default:
+			err = vm.newError("unknown opcode: %d", op)
+		}
+
+		if err != nil {
+			vm.trace(err, floor)
+			if len(vm.handlers) == 0 || vm.handlers[len(vm.handlers)-1].frame < stop || evaluator.IsLimitError(err) {
+				return err
+			}
+			vm.catch(err, floor)
+		}
+	}
+}
+
+// trace adds every call we are in, down to the floor, to the error. The innermost first.
+func (vm *VM) trace(err *object.Error, floor int) {
+	for i := len(vm.frames) - 1; i >= floor; i-- {
+		fn := vm.frames[i].cl.Fn
+		caller := vm.frames[i-1]
+		position, _ := caller.cl.Fn.Lines.Lookup(caller.ip)
+
+		err.Trace = append(err.Trace, object.TraceFrame{
+			Function: fn.Name,
+			FnLine:   fn.Line,
+			FnColumn: fn.Column,
+			Line:     position.Line,
+			Column:   position.Column,
+		})
+	}
+}
+
+// catch goes back to the innermost try, dropping every call made since it started.
+// The calls the try itself is in are not part of what the catch gets.
+func (vm *VM) catch(err *object.Error, floor int) {
+	h := vm.handlers[len(vm.handlers)-1]
+	vm.handlers = vm.handlers[:len(vm.handlers)-1]
+	err.Trace = err.Trace[:len(err.Trace)-(h.frame+1-floor)]
+
+	vm.frames = vm.frames[:h.frame+1]
+	vm.sp = h.sp
+	vm.push(evaluator.CaughtError(err))
+	vm.currentFrame().ip = h.ip - 1
+}
+
+func (vm *VM) push(obj object.Object) {
+	if vm.sp >= len(vm.stack) {
+		vm.stack = append(vm.stack, make([]object.Object, len(vm.stack))...)
+	}
+	vm.stack[vm.sp] = obj
+	vm.sp++
+}
+
+func (vm *VM) pop() object.Object {
+	obj := vm.stack[vm.sp-1]
+	vm.sp--
+	return obj
+}
+
+// The shared operations give back an error object instead of a value when they fail
+func (vm *VM) pushResult(result object.Object) *object.Error {
+	if err, ok := result.(*object.Error); ok {
+		return err
+	}
+	vm.push(result)
+	return nil
+}
+
+func (vm *VM) getLocal(locals *object.Locals, index int) *object.Error {
+	if locals.Values[index] == nil {
+		return vm.newKindError(evaluator.NAME_KIND, "identifier not found: %s", locals.Fn.Names[index])
+	}
+	vm.push(locals.Values[index])
+	return nil
+}
+
+func (vm *VM) setLocal(locals *object.Locals, index int) *object.Error {
+	if locals.Values[index] == nil {
+		return vm.newKindError(evaluator.NAME_KIND, "identifier not found: %s", locals.Fn.Names[index])
+	}
+	locals.Values[index] = vm.stack[vm.sp-1]
+	return nil
+}
+
+// The compiler never gets this wrong, but a .mytec file may come from anywhere
+func (vm *VM) checkOuter(frame *Frame, depth, index int) *object.Error {
+	if depth > len(frame.cl.Outer) || index >= len(frame.cl.Outer[depth-1].Values) {
+		return vm.newError("corrupt bytecode: no binding %d on the function %d levels up", index, depth)
+	}
+	return nil
+}
+
+// A closure made here can see our locals, and everything we could see ourselves
+func (vm *VM) pushClosure(index int) {
+	fn := vm.constants[index].(*object.CompiledFunction)
+	frame := vm.currentFrame()
+
+	var outer []*object.Locals
+	if len(vm.frames) > 1 {  // The top level has no locals, its bindings are globals
+		outer = append([]*object.Locals{frame.locals}, frame.cl.Outer...)
+	}
+	vm.push(&object.Closure{Fn: fn, Outer: outer})
+}
+
+func (vm *VM) callFunction(numArgs int) *object.Error {
+	if callee := vm.stack[vm.sp-1-numArgs]; isNative(callee) {
+		return vm.callNative(callee, numArgs)
+	}
+	cl, err := vm.callee(numArgs)
+	if err != nil {
+		return err
+	}
+	if len(vm.frames) >= MAX_FRAMES {
+		return vm.newKindError(evaluator.STACK_OVERFLOW_KIND, "stack overflow")
+	}
+	if vm.usage != nil && vm.usage.Limits.MaxCallDepth > 0 && len(vm.frames) > vm.usage.Limits.MaxCallDepth {
+		return evaluator.CallDepthError(vm.token(), vm.usage.Limits.MaxCallDepth)
+	}
+
+	frame := NewFrame(cl, vm.sp-1-numArgs)
+	copy(frame.locals.Values, vm.stack[vm.sp-numArgs:vm.sp])
+	vm.sp = frame.basePointer
+	vm.frames = append(vm.frames, frame)
+	return nil
+}
+
+/*
+tailCall is a call whose result is returned right away, so the caller has nothing
+left to do. The callee takes its frame and the stack doesn't grow, however deep the
+recursion goes. The locals are new ones though, a closure may still hold the old.
+*/
+func (vm *VM) tailCall(numArgs int) *object.Error {
+	if len(vm.frames) == 1 {
+		return vm.newError("corrupt bytecode: tail call outside of a function")
+	}
+	// A builtin doesn't need a frame, so we call it and return what it gives
+	if callee := vm.stack[vm.sp-1-numArgs]; isNative(callee) {
+		if err := vm.callNative(callee, numArgs); err != nil {
+			return err
+		}
+		result := vm.pop()
+		vm.popFrame()
+		vm.push(result)
+		return nil
+	}
+	cl, err := vm.callee(numArgs)
+	if err != nil {
+		return err
+	}
+
+	current := vm.currentFrame()
+	frame := NewFrame(cl, current.basePointer)
+	copy(frame.locals.Values, vm.stack[vm.sp-numArgs:vm.sp])
+	vm.sp = frame.basePointer
+	vm.frames[len(vm.frames)-1] = frame
+	return nil
+}
+
+/*
+isNative tells the functions that don't run on the vm: the builtins, and the ones
+of the modules, which the evaluator ran and so keeps running. They are called from
+here and give back their result at once.
+*/
+func isNative(callee object.Object) bool {
+	switch callee.(type) {
+	case *object.Builtin, *object.Function:
+		return true
+	}
+	return false
+}
+
+// The function and its arguments are replaced with what it gives back
+func (vm *VM) callNative(callee object.Object, numArgs int) *object.Error {
+	args := make([]object.Object, numArgs)
+	copy(args, vm.stack[vm.sp-numArgs:vm.sp])
+
+	tok := vm.token()
+	var result object.Object
+	if builtin, ok := callee.(*object.Builtin); ok {
+		result = evaluator.CallBuiltin(vm.usage, tok, builtin, args)
+	} else {
+		// The evaluator cannot run our closures, but it can call back into us
+		for i, arg := range args {
+			if cl, ok := arg.(*object.Closure); ok {
+				args[i] = vm.callback(cl)
+			}
+		}
+		result = evaluator.ApplyFunction(tok, callee, args, vm.usage)
+	}
+	if err, ok := result.(*object.Error); ok {
+		return err
+	}
+	vm.sp -= numArgs + 1
+	vm.push(result)
+	return nil
+}
+
+// callback is a closure of ours the evaluator can call, see callClosure
+func (vm *VM) callback(cl *object.Closure) *object.Builtin {
+	name := cl.Fn.Name
+	if name == "" {
+		name = "fn"
+	}
+	return &object.Builtin{Name: name, Fn: func(args []object.Object) (object.Object, error) {
+		return vm.callClosure(cl, args), nil
+	}}
+}
+
+// callClosure runs the closure on top of the calls going on, and gives back what it
+// returns. When it fails, the calls it made are gone and the error is what it gives.
+func (vm *VM) callClosure(cl *object.Closure, args []object.Object) object.Object {
+	sp, depth := vm.sp, len(vm.frames)
+	vm.push(cl)
+	for _, arg := range args {
+		vm.push(arg)
+	}
+
+	var result object.Object
+	if err := vm.callFunction(len(args)); err != nil {
+		result = err
+	} else {
+		result = vm.run(depth)
+	}
+	if _, ok := result.(*object.Error); ok {
+		vm.frames = vm.frames[:depth]
+		vm.sp = sp
+	}
+	return result
+}
+
+// importModule pushes the module, which is the same one the evaluator would give
+func (vm *VM) importModule(path string) *object.Error {
+	if vm.importer == nil {
+		vm.importer = evaluator.NewModules("").Importer()
+	}
+	module, err := vm.importer.Import(path, vm.usage)
+	if err != nil {
+		return vm.newKindError(evaluator.ImportErrorKind(err), "%s", err)
+	}
+	vm.push(module)
+	return nil
+}
+
+func (vm *VM) callee(numArgs int) (*object.Closure, *object.Error) {
+	callee := vm.stack[vm.sp-1-numArgs]
+	cl, ok := callee.(*object.Closure)
+	if !ok {
+		return nil, vm.newKindError(evaluator.TYPE_KIND, "not a function: %s", typeOf(callee))
+	}
+	if numArgs != cl.Fn.NumParameters {
+		return nil, vm.newKindError(evaluator.ARGUMENTS_KIND, "wrong number of arguments: want=%d, got=%d", cl.Fn.NumParameters, numArgs)
+	}
+	return cl, nil
+}
+
+func (vm *VM) popFrame() {
+	frame := vm.currentFrame()
+	vm.frames = vm.frames[:len(vm.frames)-1]
+	vm.sp = frame.basePointer
+}
+
+// step is evaluator.Step, but the position is only worked out when it fails
+func (vm *VM) step() *object.Error {
+	err := evaluator.Step(vm.usage, token.Token{})
+	if err != nil {
+		tok := vm.token()
+		err.Line, err.Column = tok.Line, tok.Column
+	}
+	return err
+}
+
+// token rebuilds where the current instruction comes from, which is all the shared
+// operations want to know about it
+func (vm *VM) token() token.Token {
+	frame := vm.currentFrame()
+	position, _ := frame.cl.Fn.Lines.Lookup(frame.ip)
+	return token.Token{Line: position.Line, Column: position.Column}
+}
+
+func (vm *VM) newError(format string, a ...interface{}) *object.Error {
+	return vm.newKindError(evaluator.RUNTIME_KIND, format, a...)
+}
+
+func (vm *VM) newKindError(kind string, format string, a ...interface{}) *object.Error {
+	tok := vm.token()
+	return &object.Error{Message: fmt.Sprintf(format, a...), Kind: kind, Line: tok.Line, Column: tok.Column}
+}
+
+func typeOf(obj object.Object) object.ObjectType {
+	if obj == nil {
+		return object.NIL_OBJ
+	}
+	return obj.Type()
+}
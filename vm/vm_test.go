@@ -0,0 +1,321 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/santos-404/myte/compiler"
+	"github.com/santos-404/myte/evaluator"
+	"github.com/santos-404/myte/lexer"
+	"github.com/santos-404/myte/object"
+	"github.com/santos-404/myte/optimize"
+	"github.com/santos-404/myte/parser"
+)
+
+func compile(t *testing.T, input string) *compiler.Bytecode {
+	t.Helper()
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has errors for %q: %v", input, p.Errors())
+	}
+
+	c := compiler.New()
+	if err := c.Compile(program); err != nil {
+		t.Fatalf("%q - compiler error: %s", input, err)
+	}
+	return c.Bytecode()
+}
+
+func runVM(t *testing.T, input string) object.Object {
+	t.Helper()
+	return New(compile(t, input)).Run()
+}
+
+func runEvaluator(input string) object.Object {
+	program := parser.New(lexer.New(input)).ParseProgram()
+	return evaluator.Eval(program, object.NewEnvironment())
+}
+
+/*
+Declarations (and blocks that end with one) don't give back anything at all. The
+evaluator may give a Go nil where the vm gives a nil object, but both mean the same
+to whoever prints them.
+*/
+func inspect(obj object.Object) string {
+	if obj == nil {
+		return "nil"
+	}
+	return obj.Inspect()
+}
+
+/*
+This is the conformance suite: every program runs on both engines and they must
+agree with each other (errors and their positions included) and with what we
+expect. Anything new the language learns should get its cases here.
+*/
+var conformanceTests = []struct {
+	input    string
+	expected string
+}{
+	// Arithmetic
+	{"1 + 2 * 3 - 4", "3"},
+	{"7 / 2", "3.5"},
+	{"4 / 2", "2.0"},
+	{"7 // -2", "-4"},
+	{"-7 % 3", "2"},
+	{"2 ** 10", "1024"},
+	{"2 ** -1", "0.5"},
+	{"2 ** 64", "1.8446744073709552e+19"},  // Too big for an int
+	{"(-2) ** 63", "-9223372036854775808"},
+	{"(-1) ** 99999999999", "-1"},
+	{"1.5 + 1", "2.5"},
+	{"-(5 - 10)", "5"},
+	{"'my' + 'te'", "myte"},
+
+	// Comparisons and truthiness
+	{"1 == 1.0", "true"},
+	{"'a' < 'b'", "true"},
+	{"1 != nil", "true"},
+	{"!0", "false"},
+	{"!nil", "true"},
+	{"!!''", "true"},
+
+	// Bindings
+	{"var x = 5; x", "5"},
+	{"var x = 5; x += 2; x *= 3; x", "21"},
+	{"var x = 1; ++x; --x; --x", "0"},
+	{"++5", "6"},
+	{"var x = 1;", "nil"},
+	{"var x = 1; var x = 'again'; x", "again"},
+	{"const c = 1; var c = 2; c = 3; c", "3"},
+
+	// if and for
+	{"if 1 > 2 { 10 } else { 20 }", "20"},
+	{"if false { 10 }", "nil"},
+	{"if true { var y = 1; }", "nil"},
+	{"if true {}", "nil"},
+	{"if true { 1; # the end\n}", "nil"},
+	{"var i = 0; for i < 10 { ++i }", "nil"},
+	{"var i = 0; for i < 10 { ++i }; i", "10"},
+	{"var i = 0; var s = 0; for i < 10 { ++i; if i % 2 == 0 { continue; } s += i }; s", "25"},
+	{"var i = 0; for true { if i == 3 { break; } ++i }; i", "3"},
+	{"var n = 0; var i = 0; for i < 3 { ++i; var j = 0; for j < 3 { ++j; if j == 2 { break; } ++n } }; n", "3"},
+	{"if true { var inside = 1; }\ninside", "1"},
+
+	// Functions
+	{"const add = fn(a, b) { a + b }; add(1, 2)", "3"},
+	{"const early = fn(x) { if x { return 'yes'; } 'no' }; early(true) + early(false)", "yesno"},
+	{"fn() {}()", "nil"},
+	{"fn() { var x = 1; }()", "nil"},
+	{"const fib = fn(n) { if n < 2 { return n; } fib(n - 1) + fib(n - 2) }; fib(20)", "6765"},
+	{"const f = fn() { g() }; const g = fn() { 'late' }; f()", "late"},
+	{"const f = fn() { for true { return 1; } }; f()", "1"},
+	{"return 3; 4", "3"},
+
+	// Tail calls
+	{"const count = fn(n, acc) { if n == 0 { return acc; } return count(n - 1, acc + 1); }; count(1000, 0)", "1000"},
+	{"const even = fn(n) { if n == 0 { return true; } return odd(n - 1); }; const odd = fn(n) { if n == 0 { return false; } return even(n - 1); }; even(11)", "false"},
+	{"const f = fn(n) { const get = fn() { n }; if n == 0 { return get; } return f(n - 1); }; f(3)()", "0"},
+	{"const keep = fn(n) { fn() { n } }; const f = fn(n) { var g = keep(n); if n == 0 { return g; } return f(n - 1); }; f(2)()", "0"},
+	{"const id = fn(x) { x }; return id(4); 5", "4"},
+	{"const f = fn() { return 5(); }; f()", "ERROR: not a function: int. Line: 0, column: 26"},
+	{"const f = fn(a) { if a { return f(); } }; f(true)", "ERROR: wrong number of arguments: want=1, got=0. Line: 0, column: 34"},
+
+	// Closures share their bindings, they don't copy them
+	{"const adder = fn(a) { fn(b) { a + b } }; adder(2)(3)", "5"},
+	{"const counter = fn() { var c = 0; fn() { c += 1; c } }; const next = counter(); next(); next(); next()", "3"},
+	{"const make = fn() { var c = 0; const inc = fn() { ++c }; inc(); inc(); c }; make()", "2"},
+	{"var total = 0; const add = fn(n) { total += n }; add(2); add(3); total", "5"},
+	{"const outer = fn() { const inner = fn() { later }; var later = 7; inner() }; outer()", "7"},
+	{"const a = fn() { var x = 1; fn() { fn() { x * 10 } } }; a()()()", "10"},
+
+	// Builtins
+	{"len('abc') + int('4') + int(2.5)", "9"},
+	{"type(1.5) + str(nil) + str(bool(0))", "floatniltrue"},
+	{"const apply = fn(f, x) { f(x) }; apply(float, 2)", "2.0"},
+	{"const f = fn(x) { return str(x); }; f(3) + f(4)", "34"},
+	{"const f = fn(x) { return len(x); }; f(5)", "ERROR: argument 1 of len must be string, array or map, got int. Line: 0, column: 29"},
+	{"assert(true); assert(1 > 2, 'nope')", "ERROR: assertion failed: nope. Line: 0, column: 21"},
+	{"len('a', 'b')", "ERROR: wrong number of arguments to len: want=1, got=2. Line: 0, column: 4"},
+	{"int('x')", "error('cannot convert \"x\" to int')"},
+	{"[int(2.0 ** 64).kind, int('x').kind]", "['error', 'error']"},
+
+	// Arrays
+	{"[1, 'a', [2.5]]", "[1, 'a', [2.5]]"},
+	{"var a = [1, 2, 3]; a[0] + a[-1]", "4"},
+	{"const f = fn(n) { [n, n * 2] }; f(3)[1] + len(f(1) + [0])", "9"},
+	{"[1] + [2] == [1, 2]", "true"},
+	{"'héllo'[1]", "é"},
+	{"[1, 2][2]", "ERROR: index out of range: 2 (length 2). Line: 0, column: 7"},
+	{"[1]['a']", "ERROR: index must be int, got string. Line: 0, column: 4"},
+	{"true[0]", "ERROR: cannot index bool. Line: 0, column: 5"},
+
+	// Maps
+	{"{'a': 1, 2: [true]}", "{'a': 1, 2: [true]}"},
+	{"var m = {'a': 1} + {'b': 2, 'a': 3}; [m['a'], m['b'], m['c'], len(m), keys(m)]", "[3, 2, nil, 2, ['a', 'b']]"},
+	{"const f = fn(k) { {k: k + '!'} }; f('x')['x']", "x!"},
+	{"{'a': [1]} == {'a': [1.0]}", "true"},
+	{"{1.5: 1}", "ERROR: unusable as map key: float. Line: 0, column: 1"},
+	{"{}[[]]", "ERROR: unusable as map key: array. Line: 0, column: 3"},
+
+	// Error values
+	{"var e = error('bad'); [e, e.message, e.line, e.column, type(e)]", "[error('bad'), 'bad', 0, 14, 'error']"},
+	{"const f = fn(s) { int(s)? * 2 }; [f('4'), f('x').column]", "[8, 22]"},
+	{"const f = fn() { for true { error('out')?; } }; f()", "error('out')"},
+	{"const f = fn(s) { var n = int(s)?; n }; const g = fn() { f('x')?; 1 }; g()", "error('cannot convert \"x\" to int')"},
+	{"fn() { nil? }()", "nil"},
+	{"return error('given back')", "error('given back')"},
+	{"1; int('x')?; 2", "ERROR: cannot convert \"x\" to int. Line: 0, column: 7"},
+	{"error('bad').code", "ERROR: error has no member: code. Line: 0, column: 14"},
+	{"var n = 1; n.code", "ERROR: cannot get member code of int. Line: 0, column: 14"},
+
+	// Try, catch and throw
+	{"try { 1 / 0 } catch e { [e.message, e.kind, e.line, e.column] }", "['division by zero', 'zero_division', 0, 9]"},
+	{"var x = 1 + try { [][0] } catch { 10 }; x", "11"},
+	{"const f = fn(n) { if n == 0 { return 1 / 0 } f(n - 1) }; try { f(20) } catch e { e.kind }", "zero_division"},
+	{"const f = fn(n) { try { return g(n) } catch e { 'caught' } }; const g = fn(n) { 1 / n }; [f(0), f(1)]", "['caught', 1.0]"},
+	{"fn() { var r = try { nope } catch err { err.kind }; r }()", "name"},
+	{"try { throw 'bad' } catch e { [e, e.kind, e.column] }", "[error('bad'), 'error', 7]"},
+	{"try { try { 1 / 0 } catch e { throw e } } catch e { [e.kind, e.column] }", "['zero_division', 15]"},
+	{"try { throw [] } catch e { e.kind }", "type"},
+	{"try { 1 } finally { 2 }", "1"},
+	{"var log = []; const f = fn() { try { return 1 } finally { log = log + ['f'] } }; [f(), log]", "[1, ['f']]"},
+	{"const f = fn() { try { return 1 } finally { return 2 } }; f()", "2"},
+	{"var log = []; for true { try { break } finally { log = log + ['f'] } }; log", "['f']"},
+	{"var log = []; var i = 0; for i < 3 { i += 1; try { if i == 2 { continue } } finally { log = log + [i] } }; log", "[1, 2, 3]"},
+	{"var log = []; try { try { 1 / 0 } catch e { throw 'again' } finally { log = log + ['f'] } } catch e { [log, e.message] }",
+		"[['f'], 'again']"},
+	{"var log = []; const f = fn() { try { int('x')? } finally { log = log + ['f'] } }; [f().message, log]",
+		"['cannot convert \"x\" to int', ['f']]"},
+	{"var log = []; const f = fn() { for true { try { try { return 'r' } finally { log = log + [1] } } finally { log = log + [2] } } }; [f(), log]",
+		"['r', [1, 2]]"},
+	{"try { 1 / 0 } finally { 2 }", "ERROR: division by zero. Line: 0, column: 9"},
+	{"throw 'stop'", "ERROR: stop. Line: 0, column: 1"},
+
+	// Traces
+	{"const f = fn() { 1 / 0 }; try { f() } catch e { e.trace }", "[{'function': 'f', 'line': 0, 'column': 34}]"},
+	{"const f = fn() { 1 / 0 }; const g = fn() { try { f() } catch e { e.trace } }; g()",
+		"[{'function': 'f', 'line': 0, 'column': 51}]"},
+	{"const f = fn() { 1 / 0 }; const g = fn() { try { f() } catch e { throw e } }; try { g() } catch e { e.trace }",
+		"[{'function': 'f', 'line': 0, 'column': 51}, {'function': 'g', 'line': 0, 'column': 86}]"},
+	{"const f = fn() { 1 / 0 }; const g = fn() { return f() }; try { g() } catch e { e.trace }",
+		"[{'function': 'f', 'line': 0, 'column': 65}]"},
+	{"const run = fn(f) { f() }; try { run(fn() { [][1] }) } catch e { e.trace }",
+		"[{'function': '<anonymous fn> (line 0, column 38)', 'line': 0, 'column': 22}, {'function': 'run', 'line': 0, 'column': 37}]"},
+
+	// Standard modules
+	{`import "math"; math.floor(2.5) + math.abs(-1)`, "3.0"},
+	{`import "strings" as s; s.join(s.split('a,b', ','), '-')`, "a-b"},
+	{`const f = fn() { import "json"; json.parse('[1, 2]') }; f()`, "[1, 2]"},
+	{`import "math"; math.nope`, "ERROR: module math has no member: nope. Line: 0, column: 21"},
+	{`import "nope"`, "ERROR: module not found: nope. Line: 0, column: 1"},
+
+	// Errors
+	{"foobar", "ERROR: identifier not found: foobar. Line: 0, column: 1"},
+	{"y = 1", "ERROR: identifier not found: y. Line: 0, column: 1"},
+	{"fn() { x; var x = 1; }()", "ERROR: identifier not found: x. Line: 0, column: 8"},
+	{"5 / 0", "ERROR: division by zero. Line: 0, column: 3"},
+	{"5 % 0.0", "ERROR: division by zero. Line: 0, column: 3"},
+	{"'a' - 1", "ERROR: unsupported operand types: string - int. Line: 0, column: 5"},
+	{"-true", "ERROR: unknown operator: -bool. Line: 0, column: 1"},
+	{"var s = 'a'; ++s", "ERROR: unknown operator: ++string. Line: 0, column: 14"},
+	{"const c = 1; c = 2", "ERROR: cannot assign to constant: c. Line: 0, column: 14"},
+	{"const c = 1; c += 2", "ERROR: cannot assign to constant: c. Line: 0, column: 14"},
+	{"const c = 1; ++c", "ERROR: cannot assign to constant: c. Line: 0, column: 16"},
+	{"fn() { const k = 1; k = 2 }()", "ERROR: cannot assign to constant: k. Line: 0, column: 21"},
+	{"const f = fn() { c = 2 }; const c = 1; f()", "ERROR: cannot assign to constant: c. Line: 0, column: 18"},
+	{"var n = 1; n()", "ERROR: not a function: int. Line: 0, column: 13"},
+	{"const f = fn(a) { a }; f(1, 2)", "ERROR: wrong number of arguments: want=1, got=2. Line: 0, column: 25"},
+	{"const f = fn() { 1 - 'a' }; 1 + 1; f()", "ERROR: unsupported operand types: int - string. Line: 0, column: 20"},
+	{"var x = 1 + nil; x", "ERROR: unsupported operand types: int + nil. Line: 0, column: 11"},
+	// Out of a loop they are wrong only once they run, and after the finallys
+	{"if false { break; }", "nil"},
+	{"if true { break; }", "ERROR: break outside of a loop. Line: 0, column: 11"},
+	{"var n = 0; try { continue } finally { ++n }; n", "ERROR: continue outside of a loop. Line: 0, column: 18"},
+	{"const f = fn() { try { break } catch e { 1 } }; try { f() } catch e { [e.message, e.trace] }",
+		"['break outside of a loop', [{'function': 'f', 'line': 0, 'column': 56}]]"},
+	{"for true { const f = fn() { continue }; f() }", "ERROR: continue outside of a loop. Line: 0, column: 29"},
+	{"var n = 0; for true { ++n; var x = if n > 3 { break }; }; n", "4"},
+	{"var a = []; var i = 0; for i < 5 { ++i; a += [if i % 2 == 0 { continue } else { i }] }; a", "[1, 3, 5]"},
+}
+
+func TestConformance(t *testing.T) {
+	for _, tt := range conformanceTests {
+		evaluated := inspect(runEvaluator(tt.input))
+		compiled := inspect(runVM(t, tt.input))
+
+		if evaluated != compiled {
+			t.Errorf("%q - the engines disagree. eval=%q, vm=%q", tt.input, evaluated, compiled)
+			continue
+		}
+		if compiled != tt.expected {
+			t.Errorf("%q - result wrong. expected=%q, got=%q", tt.input, tt.expected, compiled)
+		}
+	}
+}
+
+// A program must do the same after a trip through a .mytec file
+func TestConformanceFromFiles(t *testing.T) {
+	for _, tt := range conformanceTests {
+		data, err := compile(t, tt.input).Encode()
+		if err != nil {
+			t.Fatalf("%q - encode error: %s", tt.input, err)
+		}
+		bytecode, err := compiler.Decode(data)
+		if err != nil {
+			t.Fatalf("%q - decode error: %s", tt.input, err)
+		}
+
+		if result := inspect(New(bytecode).Run()); result != tt.expected {
+			t.Errorf("%q - result wrong. expected=%q, got=%q", tt.input, tt.expected, result)
+		}
+	}
+}
+
+// Folding constants must never change what a program gives back
+func TestConformanceOptimized(t *testing.T) {
+	for _, tt := range conformanceTests {
+		optimized, _ := optimize.Program(parser.New(lexer.New(tt.input)).ParseProgram())
+
+		c := compiler.New()
+		if err := c.Compile(optimized); err != nil {
+			t.Fatalf("%q - compiler error: %s", tt.input, err)
+		}
+		if result := inspect(New(c.Bytecode()).Run()); result != tt.expected {
+			t.Errorf("%q - result wrong. expected=%q, got=%q", tt.input, tt.expected, result)
+		}
+		if result := inspect(evaluator.Eval(optimized, object.NewEnvironment())); result != tt.expected {
+			t.Errorf("%q - evaluated result wrong. expected=%q, got=%q", tt.input, tt.expected, result)
+		}
+	}
+}
+
+// Deep recursion doesn't depend on the size the stack starts with
+func TestStackGrows(t *testing.T) {
+	input := "const sum = fn(n) { if n == 0 { return 0; } n + sum(n - 1) }; sum(5000)"
+	if result := inspect(runVM(t, input)); result != "12502500" {
+		t.Errorf("result wrong. got=%q", result)
+	}
+}
+
+// A call in tail position takes the place of its caller in both engines
+func TestDeepTailRecursion(t *testing.T) {
+	input := "const count = fn(n) { if n == 0 { return 'done'; } return count(n - 1); }; count(1000000)"
+
+	if result := inspect(runEvaluator(input)); result != "done" {
+		t.Errorf("evaluator result wrong. got=%q", result)
+	}
+	if result := inspect(runVM(t, input)); result != "done" {
+		t.Errorf("vm result wrong. got=%q", result)
+	}
+}
+
+func TestStackOverflow(t *testing.T) {
+	result := runVM(t, "const loop = fn() { loop() }; loop()")
+
+	err, ok := result.(*object.Error)
+	if !ok || err.Message != "stack overflow" {
+		t.Errorf("expected a stack overflow. got=%s", inspect(result))
+	}
+}